@@ -1,5 +1,7 @@
 package main
 
-func main() {
+import "http_calendar/cmd"
 
+func main() {
+	cmd.Execute()
 }
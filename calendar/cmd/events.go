@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"http_calendar/internal/lib/models"
+)
+
+var (
+	eventDate   string
+	calendarId  string
+	calendarIds []string
+	eventName   string
+	listWeek    bool
+	listMonth   bool
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add [flags] event description",
+	Short: "Create an event",
+	Example: `  calendar add --date 2025-07-16 "Attend Go workshop"
+  calendar add --calendar work "Standup"`,
+	Args:              cobra.MinimumNArgs(1),
+	PersistentPreRunE: loadClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, err := parseDateFlag(eventDate)
+		if err != nil {
+			return err
+		}
+		created, err := api.CreateEvent(date, strings.Join(args, " "), calendarId)
+		if err != nil {
+			return err
+		}
+		return printEvents(cmd.OutOrStdout(), []models.Event{created})
+	},
+}
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List events of a day, week or month",
+	Example: `  calendar ls
+  calendar ls --week --date 2025-07-16
+  calendar ls --month --calendar work,personal -o json`,
+	Args:              cobra.NoArgs,
+	PersistentPreRunE: loadClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, err := parseDateFlag(eventDate)
+		if err != nil {
+			return err
+		}
+		period := "day"
+		switch {
+		case listWeek && listMonth:
+			return errors.New("--week and --month are mutually exclusive")
+		case listWeek:
+			period = "week"
+		case listMonth:
+			period = "month"
+		}
+
+		events, err := api.GetEvents(date, period, calendarIds)
+		if err != nil {
+			return err
+		}
+		return printEvents(cmd.OutOrStdout(), events)
+	},
+}
+
+var rmCmd = &cobra.Command{
+	Use:               "rm [flags] event-id",
+	Short:             "Delete an event",
+	Example:           `  calendar rm --date 2025-07-16 3bf17669-4307-4899-acde-a747c04f1c79`,
+	Args:              cobra.ExactArgs(1),
+	PersistentPreRunE: loadClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, err := parseDateFlag(eventDate)
+		if err != nil {
+			return err
+		}
+		if err := api.DeleteEvent(args[0], date); err != nil {
+			return err
+		}
+		if output == OutputJSON {
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(map[string]string{"deleted": args[0]})
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "deleted %s\n", args[0])
+		return err
+	},
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit [flags] event-id",
+	Short: "Rename an event or move it to another calendar",
+	Example: `  calendar edit --date 2025-07-16 --name "Go workshop (room 4)" <event-id>
+  calendar edit --date 2025-07-16 --calendar work <event-id>`,
+	Args:              cobra.ExactArgs(1),
+	PersistentPreRunE: loadClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, err := parseDateFlag(eventDate)
+		if err != nil {
+			return err
+		}
+		if eventName == "" && calendarId == "" {
+			return errors.New("nothing to change: use --name and/or --calendar")
+		}
+
+		name := eventName
+		if name == "" {
+			// the API replaces the name, so keep the current one
+			current, err := findEvent(date, args[0])
+			if err != nil {
+				return err
+			}
+			name = current.Name
+		}
+
+		updated, err := api.UpdateEvent(args[0], date, name, calendarId)
+		if err != nil {
+			return err
+		}
+		return printEvents(cmd.OutOrStdout(), []models.Event{updated})
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import [flags] file",
+	Short: "Create events from a JSON file",
+	Long: `Create events from a JSON file ("-" reads stdin).
+
+The file holds either a JSON array or JSON lines of objects with the same
+fields as the POST /events body: "date", "event" and optional "calendar_id".`,
+	Example: `  calendar import events.json
+  jq -c '.[]' export.json | calendar import -`,
+	Args:              cobra.ExactArgs(1),
+	PersistentPreRunE: loadClient,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		events, err := readImport(args[0])
+		if err != nil {
+			return err
+		}
+
+		created := make([]models.Event, 0, len(events))
+		for i, e := range events {
+			c, err := api.CreateEvent(e.Date, e.Name, e.CalendarId)
+			if err != nil {
+				_ = printEvents(cmd.OutOrStdout(), created)
+				return fmt.Errorf("event #%d (%s %q): %w", i+1, e.Date, e.Name, err)
+			}
+			created = append(created, c)
+		}
+		return printEvents(cmd.OutOrStdout(), created)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{addCmd, lsCmd, rmCmd, editCmd} {
+		c.Flags().StringVarP(&eventDate, "date", "d", "", "date in YYYY-MM-DD format (default: today)")
+	}
+	addCmd.Flags().StringVarP(&calendarId, "calendar", "c", "", "calendar id (default: the default calendar)")
+	editCmd.Flags().StringVarP(&calendarId, "calendar", "c", "", "move the event to this calendar")
+	editCmd.Flags().StringVarP(&eventName, "name", "n", "", "new event description")
+	lsCmd.Flags().BoolVarP(&listWeek, "week", "w", false, "list the whole week")
+	lsCmd.Flags().BoolVarP(&listMonth, "month", "m", false, "list the whole month")
+	lsCmd.Flags().StringSliceVarP(&calendarIds, "calendar", "c", nil, "comma-separated calendar ids (default: all)")
+
+	rootCmd.AddCommand(addCmd, lsCmd, rmCmd, editCmd, importCmd)
+}
+
+// parseDateFlag parses a --date value, defaulting to today.
+func parseDateFlag(s string) (models.Date, error) {
+	if s == "" {
+		y, m, d := time.Now().Date()
+		return models.Date{Time: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}, nil
+	}
+	date, err := models.ParseDate(s)
+	if err != nil {
+		return models.Date{}, fmt.Errorf("invalid date %q: want YYYY-MM-DD", s)
+	}
+	return date, nil
+}
+
+// findEvent looks up an event by id among the events of date.
+func findEvent(date models.Date, eventId string) (models.Event, error) {
+	events, err := api.GetEvents(date, "day", nil)
+	if err != nil {
+		return models.Event{}, err
+	}
+	for _, e := range events {
+		if e.Id == eventId {
+			return e, nil
+		}
+	}
+	return models.Event{}, fmt.Errorf("no event %s on %s", eventId, date)
+}
+
+// readImport decodes events from a JSON array or JSON lines file.
+func readImport(path string) ([]models.Event, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	br := bufio.NewReader(r)
+	data, err := io.ReadAll(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var events []models.Event
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &events); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var e models.Event
+			if err := dec.Decode(&e); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: event #%d: %w", path, len(events)+1, err)
+			}
+			events = append(events, e)
+		}
+	}
+
+	for i, e := range events {
+		if e.Date.IsZero() || e.Name == "" {
+			return nil, fmt.Errorf("event #%d: \"date\" and \"event\" are required", i+1)
+		}
+	}
+	return events, nil
+}
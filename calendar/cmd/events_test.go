@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"http_calendar/internal/lib/models"
+)
+
+func TestReadImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // names of the imported events
+		wantErr string
+	}{
+		{"array", `[{"date": "2025-07-16", "event": "Workshop"}, {"date": "2025-07-17", "event": "Demo"}]`, []string{"Workshop", "Demo"}, ""},
+		{"json_lines", "{\"date\": \"2025-07-16\", \"event\": \"Workshop\"}\n{\"date\": \"2025-07-17\", \"event\": \"Demo\"}\n", []string{"Workshop", "Demo"}, ""},
+		{"empty", "", nil, ""},
+		{"missing_name", `[{"date": "2025-07-16"}]`, nil, `event #1: "date" and "event" are required`},
+		{"malformed_line", "{\"date\": \"2025-07-16\", \"event\": \"Workshop\"}\n{\"date\":", nil, "event #2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "events.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := readImport(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readImport failed: %v", err)
+			}
+			var names []string
+			for _, e := range events {
+				names = append(names, e.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got events %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintEvents(t *testing.T) {
+	date, _ := models.ParseDate("2025-07-16")
+	tests := []struct {
+		name   string
+		format string
+		events []models.Event
+		want   string
+	}{
+		{"table", OutputTable, []models.Event{
+			{Id: "1", CalendarId: "work", Date: date, Name: "Workshop"},
+			{Id: "12", CalendarId: "default", Date: date, Name: "Dentist"},
+		}, "DATE        CALENDAR  ID  EVENT\n" +
+			"2025-07-16  work      1   Workshop\n" +
+			"2025-07-16  default   12  Dentist\n"},
+		{"no_events", OutputTable, nil, "no events\n"},
+		{"json_no_events", OutputJSON, nil, "[]\n"},
+	}
+
+	defer func(saved string) { output = saved }(output)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output = tt.format
+			var buf bytes.Buffer
+			if err := printEvents(&buf, tt.events); err != nil {
+				t.Fatalf("printEvents failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
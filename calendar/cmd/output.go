@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"http_calendar/internal/lib/models"
+)
+
+// printEvents writes events as an aligned table or, with -o json, as a JSON array.
+func printEvents(w io.Writer, events []models.Event) error {
+	if output == OutputJSON {
+		if events == nil {
+			events = []models.Event{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(events)
+	}
+
+	if len(events) == 0 {
+		_, err := fmt.Fprintln(w, "no events")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "DATE\tCALENDAR\tID\tEVENT"); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Date, e.CalendarId, e.Id, e.Name); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"http_calendar/config"
+	"http_calendar/internal/client"
+)
+
+// Output formats of the client subcommands.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+var (
+	configPath string
+	serverURL  string
+	token      string
+	userId     string
+	output     string
+
+	// api is set up by loadClient before any client subcommand runs.
+	api *client.Client
+)
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// rootCmd represents the base command when called without any subcommands
+var rootCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Calendar HTTP server and command-line client",
+	Long: `Calendar HTTP server and command-line client.
+
+"calendar serve" runs the server. The other subcommands manage events of a
+user through the server's HTTP API, so they can be used from shell scripts.
+
+The server URL, token and user are read from the config file
+(default: $XDG_CONFIG_HOME/calendar/config.json), then from CALENDAR_URL,
+CALENDAR_TOKEN and CALENDAR_USER, then from flags.
+
+Examples:
+  calendar add --date 2025-07-16 "Attend Go workshop"
+  calendar ls --week
+  calendar ls --month -o json
+  calendar rm --date 2025-07-16 <event-id>
+`,
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", config.ClientConfigPath(), "client config file")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "calendar server URL (overrides config and CALENDAR_URL)")
+	rootCmd.PersistentFlags().StringVar(&token, "token", "", "API token (overrides config and CALENDAR_TOKEN)")
+	rootCmd.PersistentFlags().StringVarP(&userId, "user", "u", "", "user id (overrides config and CALENDAR_USER)")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", OutputTable, "output format: table or json")
+}
+
+// loadClient resolves the client configuration and creates the API client.
+// It is the PersistentPreRunE of client subcommands.
+func loadClient(cmd *cobra.Command, args []string) error {
+	if output != OutputTable && output != OutputJSON {
+		return fmt.Errorf("invalid output format %q: want %s or %s", output, OutputTable, OutputJSON)
+	}
+
+	cfg, err := config.LoadClient(configPath)
+	if err != nil {
+		return err
+	}
+	if serverURL != "" {
+		cfg.ServerURL = serverURL
+	}
+	if token != "" {
+		cfg.Token = token
+	}
+	if userId != "" {
+		cfg.UserId = userId
+	}
+	if cfg.UserId == "" {
+		return errors.New("user is not set: use --user, CALENDAR_USER or user_id in the config file")
+	}
+
+	api = client.New(cfg.ServerURL, cfg.Token, cfg.UserId)
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/spf13/cobra"
+	"http_calendar/config"
+	"http_calendar/internal/holidays"
+	calendarCreator "http_calendar/internal/http/handlers/calendars/creator"
+	calendarDeleter "http_calendar/internal/http/handlers/calendars/deleter"
+	calendarGetter "http_calendar/internal/http/handlers/calendars/getter"
+	calendarUpdater "http_calendar/internal/http/handlers/calendars/updater"
+	"http_calendar/internal/http/handlers/creator"
+	"http_calendar/internal/http/handlers/deleter"
+	"http_calendar/internal/http/handlers/getter"
+	"http_calendar/internal/http/handlers/quickcreator"
+	"http_calendar/internal/http/handlers/updater"
+	"http_calendar/internal/http/handlers/views"
+	mwLogger "http_calendar/internal/http/middleware"
+	"http_calendar/internal/service"
+	"http_calendar/internal/storage"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	_ "time/tzdata" // quick-add resolves users' time zones independently of the host
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the calendar HTTP server",
+	Long: `Run the calendar HTTP server.
+
+The server is configured via CALENDAR_* environment variables, see README.md.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serve()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+func serve() {
+	cfg := config.MustLoad()
+
+	logger := setupLogger(cfg)
+	logger.Info("starting calendar server", slog.String("address", cfg.Address))
+
+	repo := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(repo, cfg.MondayBasedWeek)
+	if cfg.HolidayCountry != "" {
+		set, err := loadHolidays(cfg.HolidayFiles)
+		if err != nil {
+			logger.Error("failed to load holidays", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		svc.SetHolidays(set, cfg.HolidayCountry)
+		logger.Info("holidays enabled", slog.String("country", cfg.HolidayCountry), slog.Any("loaded", set.Countries()))
+	}
+	if n, err := svc.MigrateLegacyEvents(); err != nil {
+		logger.Error("failed to migrate events into default calendars", slog.String("error", err.Error()))
+		os.Exit(1)
+	} else if n > 0 {
+		logger.Info("migrated events into default calendars", slog.Int("count", n))
+	}
+
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(mwLogger.NewHTTPMw(logger))
+	router.Use(middleware.Recoverer)
+
+	router.Post("/events", creator.New(logger, svc))
+	router.Post("/events/quick", quickcreator.New(logger, svc))
+	router.Put("/events", updater.New(logger, svc))
+	router.Delete("/events", deleter.New(logger, svc))
+	router.Get("/events", getter.New(logger, svc))
+
+	router.Post("/calendars", calendarCreator.New(logger, svc))
+	router.Put("/calendars", calendarUpdater.New(logger, svc))
+	router.Delete("/calendars", calendarDeleter.New(logger, svc))
+	router.Get("/calendars", calendarGetter.New(logger, svc))
+
+	router.Get("/views/month", views.NewMonth(logger, svc))
+	router.Get("/views/week", views.NewWeek(logger, svc))
+
+	srv := &http.Server{
+		Addr:         cfg.Address,
+		Handler:      router,
+		ReadTimeout:  cfg.Timeout,
+		WriteTimeout: cfg.Timeout,
+	}
+	if err := srv.ListenAndServe(); err != nil {
+		logger.Error("server stopped", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+// loadHolidays returns the bundled holiday sets extended with user-supplied files.
+func loadHolidays(files []string) (*holidays.Set, error) {
+	set, err := holidays.NewBundledSet()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		if err := set.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// setupLogger returns a text logger for local runs and a JSON logger otherwise,
+// writing to cfg.LogFile when it is set.
+func setupLogger(cfg *config.Config) *slog.Logger {
+	var out io.Writer = os.Stdout
+	if cfg.LogFile != "" {
+		f, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			log.Fatalf("failed to open log file %s: %v", cfg.LogFile, err)
+		}
+		out = f
+	}
+
+	if cfg.Env == "local" {
+		return slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ClientConfig holds the settings of the calendar CLI client.
+// Values come from the config file and are overridden by environment variables.
+type ClientConfig struct {
+	ServerURL string `json:"server_url"` // CALENDAR_URL: base URL of the calendar server
+	Token     string `json:"token"`      // CALENDAR_TOKEN: sent as a bearer token with every request
+	UserId    string `json:"user_id"`    // CALENDAR_USER: user whose events are managed
+}
+
+// DefaultServerURL is used when neither the config file nor the environment sets a server.
+const DefaultServerURL = "http://localhost:8080"
+
+// ClientConfigPath returns the default config file location:
+// $XDG_CONFIG_HOME/calendar/config.json (or the OS equivalent).
+func ClientConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "calendar", "config.json")
+}
+
+// LoadClient reads the client config from path, if it exists, and applies
+// environment overrides. An empty path skips the file.
+func LoadClient(path string) (*ClientConfig, error) {
+	cfg := &ClientConfig{ServerURL: DefaultServerURL}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+		default:
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+			}
+		}
+	}
+
+	if v := os.Getenv("CALENDAR_URL"); v != "" {
+		cfg.ServerURL = v
+	}
+	if v := os.Getenv("CALENDAR_TOKEN"); v != "" {
+		cfg.Token = v
+	}
+	if v := os.Getenv("CALENDAR_USER"); v != "" {
+		cfg.UserId = v
+	}
+	return cfg, nil
+}
@@ -1 +1,61 @@
 package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the server settings. Every field is read from an environment variable.
+type Config struct {
+	Env             string        // CALENDAR_ENV: local, dev or prod; selects the log format
+	Address         string        // CALENDAR_ADDRESS: listen address, e.g. ":8080"
+	LogFile         string        // CALENDAR_LOG_FILE: write logs to this file instead of stdout
+	MondayBasedWeek bool          // CALENDAR_MONDAY_BASED_WEEK: weeks start on Monday
+	Timeout         time.Duration // CALENDAR_TIMEOUT: read/write timeout of the HTTP server
+	HolidayCountry  string        // CALENDAR_HOLIDAYS_COUNTRY: country whose holidays are overlaid, e.g. "US"; empty disables holidays
+	HolidayFiles    []string      // CALENDAR_HOLIDAYS_FILES: comma-separated .json/.ics files loaded on top of the bundled sets
+}
+
+// MustLoad reads the Config from the environment and terminates the process on malformed values.
+func MustLoad() *Config {
+	cfg := &Config{
+		Env:             getEnv("CALENDAR_ENV", "local"),
+		Address:         getEnv("CALENDAR_ADDRESS", ":8080"),
+		LogFile:         os.Getenv("CALENDAR_LOG_FILE"),
+		MondayBasedWeek: true,
+		Timeout:         5 * time.Second,
+		HolidayCountry:  os.Getenv("CALENDAR_HOLIDAYS_COUNTRY"),
+	}
+
+	for _, path := range strings.Split(os.Getenv("CALENDAR_HOLIDAYS_FILES"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			cfg.HolidayFiles = append(cfg.HolidayFiles, path)
+		}
+	}
+
+	if v := os.Getenv("CALENDAR_MONDAY_BASED_WEEK"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("invalid CALENDAR_MONDAY_BASED_WEEK %q: %v", v, err)
+		}
+		cfg.MondayBasedWeek = b
+	}
+	if v := os.Getenv("CALENDAR_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid CALENDAR_TIMEOUT %q: %v", v, err)
+		}
+		cfg.Timeout = d
+	}
+	return cfg
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the calendar HTTP API on behalf of a single user.
+type Client struct {
+	baseURL string
+	token   string
+	userId  string
+	http    *http.Client
+}
+
+// New creates a Client for the server at baseURL.
+// token is sent as a bearer token when not empty.
+func New(baseURL, token, userId string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		userId:  userId,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// eventRequest mirrors the JSON bodies accepted by /events handlers.
+type eventRequest struct {
+	UserId     string      `json:"user_id"`
+	EventId    string      `json:"event_id,omitempty"`
+	CalendarId string      `json:"calendar_id,omitempty"`
+	Date       models.Date `json:"date"`
+	EventName  string      `json:"event,omitempty"`
+}
+
+// CreateEvent creates an event; an empty calendarId means the default calendar.
+func (c *Client) CreateEvent(date models.Date, name, calendarId string) (models.Event, error) {
+	var created models.Event
+	err := c.do(http.MethodPost, "/events", nil, eventRequest{
+		UserId:     c.userId,
+		CalendarId: calendarId,
+		Date:       date,
+		EventName:  name,
+	}, &created)
+	return created, err
+}
+
+// UpdateEvent replaces name and, if calendarId is set, the calendar of an event.
+func (c *Client) UpdateEvent(eventId string, date models.Date, name, calendarId string) (models.Event, error) {
+	var updated models.Event
+	err := c.do(http.MethodPut, "/events", nil, eventRequest{
+		UserId:     c.userId,
+		EventId:    eventId,
+		CalendarId: calendarId,
+		Date:       date,
+		EventName:  name,
+	}, &updated)
+	return updated, err
+}
+
+// DeleteEvent deletes the event with eventId on date.
+func (c *Client) DeleteEvent(eventId string, date models.Date) error {
+	return c.do(http.MethodDelete, "/events", nil, eventRequest{
+		UserId:  c.userId,
+		EventId: eventId,
+		Date:    date,
+	}, nil)
+}
+
+// GetEvents lists events of the day, week or month containing date.
+// An empty calendarIds means all calendars.
+func (c *Client) GetEvents(date models.Date, period string, calendarIds []string) ([]models.Event, error) {
+	q := url.Values{}
+	q.Set("user_id", c.userId)
+	q.Set("date", date.String())
+	q.Set("period", period)
+	if len(calendarIds) > 0 {
+		q.Set("calendar_ids", strings.Join(calendarIds, ","))
+	}
+
+	var events []models.Event
+	err := c.do(http.MethodGet, "/events", q, nil, &events)
+	return events, err
+}
+
+// do sends a request and decodes the response.Response envelope.
+// The Result is decoded into result when it is not nil; an error status
+// is returned as *APIError.
+func (c *Client) do(method, path string, query url.Values, body any, result any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot reach calendar server at %s: %w", c.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var envelope struct {
+		response.Response
+		Result json.RawMessage `json:"result,omitempty"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return &APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("unexpected response (%s)", resp.Status)}
+	}
+	if envelope.Status != response.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: envelope.Error}
+	}
+	if result != nil && len(envelope.Result) > 0 {
+		if err := json.Unmarshal(envelope.Result, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package client_test
+
+import (
+	"encoding/json"
+	"http_calendar/internal/client"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateEventSendsTokenAndDecodesResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["user_id"] != "1" || body["date"] != "2025-07-16" || body["event"] != "Workshop" {
+			t.Errorf("unexpected body %v", body)
+		}
+		_ = json.NewEncoder(w).Encode(response.OK(models.Event{Id: "42", UserId: "1", Name: "Workshop"}))
+	}))
+	defer srv.Close()
+
+	date, _ := models.ParseDate("2025-07-16")
+	created, err := client.New(srv.URL, "secret", "1").CreateEvent(date, "Workshop", "")
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if created.Id != "42" {
+		t.Fatalf("Expected event 42, got %+v", created)
+	}
+}
+
+func TestErrorsAreHumanized(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		msg    string
+		want   string
+	}{
+		{
+			"validation",
+			http.StatusBadRequest,
+			"failed to validate request Key: 'Request.Date' Error:Field validation for 'Date' failed on the 'ISO8601date' tag",
+			"invalid request: date must be a date in YYYY-MM-DD format",
+		},
+		{
+			"not_found",
+			http.StatusServiceUnavailable,
+			"failed to delete event: event not found: 7",
+			"no such event on that date",
+		},
+		{
+			"unknown",
+			http.StatusServiceUnavailable,
+			"failed to get events: disk on fire",
+			"server error: failed to get events: disk on fire",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(response.Error(tt.msg))
+			}))
+			defer srv.Close()
+
+			_, err := client.New(srv.URL, "", "1").GetEvents(models.Date{}, "day", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
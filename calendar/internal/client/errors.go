@@ -0,0 +1,83 @@
+package client
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// APIError is an error reported by the server in the response.Response error field.
+type APIError struct {
+	StatusCode int    // StatusCode is the HTTP status of the response.
+	Message    string // Message is the raw error field of the response.
+}
+
+// Error returns a human-friendly description of the server error.
+func (e *APIError) Error() string {
+	return Humanize(e.StatusCode, e.Message)
+}
+
+// validationTag matches validator messages such as
+// "Key: 'Request.Date' Error:Field validation for 'Date' failed on the 'ISO8601date' tag".
+var validationTag = regexp.MustCompile(`Field validation for '(\w+)' failed on the '(\w+)' tag`)
+
+// fieldNames maps request struct fields to the CLI vocabulary.
+var fieldNames = map[string]string{
+	"UserId":     "user",
+	"EventId":    "event id",
+	"CalendarId": "calendar",
+	"Date":       "date",
+	"EventName":  "event name",
+	"Name":       "name",
+	"Color":      "color",
+	"Visibility": "visibility",
+	"Period":     "period",
+}
+
+// tagHints explains validation tags.
+var tagHints = map[string]string{
+	"required":    "is required",
+	"ISO8601date": "must be a date in YYYY-MM-DD format",
+	"hexcolor":    "must be a color like #4285f4",
+	"oneof":       "has an unsupported value",
+}
+
+// knownErrors maps fragments of business-logic errors to friendlier text.
+var knownErrors = []struct{ fragment, text string }{
+	{"calendar not found", "no such calendar"},
+	{"event not found", "no such event on that date"},
+	{"user has no events", "you have no events yet"},
+	{"event already exists", "the event already exists"},
+	{"default calendar cannot be deleted", "the default calendar cannot be deleted"},
+	{"empty request body", "the request was empty"},
+}
+
+// Humanize turns a raw server error message into a short explanation.
+func Humanize(status int, msg string) string {
+	var hints []string
+	for _, m := range validationTag.FindAllStringSubmatch(msg, -1) {
+		field, ok := fieldNames[m[1]]
+		if !ok {
+			field = strings.ToLower(m[1])
+		}
+		hint, ok := tagHints[m[2]]
+		if !ok {
+			hint = "is invalid"
+		}
+		hints = append(hints, field+" "+hint)
+	}
+	if len(hints) > 0 {
+		return "invalid request: " + strings.Join(hints, "; ")
+	}
+
+	for _, known := range knownErrors {
+		if strings.Contains(msg, known.fragment) {
+			return known.text
+		}
+	}
+
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+	return "server error: " + msg
+}
@@ -0,0 +1,126 @@
+package holidays
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"http_calendar/internal/lib/models"
+	"strings"
+	"time"
+)
+
+// jsonFile is the layout of .json holiday files:
+//
+//	{"country": "US", "holidays": [{"date": "2025-01-01", "name": "New Year's Day"}]}
+type jsonFile struct {
+	Country  string `json:"country"`
+	Holidays []struct {
+		Date models.Date `json:"date"`
+		Name string      `json:"name"`
+	} `json:"holidays"`
+}
+
+// loadJSON adds the holidays of a .json file; fallbackCountry is used when
+// the file has no "country" field.
+func (s *Set) loadJSON(data []byte, fallbackCountry string) error {
+	var f jsonFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	country := f.Country
+	if country == "" {
+		country = fallbackCountry
+	}
+	for _, h := range f.Holidays {
+		s.Add(Holiday{Date: h.Date, Name: h.Name, Country: country})
+	}
+	return nil
+}
+
+// loadICS adds the all-day VEVENTs of an iCalendar file. Only DTSTART,
+// DTEND and SUMMARY are read; multi-day events (DTEND is exclusive per
+// RFC 5545) produce one holiday per day.
+func (s *Set) loadICS(data []byte, country string) error {
+	var (
+		inEvent    bool
+		start, end time.Time
+		summary    string
+	)
+	for i, line := range unfoldICS(data) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// drop parameters: DTSTART;VALUE=DATE:20250101
+		name, _, _ = strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, start, end, summary = true, time.Time{}, time.Time{}, ""
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			t, err := parseICSDate(value)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if strings.EqualFold(name, "DTSTART") {
+				start = t
+			} else {
+				end = t
+			}
+		case "SUMMARY":
+			if inEvent {
+				summary = unescapeICS(value)
+			}
+		case "END":
+			if !inEvent || !strings.EqualFold(value, "VEVENT") {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return fmt.Errorf("line %d: VEVENT without DTSTART", i+1)
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				s.Add(Holiday{Date: models.Date{Time: d}, Name: summary, Country: country})
+			}
+		}
+	}
+	return nil
+}
+
+// unfoldICS splits iCalendar content into logical lines, joining folded
+// continuation lines that start with a space or a tab.
+func unfoldICS(data []byte) []string {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICSDate accepts DATE (20250101) and DATE-TIME (20250101T000000Z) values.
+func parseICSDate(v string) (time.Time, error) {
+	if len(v) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", v)
+	}
+	return time.Parse("20060102", v[:8])
+}
+
+// unescapeICS reverts TEXT escaping of RFC 5545.
+func unescapeICS(v string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(v)
+}
@@ -0,0 +1,150 @@
+package holidays
+
+import (
+	"embed"
+	"fmt"
+	"http_calendar/internal/lib/models"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bundled holds the holiday sets shipped with the server, one JSON file per country.
+//
+//go:embed data/*.json
+var bundled embed.FS
+
+// Holiday is a public holiday (a non-working day) of a country.
+type Holiday struct {
+	Date    models.Date `json:"date"`    // Date of the holiday.
+	Name    string      `json:"name"`    // Name of the holiday.
+	Country string      `json:"country"` // Country is an ISO 3166 alpha-2 code, e.g. "US".
+}
+
+// Provider answers holiday queries for a country.
+type Provider interface {
+	// IsHoliday reports whether date is a holiday in country.
+	IsHoliday(country string, date models.Date) bool
+	// Holidays returns the holidays of country between from and to inclusive, ordered by date.
+	Holidays(country string, from, to time.Time) []Holiday
+}
+
+// Set is an in-memory Provider filled from bundled or user-supplied files.
+// It is safe for concurrent use.
+type Set struct {
+	mu   sync.RWMutex
+	days map[string]map[string]Holiday // days[country][dateKey] = Holiday
+}
+
+// NewSet returns an empty Set.
+func NewSet() *Set {
+	return &Set{days: make(map[string]map[string]Holiday)}
+}
+
+// NewBundledSet returns a Set with all bundled countries loaded.
+func NewBundledSet() (*Set, error) {
+	s := NewSet()
+	files, err := fs.Glob(bundled, "data/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		data, err := bundled.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.loadJSON(data, countryFromFilename(name)); err != nil {
+			return nil, fmt.Errorf("bundled %s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// Countries returns the codes of all loaded countries, sorted.
+func (s *Set) Countries() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]string, 0, len(s.days))
+	for c := range s.days {
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Add registers a holiday; a later holiday on the same date replaces the earlier one.
+func (s *Set) Add(h Holiday) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h.Country = strings.ToUpper(h.Country)
+	if _, ok := s.days[h.Country]; !ok {
+		s.days[h.Country] = make(map[string]Holiday)
+	}
+	s.days[h.Country][h.Date.String()] = h
+}
+
+// LoadFile adds the holidays of a .json or .ics file.
+// The country is taken from the "country" field of JSON files and
+// otherwise from the file name: "de.ics" holds German holidays.
+func (s *Set) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read holidays %s: %w", path, err)
+	}
+
+	country := countryFromFilename(path)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = s.loadJSON(data, country)
+	case ".ics":
+		err = s.loadICS(data, country)
+	default:
+		err = fmt.Errorf("unsupported format, want .json or .ics")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load holidays %s: %w", path, err)
+	}
+	return nil
+}
+
+// IsHoliday reports whether date is a holiday in country.
+func (s *Set) IsHoliday(country string, date models.Date) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.days[strings.ToUpper(country)][date.String()]
+	return ok
+}
+
+// Holidays returns the holidays of country between from and to inclusive, ordered by date.
+func (s *Set) Holidays(country string, from, to time.Time) []Holiday {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	days := s.days[strings.ToUpper(country)]
+	if len(days) == 0 {
+		return nil
+	}
+
+	var out []Holiday
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if h, ok := days[models.Date{Time: d}.String()]; ok {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// countryFromFilename turns "path/to/us.json" into "US".
+func countryFromFilename(path string) string {
+	base := filepath.Base(path)
+	return strings.ToUpper(strings.TrimSuffix(base, filepath.Ext(base)))
+}
@@ -0,0 +1,64 @@
+package holidays_test
+
+import (
+	"http_calendar/internal/holidays"
+	"http_calendar/internal/lib/models"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func parseDate(s string) models.Date {
+	t, _ := time.Parse("2006-01-02", s)
+	return models.Date{Time: t}
+}
+
+func TestBundledSet(t *testing.T) {
+	set, err := holidays.NewBundledSet()
+	if err != nil {
+		t.Fatalf("NewBundledSet failed: %v", err)
+	}
+	if !set.IsHoliday("us", parseDate("2025-07-04")) {
+		t.Fatalf("Expected July 4th to be a US holiday")
+	}
+	if set.IsHoliday("RU", parseDate("2025-07-04")) {
+		t.Fatalf("Expected July 4th not to be a RU holiday")
+	}
+	got := set.Holidays("RU", parseDate("2025-05-01").Time, parseDate("2025-05-31").Time)
+	if len(got) != 2 || got[0].Name != "Spring and Labour Day" || got[1].Date.String() != "2025-05-09" {
+		t.Fatalf("Unexpected RU holidays in May: %+v", got)
+	}
+}
+
+func TestLoadICS(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20251224\r\n" +
+		"DTEND;VALUE=DATE:20251227\r\n" +
+		"SUMMARY:Christmas\\, \r\n" +
+		" with family\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20251003T000000Z\r\n" +
+		"SUMMARY:Unity Day\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	path := filepath.Join(t.TempDir(), "de.ics")
+	if err := os.WriteFile(path, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	set := holidays.NewSet()
+	if err := set.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+
+	got := set.Holidays("DE", parseDate("2025-01-01").Time, parseDate("2025-12-31").Time)
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 holiday days, got %+v", got)
+	}
+	if got[0].Name != "Unity Day" || got[1].Name != "Christmas, with family" || got[3].Date.String() != "2025-12-26" {
+		t.Fatalf("Unexpected holidays: %+v", got)
+	}
+}
@@ -0,0 +1,46 @@
+package creator
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"log/slog"
+	"net/http"
+)
+
+type CalendarCreator interface {
+	// CreateCalendar creates a new named calendar for userId.
+	// Returns created calendar and error if saving to storage fails.
+	CreateCalendar(userId string, c models.Calendar) (models.Calendar, error)
+}
+
+func New(log *slog.Logger, creator CalendarCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.calendars.creator.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		calendar := models.NewCalendar(req.UserId, req.Name, req.Color, req.Visibility)
+		createdCalendar, err := creator.CreateCalendar(req.UserId, *calendar)
+
+		if err != nil {
+			log.Error("failed to create calendar", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to create calendar: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK(createdCalendar))
+	}
+}
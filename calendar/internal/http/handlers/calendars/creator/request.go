@@ -0,0 +1,10 @@
+package creator
+
+import "http_calendar/internal/lib/models"
+
+type Request struct {
+	UserId     string            `json:"user_id"    validate:"required"`
+	Name       string            `json:"name"       validate:"required"`
+	Color      string            `json:"color"      validate:"omitempty,hexcolor"`
+	Visibility models.Visibility `json:"visibility" validate:"omitempty,oneof=private busy public"`
+}
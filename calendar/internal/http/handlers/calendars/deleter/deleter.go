@@ -0,0 +1,44 @@
+package deleter
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"log/slog"
+	"net/http"
+)
+
+type CalendarDeleter interface {
+	// DeleteCalendar deletes the calendar with calendarId of userId together with its events.
+	// Returns an error if the calendar is not found or is the default one.
+	DeleteCalendar(userId string, calendarId string) error
+}
+
+func New(log *slog.Logger, deleter CalendarDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.calendars.deleter.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		err := deleter.DeleteCalendar(req.UserId, req.CalendarId)
+
+		if err != nil {
+			log.Error("failed to delete calendar", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to delete calendar: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK(nil))
+	}
+}
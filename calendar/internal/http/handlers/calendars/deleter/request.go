@@ -0,0 +1,6 @@
+package deleter
+
+type Request struct {
+	UserId     string `json:"user_id"     validate:"required"`
+	CalendarId string `json:"calendar_id" validate:"required"`
+}
@@ -0,0 +1,44 @@
+package getter
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"log/slog"
+	"net/http"
+)
+
+type CalendarGetter interface {
+	// GetCalendars returns all calendars of userId, the default one included.
+	GetCalendars(userId string) ([]models.Calendar, error)
+}
+
+func New(log *slog.Logger, getter CalendarGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.calendars.getter.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		req := Request{UserId: r.URL.Query().Get("user_id")}
+		if ok := request_helper.ValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		calendars, err := getter.GetCalendars(req.UserId)
+
+		if err != nil {
+			log.Error("failed to get calendars", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to get calendars: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK(calendars))
+	}
+}
@@ -0,0 +1,6 @@
+package getter
+
+// Request is built from the query string: ?user_id=1
+type Request struct {
+	UserId string `validate:"required"`
+}
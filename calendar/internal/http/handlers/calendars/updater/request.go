@@ -0,0 +1,11 @@
+package updater
+
+import "http_calendar/internal/lib/models"
+
+type Request struct {
+	UserId     string            `json:"user_id"     validate:"required"`
+	CalendarId string            `json:"calendar_id" validate:"required"`
+	Name       string            `json:"name"`
+	Color      string            `json:"color"       validate:"omitempty,hexcolor"`
+	Visibility models.Visibility `json:"visibility"  validate:"omitempty,oneof=private busy public"`
+}
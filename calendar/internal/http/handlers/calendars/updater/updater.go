@@ -0,0 +1,48 @@
+package updater
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"log/slog"
+	"net/http"
+)
+
+type CalendarUpdater interface {
+	// UpdateCalendar changes name, color or visibility of a calendar of userId.
+	// Empty fields keep their current values.
+	// Returns an error if the calendar does not exist or update fails.
+	UpdateCalendar(userId string, c *models.Calendar) (*models.Calendar, error)
+}
+
+func New(log *slog.Logger, updater CalendarUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.calendars.updater.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		calendar := models.NewCalendar(req.UserId, req.Name, req.Color, req.Visibility)
+		calendar.Id = req.CalendarId
+		updatedCalendar, err := updater.UpdateCalendar(req.UserId, calendar)
+
+		if err != nil {
+			log.Error("failed to update calendar", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to update calendar: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK(updatedCalendar))
+	}
+}
@@ -20,17 +20,18 @@ func New(log *slog.Logger, creator EventCreator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.creator.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("requestId", middleware.GetReqID(r.Context())),
 		)
 
 		var req Request
-		if ok := request_helper.DecodeAndValidateRequest(log, req, r, w); !ok {
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
 			return
 		}
 
 		event := models.NewEvent(req.UserId, req.Date, req.EventName)
+		event.CalendarId = req.CalendarId
 		createdEvent, err := creator.CreateEvent(req.UserId, *event)
 
 		if err != nil {
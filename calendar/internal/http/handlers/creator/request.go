@@ -5,7 +5,8 @@ import (
 )
 
 type Request struct {
-	UserId    string      `json:"user_id" validate:"required"`
-	Date      models.Date `json:"date"    validate:"ISO8601date"`
-	EventName string      `json:"event"   validate:"required"`
+	UserId     string      `json:"user_id"     validate:"required"`
+	CalendarId string      `json:"calendar_id"`
+	Date       models.Date `json:"date"        validate:"ISO8601date"`
+	EventName  string      `json:"event"       validate:"required"`
 }
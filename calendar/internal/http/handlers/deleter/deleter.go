@@ -20,13 +20,13 @@ func New(log *slog.Logger, deleter EventDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.deleter.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("requestId", middleware.GetReqID(r.Context())),
 		)
 
 		var req Request
-		if ok := request_helper.DecodeAndValidateRequest(log, req, r, w); !ok {
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
 			return
 		}
 
@@ -1 +1,59 @@
 package getter
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"log/slog"
+	"net/http"
+)
+
+type EventGetter interface {
+	// GetEventsForDay returns events of userId on date.
+	GetEventsForDay(userId string, date models.Date, calendarIds []string) ([]models.Event, error)
+	// GetEventsForWeek returns events of userId in the week containing date.
+	GetEventsForWeek(userId string, date models.Date, calendarIds []string) ([]models.Event, error)
+	// GetEventsForMonth returns events of userId in the month containing date.
+	GetEventsForMonth(userId string, date models.Date, calendarIds []string) ([]models.Event, error)
+}
+
+func New(log *slog.Logger, getter EventGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.getter.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		req := parseRequest(r)
+		if ok := request_helper.ValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		var (
+			events []models.Event
+			err    error
+		)
+		switch req.Period {
+		case PeriodWeek:
+			events, err = getter.GetEventsForWeek(req.UserId, req.Date, req.CalendarIds)
+		case PeriodMonth:
+			events, err = getter.GetEventsForMonth(req.UserId, req.Date, req.CalendarIds)
+		default:
+			events, err = getter.GetEventsForDay(req.UserId, req.Date, req.CalendarIds)
+		}
+
+		if err != nil {
+			log.Error("failed to get events", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to get events: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK(events))
+	}
+}
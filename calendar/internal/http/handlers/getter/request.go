@@ -0,0 +1,36 @@
+package getter
+
+import (
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/models"
+	"net/http"
+)
+
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+type Request struct {
+	UserId      string      `validate:"required"`
+	Date        models.Date `validate:"ISO8601date"`
+	Period      string      `validate:"oneof=day week month"`
+	CalendarIds []string
+}
+
+// parseRequest builds a Request from the query string:
+// ?user_id=1&date=2025-07-16&period=week&calendar_ids=work,personal
+func parseRequest(r *http.Request) Request {
+	q := r.URL.Query()
+	req := Request{
+		UserId:      q.Get("user_id"),
+		Date:        request_helper.QueryDate(r, "date"),
+		Period:      q.Get("period"),
+		CalendarIds: request_helper.QueryList(r, "calendar_ids"),
+	}
+	if req.Period == "" {
+		req.Period = PeriodDay
+	}
+	return req
+}
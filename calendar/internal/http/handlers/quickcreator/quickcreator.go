@@ -0,0 +1,58 @@
+package quickcreator
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
+	"http_calendar/internal/service"
+	"log/slog"
+	"net/http"
+)
+
+type QuickEventCreator interface {
+	// QuickAddEvent parses text in the user's timeZone into an event and,
+	// unless dryRun, creates it. Returns the event and its interpretation.
+	// Errors caused by text or timeZone wrap service.ErrInvalidQuickAdd.
+	QuickAddEvent(userId, calendarId, text, timeZone string, dryRun bool) (models.Event, string, error)
+}
+
+func New(log *slog.Logger, creator QuickEventCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.quickcreator.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		event, interpretation, err := creator.QuickAddEvent(req.UserId, req.CalendarId, req.Text, req.TimeZone, req.DryRun)
+
+		if errors.Is(err, service.ErrInvalidQuickAdd) {
+			log.Info("rejected quick-add text", slog.String("error", err.Error()))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to quick-add event: "+err.Error()))
+			return
+		}
+		if err != nil {
+			log.Error("failed to quick-add event", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to quick-add event: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, response.OK(Result{
+			Event:          event,
+			Interpretation: interpretation,
+			Created:        !req.DryRun,
+		}))
+	}
+}
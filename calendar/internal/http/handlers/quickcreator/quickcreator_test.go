@@ -0,0 +1,50 @@
+package quickcreator_test
+
+import (
+	"errors"
+	"fmt"
+	"http_calendar/internal/http/handlers/quickcreator"
+	"http_calendar/internal/lib/models"
+	"http_calendar/internal/service"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type stubCreator struct {
+	err error
+}
+
+func (s stubCreator) QuickAddEvent(userId, calendarId, text, timeZone string, dryRun bool) (models.Event, string, error) {
+	return models.Event{Id: "1", UserId: userId, Name: text}, "interpretation", s.err
+}
+
+func TestQuickAddStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"created", nil, http.StatusOK},
+		{"invalid_text", fmt.Errorf("%w: no title", service.ErrInvalidQuickAdd), http.StatusBadRequest},
+		{"storage", errors.New("disk on fire"), http.StatusServiceUnavailable},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"user_id":"1","text":"Review at 3pm"}`
+			req := httptest.NewRequest(http.MethodPost, "/events/quick", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			quickcreator.New(log, stubCreator{err: tt.err})(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
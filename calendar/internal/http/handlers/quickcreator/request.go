@@ -0,0 +1,19 @@
+package quickcreator
+
+import "http_calendar/internal/lib/models"
+
+type Request struct {
+	UserId     string `json:"user_id"     validate:"required"`
+	CalendarId string `json:"calendar_id"`
+	Text       string `json:"text"        validate:"required"`
+	TimeZone   string `json:"time_zone"`
+	DryRun     bool   `json:"dry_run"`
+}
+
+// Result is the response payload: the parsed (and, unless DryRun, created)
+// event together with a human-readable interpretation to confirm.
+type Result struct {
+	Event          models.Event `json:"event"`
+	Interpretation string       `json:"interpretation"`
+	Created        bool         `json:"created"`
+}
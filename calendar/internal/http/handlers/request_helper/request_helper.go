@@ -5,11 +5,39 @@ import (
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/models"
 	"io"
 	"log/slog"
 	"net/http"
+	"reflect"
+	"strings"
+	"time"
 )
 
+// validate is shared by all handlers; validator.Validate caches struct info and is safe for concurrent use.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// models.Date is validated by its YYYY-MM-DD representation
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		d := field.Interface().(models.Date)
+		if d.IsZero() {
+			return ""
+		}
+		return d.String()
+	}, models.Date{})
+	if err := v.RegisterValidation("ISO8601date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(time.DateOnly, fl.Field().String())
+		return err == nil
+	}); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// DecodeAndValidateRequest decodes the JSON body into req (a pointer) and validates it.
+// On failure it writes a 400 response and returns false.
 func DecodeAndValidateRequest(log *slog.Logger, req any, r *http.Request, w http.ResponseWriter) bool {
 	// try to decode request
 	err := render.DecodeJSON(r.Body, req)
@@ -28,8 +56,12 @@ func DecodeAndValidateRequest(log *slog.Logger, req any, r *http.Request, w http
 
 	log.Info("request body decoded", slog.Any("req", req))
 
-	// validate decoded request
-	validate := validator.Validate{}
+	return ValidateRequest(log, req, r, w)
+}
+
+// ValidateRequest validates an already decoded request (e.g. built from the query string).
+// On failure it writes a 400 response and returns false.
+func ValidateRequest(log *slog.Logger, req any, r *http.Request, w http.ResponseWriter) bool {
 	if err := validate.Struct(req); err != nil {
 		log.Error("failed to validate request", slog.String("error", err.Error()))
 		render.Status(r, http.StatusBadRequest)
@@ -38,3 +70,24 @@ func DecodeAndValidateRequest(log *slog.Logger, req any, r *http.Request, w http
 	}
 	return true
 }
+
+// QueryDate parses the YYYY-MM-DD query parameter key.
+// A missing or malformed value yields the zero Date, which fails ISO8601date validation.
+func QueryDate(r *http.Request, key string) models.Date {
+	d, err := models.ParseDate(r.URL.Query().Get(key))
+	if err != nil {
+		return models.Date{}
+	}
+	return d
+}
+
+// QueryList splits a comma-separated query parameter, dropping empty items.
+func QueryList(r *http.Request, key string) []string {
+	var out []string
+	for _, item := range strings.Split(r.URL.Query().Get(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -3,7 +3,9 @@ package updater
 import "http_calendar/internal/lib/models"
 
 type Request struct {
-	UserId    string      `json:"user_id" validate:"required"`
-	Date      models.Date `json:"date"    validate:"ISO8601date"`
-	EventName string      `json:"event"   validate:"required"`
+	UserId     string      `json:"user_id"     validate:"required"`
+	EventId    string      `json:"event_id"    validate:"required"`
+	CalendarId string      `json:"calendar_id"`
+	Date       models.Date `json:"date"        validate:"ISO8601date"`
+	EventName  string      `json:"event"       validate:"required"`
 }
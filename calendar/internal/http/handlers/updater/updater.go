@@ -11,7 +11,8 @@ import (
 )
 
 type EventUpdater interface {
-	// UpdateEvent updates an existing event for userId.
+	// UpdateEvent updates an existing event for userId; empty fields of e
+	// keep their stored values.
 	// Returns an error if the event does not exist or update fails.
 	UpdateEvent(userId string, e *models.Event) (*models.Event, error)
 }
@@ -20,17 +21,19 @@ func New(log *slog.Logger, updater EventUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.updater.New"
 
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 			slog.String("requestId", middleware.GetReqID(r.Context())),
 		)
 
 		var req Request
-		if ok := request_helper.DecodeAndValidateRequest(log, req, r, w); !ok {
+		if ok := request_helper.DecodeAndValidateRequest(log, &req, r, w); !ok {
 			return
 		}
 
 		event := models.NewEvent(req.UserId, req.Date, req.EventName)
+		event.Id = req.EventId
+		event.CalendarId = req.CalendarId
 		updateEvent, err := updater.UpdateEvent(req.UserId, event)
 
 		if err != nil {
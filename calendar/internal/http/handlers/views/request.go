@@ -0,0 +1,36 @@
+package views
+
+import (
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/models"
+	"net/http"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+	FormatHTML = "html"
+)
+
+type Request struct {
+	UserId      string      `validate:"required"`
+	Date        models.Date `validate:"ISO8601date"`
+	Format      string      `validate:"oneof=json text html"`
+	CalendarIds []string
+}
+
+// parseRequest builds a Request from the query string:
+// ?user_id=1&date=2025-07-16&format=text&calendar_ids=work,personal
+func parseRequest(r *http.Request) Request {
+	q := r.URL.Query()
+	req := Request{
+		UserId:      q.Get("user_id"),
+		Date:        request_helper.QueryDate(r, "date"),
+		Format:      q.Get("format"),
+		CalendarIds: request_helper.QueryList(r, "calendar_ids"),
+	}
+	if req.Format == "" {
+		req.Format = FormatJSON
+	}
+	return req
+}
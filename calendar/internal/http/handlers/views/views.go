@@ -0,0 +1,83 @@
+package views
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"http_calendar/internal/http/handlers/request_helper"
+	"http_calendar/internal/lib/api/response"
+	"http_calendar/internal/lib/grid"
+	"http_calendar/internal/lib/models"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type MonthViewGetter interface {
+	// GetMonthView returns a weeks × days grid covering the month of date.
+	GetMonthView(userId string, date models.Date, calendarIds []string) (models.Grid, error)
+}
+
+type WeekViewGetter interface {
+	// GetWeekView returns a one-row grid for the week of date.
+	GetWeekView(userId string, date models.Date, calendarIds []string) (models.Grid, error)
+}
+
+// NewMonth serves GET /views/month.
+func NewMonth(log *slog.Logger, getter MonthViewGetter) http.HandlerFunc {
+	return newView(log, "handlers.views.NewMonth", getter.GetMonthView)
+}
+
+// NewWeek serves GET /views/week.
+func NewWeek(log *slog.Logger, getter WeekViewGetter) http.HandlerFunc {
+	return newView(log, "handlers.views.NewWeek", getter.GetWeekView)
+}
+
+// newView builds a handler that renders the grid returned by getView as JSON,
+// plain text (cal-like) or HTML depending on the format query parameter.
+func newView(log *slog.Logger, op string, getView func(string, models.Date, []string) (models.Grid, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With(
+			slog.String("op", op),
+			slog.String("requestId", middleware.GetReqID(r.Context())),
+		)
+
+		req := parseRequest(r)
+		if ok := request_helper.ValidateRequest(log, &req, r, w); !ok {
+			return
+		}
+
+		g, err := getView(req.UserId, req.Date, req.CalendarIds)
+		if err != nil {
+			log.Error("failed to build view", slog.String("error", err.Error()))
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, response.Error("failed to build view: "+err.Error()))
+			return
+		}
+
+		var b strings.Builder
+		switch req.Format {
+		case FormatText:
+			err = grid.RenderText(&b, g)
+		case FormatHTML:
+			err = grid.RenderHTML(&b, g)
+		default:
+			render.Status(r, http.StatusOK)
+			render.JSON(w, r, response.OK(g))
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to render view", slog.String("error", err.Error()))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to render view: "+err.Error()))
+			return
+		}
+
+		render.Status(r, http.StatusOK)
+		if req.Format == FormatHTML {
+			render.HTML(w, r, b.String())
+		} else {
+			render.PlainText(w, r, b.String())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package views_test
+
+import (
+	"encoding/json"
+	"errors"
+	"http_calendar/internal/http/handlers/views"
+	"http_calendar/internal/lib/grid"
+	"http_calendar/internal/lib/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubGetter struct {
+	err error
+}
+
+func (s stubGetter) GetMonthView(userId string, date models.Date, calendarIds []string) (models.Grid, error) {
+	from := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	events := []models.Event{{UserId: userId, Date: date, Name: "Go workshop"}}
+	return grid.Build(from.Format("January 2006"), from, from.AddDate(0, 1, -1), true, events), s.err
+}
+
+func TestMonthView(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+		want   string
+	}{
+		{"json", "user_id=1&date=2025-07-16", nil, http.StatusOK, `"title":"July 2025"`},
+		{"text", "user_id=1&date=2025-07-16&format=text", nil, http.StatusOK, "14 15 16*17 18 19 20"},
+		{"html", "user_id=1&date=2025-07-16&format=html", nil, http.StatusOK, "<li>Go workshop</li>"},
+		{"bad_format", "user_id=1&date=2025-07-16&format=pdf", nil, http.StatusBadRequest, "Format"},
+		{"no_user", "date=2025-07-16", nil, http.StatusBadRequest, "UserId"},
+		{"storage", "user_id=1&date=2025-07-16", errors.New("disk on fire"), http.StatusServiceUnavailable, "disk on fire"},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/views/month?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			views.NewMonth(log, stubGetter{err: tt.err})(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Fatalf("body does not contain %q:\n%s", tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestMonthViewJSONIsGrid(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/views/month?user_id=1&date=2025-07-16", nil)
+	rec := httptest.NewRecorder()
+
+	views.NewMonth(slog.New(slog.NewTextHandler(io.Discard, nil)), stubGetter{})(rec, req)
+
+	var body struct {
+		Result models.Grid `json:"result"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(body.Result.Weeks) != 5 || body.Result.Weekdays[0] != "Mo" {
+		t.Fatalf("unexpected grid %+v", body.Result)
+	}
+}
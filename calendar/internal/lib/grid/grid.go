@@ -0,0 +1,66 @@
+package grid
+
+import (
+	"http_calendar/internal/lib/models"
+	"time"
+)
+
+// Build lays out the period [from; to] as full weeks starting on Monday or
+// Sunday and distributes events over the days. Days of adjacent weeks that
+// complete the first and last rows are kept as padding with InPeriod == false
+// and without events; so are events outside the period.
+func Build(title string, from, to time.Time, mondayBased bool, events []models.Event) models.Grid {
+	byDate := make(map[string][]models.Event, len(events))
+	for _, e := range events {
+		byDate[e.Date.String()] = append(byDate[e.Date.String()], e)
+	}
+
+	gridStart := WeekStart(from, mondayBased)
+	gridEnd := WeekStart(to, mondayBased).AddDate(0, 0, 6)
+
+	g := models.Grid{
+		Title:           title,
+		From:            models.Date{Time: from},
+		To:              models.Date{Time: to},
+		MondayBasedWeek: mondayBased,
+	}
+	for d := gridStart; d.Before(gridStart.AddDate(0, 0, 7)); d = d.AddDate(0, 0, 1) {
+		g.Weekdays = append(g.Weekdays, d.Weekday().String()[:2])
+	}
+
+	var week []models.GridDay
+	for d := gridStart; !d.After(gridEnd); d = d.AddDate(0, 0, 1) {
+		day := models.GridDay{
+			Date:     models.Date{Time: d},
+			InPeriod: !d.Before(from) && !d.After(to),
+			Events:   []models.Event{},
+		}
+		if day.InPeriod {
+			if evs, ok := byDate[day.Date.String()]; ok {
+				day.Events = evs
+			}
+		}
+		week = append(week, day)
+		if len(week) == 7 {
+			g.Weeks = append(g.Weeks, week)
+			week = nil
+		}
+	}
+	return g
+}
+
+// WeekStart returns midnight of the first day of the week containing t:
+// Monday if mondayBased, Sunday otherwise.
+func WeekStart(t time.Time, mondayBased bool) time.Time {
+	weekday := int(t.Weekday())
+
+	if mondayBased {
+		if weekday == 0 {
+			weekday = 7
+		}
+		weekday--
+	}
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -weekday)
+}
@@ -0,0 +1,58 @@
+package grid_test
+
+import (
+	"http_calendar/internal/lib/grid"
+	"http_calendar/internal/lib/models"
+	"slices"
+	"testing"
+)
+
+func TestBuildMonth(t *testing.T) {
+	events := []models.Event{
+		{Id: "1", Date: parseDate("2025-07-16"), Name: "Go workshop"},
+		{Id: "2", Date: parseDate("2025-07-16"), Name: "Retro"},
+		{Id: "3", Date: parseDate("2025-08-01"), Name: "Outside"},
+	}
+	g := grid.Build("July 2025", parseDate("2025-07-01").Time, parseDate("2025-07-31").Time, false, events)
+
+	if want := []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}; !slices.Equal(g.Weekdays, want) {
+		t.Fatalf("Weekdays = %v, want %v", g.Weekdays, want)
+	}
+	if len(g.Weeks) != 5 {
+		t.Fatalf("Expected 5 weeks, got %d", len(g.Weeks))
+	}
+	for _, week := range g.Weeks {
+		if len(week) != 7 {
+			t.Fatalf("Expected rows of 7 days, got %d", len(week))
+		}
+		for _, day := range week {
+			if day.Events == nil {
+				t.Fatalf("Events of %s is nil", day.Date)
+			}
+		}
+	}
+
+	first, last := g.Weeks[0][0], g.Weeks[4][6]
+	if first.Date.String() != "2025-06-29" || first.InPeriod {
+		t.Errorf("first day = %s (in period %v), want padding 2025-06-29", first.Date, first.InPeriod)
+	}
+	if last.Date.String() != "2025-08-02" || last.InPeriod {
+		t.Errorf("last day = %s (in period %v), want padding 2025-08-02", last.Date, last.InPeriod)
+	}
+	if day := g.Weeks[2][3]; day.Date.String() != "2025-07-16" || len(day.Events) != 2 {
+		t.Errorf("2025-07-16 = %s with %d events, want 2", day.Date, len(day.Events))
+	}
+	if day := g.Weeks[4][5]; day.Date.String() != "2025-08-01" || len(day.Events) != 0 {
+		t.Errorf("padding day %s got %d events, want none", day.Date, len(day.Events))
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	sunday := parseDate("2025-07-20").Time
+	if got := grid.WeekStart(sunday, true); !got.Equal(parseDate("2025-07-14").Time) {
+		t.Errorf("Monday-based week of Sunday starts %s, want 2025-07-14", got.Format("2006-01-02"))
+	}
+	if got := grid.WeekStart(sunday, false); !got.Equal(sunday) {
+		t.Errorf("Sunday-based week of Sunday starts %s, want 2025-07-20", got.Format("2006-01-02"))
+	}
+}
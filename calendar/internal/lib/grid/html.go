@@ -0,0 +1,37 @@
+package grid
+
+import (
+	"html/template"
+	"http_calendar/internal/lib/models"
+	"io"
+)
+
+// htmlTemplate renders a grid as a self-contained table.
+// Cells get the classes "out" (adjacent month) and "busy" (has events) for styling.
+var htmlTemplate = template.Must(template.New("grid").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; vertical-align: top; width: 8em; height: 5em; padding: 2px; }
+td.out { color: #aaa; }
+td.busy .day { font-weight: bold; }
+ul { margin: 0; padding-left: 1em; }
+</style>
+</head>
+<body>
+<table>
+<caption>{{.Title}}</caption>
+<tr>{{range .Weekdays}}<th>{{.}}</th>{{end}}</tr>
+{{range .Weeks}}<tr>{{range .}}<td class="{{if not .InPeriod}}out{{else if .Events}}busy{{end}}"><div class="day">{{.Date.Day}}</div>{{if .Events}}<ul>{{range .Events}}<li>{{.Name}}</li>{{end}}</ul>{{end}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// RenderHTML writes the grid as an HTML page with a table of weeks × days.
+func RenderHTML(w io.Writer, g models.Grid) error {
+	return htmlTemplate.Execute(w, g)
+}
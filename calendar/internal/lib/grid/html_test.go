@@ -0,0 +1,34 @@
+package grid_test
+
+import (
+	"bytes"
+	"http_calendar/internal/lib/grid"
+	"http_calendar/internal/lib/models"
+	"strings"
+	"testing"
+)
+
+func TestRenderHTML(t *testing.T) {
+	g := grid.Build("July 2025", parseDate("2025-07-01").Time, parseDate("2025-07-31").Time, true,
+		[]models.Event{{Date: parseDate("2025-07-16"), Name: "<b>Go</b> workshop"}})
+
+	var out bytes.Buffer
+	if err := grid.RenderHTML(&out, g); err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+
+	html := out.String()
+	for _, want := range []string{
+		"<caption>July 2025</caption>",
+		"<tr><th>Mo</th><th>Tu</th>",
+		`<td class="out"><div class="day">30</div></td>`,
+		`<td class="busy"><div class="day">16</div><ul><li>&lt;b&gt;Go&lt;/b&gt; workshop</li></ul></td>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("output does not contain %q:\n%s", want, html)
+		}
+	}
+	if got := strings.Count(html, "<tr><td"); got != 5 {
+		t.Errorf("Expected 5 week rows, got %d", got)
+	}
+}
@@ -0,0 +1,75 @@
+package grid
+
+import (
+	"fmt"
+	"http_calendar/internal/lib/models"
+	"io"
+	"strings"
+)
+
+// EventMarker is printed next to the day number of days that have events.
+const EventMarker = '*'
+
+// cellWidth is the width of a day column: two digits and a marker.
+const cellWidth = 3
+
+// RenderText writes the grid in the style of the UNIX `cal` utility:
+// a centered title, weekday headers and one row per week. Days that have
+// events are marked with EventMarker; padding days of adjacent months are blank.
+// The events themselves are listed below the grid, one per line.
+func RenderText(w io.Writer, g models.Grid) error {
+	var b strings.Builder
+
+	width := cellWidth*len(g.Weekdays) - 1
+	b.WriteString(center(g.Title, width))
+	b.WriteByte('\n')
+
+	for i, wd := range g.Weekdays {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%-2s", wd)
+	}
+	b.WriteByte('\n')
+
+	for _, week := range g.Weeks {
+		var row strings.Builder
+		for _, day := range week {
+			if !day.InPeriod {
+				row.WriteString("   ")
+				continue
+			}
+			marker := ' '
+			if len(day.Events) > 0 {
+				marker = EventMarker
+			}
+			fmt.Fprintf(&row, "%2d%c", day.Date.Day(), marker)
+		}
+		b.WriteString(strings.TrimRight(row.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	listed := false
+	for _, week := range g.Weeks {
+		for _, day := range week {
+			for _, e := range day.Events {
+				if !listed {
+					b.WriteByte('\n')
+					listed = true
+				}
+				fmt.Fprintf(&b, "%s %c %s\n", day.Date, EventMarker, e.Name)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// center pads s with spaces on the left so it is centered within width.
+func center(s string, width int) string {
+	if pad := (width - len(s)) / 2; pad > 0 {
+		return strings.Repeat(" ", pad) + s
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package grid_test
+
+import (
+	"bytes"
+	"http_calendar/internal/lib/grid"
+	"http_calendar/internal/lib/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseDate(s string) models.Date {
+	t, _ := time.Parse("2006-01-02", s)
+	return models.Date{Time: t}
+}
+
+func TestRenderTextMonth(t *testing.T) {
+	g := grid.Build("July 2025", parseDate("2025-07-01").Time, parseDate("2025-07-31").Time, true,
+		[]models.Event{{Date: parseDate("2025-07-16"), Name: "Go workshop"}})
+
+	var out bytes.Buffer
+	if err := grid.RenderText(&out, g); err != nil {
+		t.Fatalf("RenderText failed: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"     July 2025",
+		"Mo Tu We Th Fr Sa Su",
+		"    1  2  3  4  5  6",
+		" 7  8  9 10 11 12 13",
+		"14 15 16*17 18 19 20",
+		"21 22 23 24 25 26 27",
+		"28 29 30 31",
+		"",
+		"2025-07-16 * Go workshop",
+		"",
+	}, "\n")
+	if out.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRenderTextWeekSundayStart(t *testing.T) {
+	g := grid.Build("Week 2025-07-27 - 2025-08-02", parseDate("2025-07-27").Time, parseDate("2025-08-02").Time, false, nil)
+
+	var out bytes.Buffer
+	if err := grid.RenderText(&out, g); err != nil {
+		t.Fatalf("RenderText failed: %v", err)
+	}
+
+	want := "Week 2025-07-27 - 2025-08-02\nSu Mo Tu We Th Fr Sa\n27 28 29 30 31  1  2\n"
+	if out.String() != want {
+		t.Fatalf("got:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+// DefaultCalendarId is the identifier of the calendar every user owns implicitly.
+// Events created without an explicit calendar, as well as events stored before
+// multi-calendar support, belong to it.
+const DefaultCalendarId = "default"
+
+// HolidaysCalendarId is the identifier of the read-only calendar of public
+// holidays overlaid on event lists and views. Holiday events are not stored.
+const HolidaysCalendarId = "holidays"
+
+// DefaultCalendarColor is the color assigned to calendars created without one.
+const DefaultCalendarColor = "#4285f4"
+
+// Visibility controls how the events of a calendar are exposed to other users.
+type Visibility string
+
+const (
+	VisibilityPrivate Visibility = "private" // only the owner sees the events
+	VisibilityBusy    Visibility = "busy"    // others see free/busy blocks only
+	VisibilityPublic  Visibility = "public"  // everyone sees the events
+)
+
+// Calendar is a named collection of events owned by a single user,
+// e.g. "work", "personal" or "on-call".
+type Calendar struct {
+	Id         string     `json:"id"`         // Id is the unique identifier of the calendar within the user's calendars.
+	UserId     string     `json:"user_id"`    // UserId is the unique identifier of the user who owns the calendar.
+	Name       string     `json:"name"`       // Name is a human-readable calendar name.
+	Color      string     `json:"color"`      // Color is a hex color (#RRGGBB) used by clients to render the events.
+	Visibility Visibility `json:"visibility"` // Visibility is one of private, busy, public.
+}
+
+func NewCalendar(userId, name, color string, visibility Visibility) *Calendar {
+	return &Calendar{
+		UserId:     userId,
+		Name:       name,
+		Color:      color,
+		Visibility: visibility,
+	}
+}
+
+// NewDefaultCalendar returns the implicit default calendar of userId.
+func NewDefaultCalendar(userId string) *Calendar {
+	return &Calendar{
+		Id:         DefaultCalendarId,
+		UserId:     userId,
+		Name:       "Default",
+		Color:      DefaultCalendarColor,
+		Visibility: VisibilityPrivate,
+	}
+}
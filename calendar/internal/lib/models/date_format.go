@@ -1,13 +1,44 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Date is a wrapper around time.Time that enforces a unified date format.
 type Date struct {
 	time.Time
 }
 
+// ParseDate parses a YYYY-MM-DD string into a Date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{Time: t}, nil
+}
+
 // String returns the Date formatted as YYYY-MM-DD.
 func (d Date) String() string {
 	return d.Format(time.DateOnly)
 }
+
+// MarshalJSON encodes the Date as a YYYY-MM-DD string.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a YYYY-MM-DD string into the Date.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
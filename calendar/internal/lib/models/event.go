@@ -1,13 +1,20 @@
 package models
 
 // Event represents a calendar entry.
-// Each event is associated with a user, a date, and a text description.
+// Each event is associated with a user, a calendar, a date, and a text description.
 type Event struct {
-	Id     string `json:"id"`      // Id is the unique identifier of the event
-	UserId string `json:"user_id"` // UserId is the unique identifier of the user who owns the event.
-	Date   Date   `json:"date"`    // Date is the date (YYYY-MM-DD) of the event.
-	// TODO: time of the day
-	Name string `json:"event"` // Name is a name or brief description of the event.
+	Id         string `json:"id"`          // Id is the unique identifier of the event
+	UserId     string `json:"user_id"`     // UserId is the unique identifier of the user who owns the event.
+	CalendarId string `json:"calendar_id"` // CalendarId is the identifier of the user's calendar the event belongs to.
+	Date       Date   `json:"date"`        // Date is the date (YYYY-MM-DD) of the event.
+	Name       string `json:"event"`       // Name is a name or brief description of the event.
+
+	Time            string      `json:"time,omitempty"`             // Time is the start time (HH:MM) in TimeZone; empty for all-day events.
+	DurationMinutes int         `json:"duration_minutes,omitempty"` // DurationMinutes is the length of the event; 0 if unknown.
+	TimeZone        string      `json:"time_zone,omitempty"`        // TimeZone is the IANA zone of Date and Time, e.g. "Europe/Berlin".
+	Recurrence      *Recurrence `json:"recurrence,omitempty"`       // Recurrence is set for repeating events; Date is the first occurrence. It is metadata only: further occurrences are not listed.
+
+	Holiday bool `json:"holiday,omitempty"` // Holiday marks read-only events overlaid from the holiday calendar.
 }
 
 func NewEvent(userId string, date Date, name string) *Event {
@@ -0,0 +1,18 @@
+package models
+
+// GridDay is a single cell of a calendar view.
+type GridDay struct {
+	Date     Date    `json:"date"`      // Date of the cell.
+	InPeriod bool    `json:"in_period"` // InPeriod is false for leading/trailing days of adjacent months.
+	Events   []Event `json:"events"`    // Events of the day, never nil.
+}
+
+// Grid is a ready-to-render calendar view: weeks × days, each day with its events.
+type Grid struct {
+	Title           string      `json:"title"`             // Title is e.g. "July 2025" or "Week 2025-07-14 - 2025-07-20".
+	From            Date        `json:"from"`              // From is the first day of the view period.
+	To              Date        `json:"to"`                // To is the last day of the view period.
+	MondayBasedWeek bool        `json:"monday_based_week"` // MondayBasedWeek tells whether rows start on Monday.
+	Weekdays        []string    `json:"weekdays"`          // Weekdays are column headers ("Mo", "Tu", ...) in grid order.
+	Weeks           [][]GridDay `json:"weeks"`             // Weeks are grid rows of exactly 7 days.
+}
@@ -0,0 +1,19 @@
+package models
+
+// Frequency is the base unit of a Recurrence.
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+	FrequencyYearly  Frequency = "yearly"
+)
+
+// Recurrence describes how an event repeats, in the spirit of RFC 5545 RRULE:
+// every Interval units of Frequency, starting from the event Date.
+type Recurrence struct {
+	Frequency Frequency `json:"frequency"`          // Frequency is daily, weekly, monthly or yearly.
+	Interval  int       `json:"interval"`           // Interval is 1 for "every week", 2 for "every other week".
+	Weekdays  []string  `json:"weekdays,omitempty"` // Weekdays restrict weekly recurrences, e.g. ["monday", "friday"].
+}
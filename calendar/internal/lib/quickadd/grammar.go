@@ -0,0 +1,553 @@
+package quickadd
+
+import (
+	"http_calendar/internal/lib/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// token is a single word of the phrase: raw keeps the user's spelling for
+// the event name, norm is the lower-cased word without surrounding punctuation.
+type token struct {
+	raw  string
+	norm string
+}
+
+func tokenize(text string) []token {
+	fields := strings.Fields(text)
+	toks := make([]token, len(fields))
+	for i, f := range fields {
+		toks[i] = token{raw: f, norm: strings.Trim(strings.ToLower(f), ",.;!?()\"")}
+	}
+	return toks
+}
+
+// parser holds the state of a single Parse call. Every match* method tries
+// to recognize a phrase starting at token i and returns the number of tokens
+// it consumed, 0 if the phrase does not match. A phrase kind that is already
+// filled in is not matched again, so repeated words stay in the event name.
+type parser struct {
+	toks  []token
+	used  []bool
+	today time.Time
+	res   Result
+	err   error // the first phrase that was recognized but is invalid
+}
+
+// fail records err unless an earlier phrase failed.
+func (p *parser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// at returns the normalized token i, or "" if it is out of range or consumed.
+func (p *parser) at(i int) string {
+	if i < 0 || i >= len(p.toks) || p.used[i] {
+		return ""
+	}
+	return p.toks[i].norm
+}
+
+func (p *parser) consume(i, n int) {
+	for k := i; k < i+n; k++ {
+		p.used[k] = true
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var numberWords = map[string]int{
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var (
+	clockRe    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a\.m|p\.m)?$`)
+	dayRe      = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+	yearRe     = regexp.MustCompile(`^\d{4}$`)
+	compactRe  = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)h(?:rs?|ours?)?)?(?:(\d+)m(?:ins?|inutes?)?)?$`)
+	isoDateRe  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	minuteUnit = map[string]int{
+		"h": 60, "hr": 60, "hrs": 60, "hour": 60, "hours": 60,
+		"m": 1, "min": 1, "mins": 1, "minute": 1, "minutes": 1,
+	}
+)
+
+// weekdayAt parses a weekday name. Abbreviations and plurals ("fri",
+// "fridays") are accepted only when strict is false, so that a bare
+// "Sun cream" stays in the event name.
+func weekdayAt(s string, strict bool) (time.Weekday, bool) {
+	wd, ok := weekdays[s]
+	if strict {
+		return wd, ok && s == strings.ToLower(wd.String())
+	}
+	if ok {
+		return wd, true
+	}
+	wd, ok = weekdays[strings.TrimSuffix(s, "s")]
+	return wd, ok
+}
+
+func numberAt(s string) (int, bool) {
+	if n, ok := numberWords[s]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(s)
+	return n, err == nil && n > 0
+}
+
+// --- recurrence ---
+
+func (p *parser) matchRecurrence(i int) int {
+	if p.res.Recurrence != nil {
+		return 0
+	}
+	simple := map[string]models.Frequency{
+		"daily": models.FrequencyDaily, "weekly": models.FrequencyWeekly,
+		"monthly": models.FrequencyMonthly, "yearly": models.FrequencyYearly, "annually": models.FrequencyYearly,
+	}
+	if f, ok := simple[p.at(i)]; ok {
+		p.res.Recurrence = &models.Recurrence{Frequency: f, Interval: 1}
+		return 1
+	}
+	if p.at(i) != "every" {
+		return 0
+	}
+
+	j, interval := i+1, 1
+	if p.at(j) == "other" {
+		j, interval = j+1, 2
+	} else if n, ok := numberAt(p.at(j)); ok {
+		j, interval = j+1, n
+	}
+
+	units := map[string]models.Frequency{
+		"day": models.FrequencyDaily, "week": models.FrequencyWeekly,
+		"month": models.FrequencyMonthly, "year": models.FrequencyYearly,
+	}
+	unit := strings.TrimSuffix(p.at(j), "s")
+	if f, ok := units[unit]; ok {
+		p.res.Recurrence = &models.Recurrence{Frequency: f, Interval: interval}
+		j++
+		// every 3 weeks on friday [and] [monday] ...
+		if f == models.FrequencyWeekly && p.at(j) == "on" {
+			if days, n := p.weekdaysAt(j + 1); n > 0 {
+				p.res.Recurrence.Weekdays = days
+				j += 1 + n
+			}
+		}
+		return j - i
+	}
+	if unit == "weekday" && interval == 1 {
+		p.res.Recurrence = &models.Recurrence{
+			Frequency: models.FrequencyWeekly,
+			Interval:  1,
+			Weekdays:  []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+		}
+		return j + 1 - i
+	}
+
+	// every [other] monday [and] [wed] ...
+	days, n := p.weekdaysAt(j)
+	if n == 0 {
+		return 0
+	}
+	p.res.Recurrence = &models.Recurrence{Frequency: models.FrequencyWeekly, Interval: interval, Weekdays: days}
+	return j + n - i
+}
+
+// weekdaysAt parses a list of weekdays like "monday and wed" at token i.
+// Returns the lower-cased weekday names and the number of consumed tokens.
+func (p *parser) weekdaysAt(i int) ([]string, int) {
+	var days []string
+	j := i
+	for {
+		wd, ok := weekdayAt(p.at(j), false)
+		if !ok {
+			break
+		}
+		days = append(days, strings.ToLower(wd.String()))
+		j++
+		if p.at(j) == "and" {
+			if _, ok := weekdayAt(p.at(j+1), false); ok {
+				j++
+			}
+		}
+	}
+	return days, j - i
+}
+
+// --- dates ---
+
+func (p *parser) matchDate(i int) int {
+	if !p.res.Date.IsZero() {
+		return 0
+	}
+	set := func(d time.Time, n int) int {
+		p.res.Date = d
+		return n
+	}
+
+	switch p.at(i) {
+	case "today", "tonight":
+		return set(p.today, 1)
+	case "tomorrow", "tmrw", "tmr":
+		return set(p.today.AddDate(0, 0, 1), 1)
+	case "day":
+		if p.at(i+1) == "after" && p.at(i+2) == "tomorrow" {
+			return set(p.today.AddDate(0, 0, 2), 3)
+		}
+	case "next":
+		switch p.at(i + 1) {
+		case "week":
+			return set(p.today.AddDate(0, 0, 7), 2)
+		case "month":
+			return set(p.today.AddDate(0, 1, 0), 2)
+		}
+		if wd, ok := weekdayAt(p.at(i+1), false); ok {
+			return set(onOrAfter(p.today, wd).AddDate(0, 0, 7), 2)
+		}
+	case "in":
+		if n := p.matchRelative(i + 1); n > 0 {
+			return n + 1
+		}
+	}
+
+	j := i
+	if p.at(j) == "on" || p.at(j) == "this" {
+		j++
+		if wd, ok := weekdayAt(p.at(j), false); ok {
+			return set(onOrAfter(p.today, wd), j+1-i)
+		}
+	} else if wd, ok := weekdayAt(p.at(j), true); ok {
+		return set(onOrAfter(p.today, wd), 1)
+	}
+
+	if isoDateRe.MatchString(p.at(j)) {
+		d, err := time.ParseInLocation(time.DateOnly, p.at(j), p.today.Location())
+		if err == nil {
+			return set(d, j+1-i)
+		}
+	}
+	if n := p.matchDayOfYear(j); n > 0 {
+		return j - i + n
+	}
+	return 0
+}
+
+// matchRelative parses "3 days", "two weeks", "a month" after "in".
+func (p *parser) matchRelative(i int) int {
+	n, ok := numberAt(p.at(i))
+	if !ok && (p.at(i) == "a" || p.at(i) == "an") {
+		n, ok = 1, true
+	}
+	if !ok {
+		return 0
+	}
+	switch strings.TrimSuffix(p.at(i+1), "s") {
+	case "day":
+		p.res.Date = p.today.AddDate(0, 0, n)
+	case "week":
+		p.res.Date = p.today.AddDate(0, 0, 7*n)
+	case "month":
+		p.res.Date = p.today.AddDate(0, n, 0)
+	default:
+		return 0
+	}
+	return 2
+}
+
+// matchDayOfYear parses "july 16[th] [2025]" and "16[th] jul [2025]".
+func (p *parser) matchDayOfYear(i int) int {
+	var (
+		month time.Month
+		day   int
+		ok    bool
+	)
+	if month, ok = months[p.at(i)]; ok {
+		day, ok = dayAt(p.at(i + 1))
+	} else if day, ok = dayAt(p.at(i)); ok {
+		month, ok = months[p.at(i+1)]
+	}
+	if !ok {
+		return 0
+	}
+
+	n, year := 2, p.today.Year()
+	if yearRe.MatchString(p.at(i + 2)) {
+		year, _ = strconv.Atoi(p.at(i + 2))
+		n = 3
+	}
+	d := time.Date(year, month, day, 0, 0, 0, 0, p.today.Location())
+	if d.Day() != day {
+		return 0 // e.g. "february 30"
+	}
+	if n == 2 && d.Before(p.today) {
+		d = d.AddDate(1, 0, 0)
+	}
+	p.res.Date = d
+	return n
+}
+
+func dayAt(s string) (int, bool) {
+	m := dayRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	d, _ := strconv.Atoi(m[1])
+	return d, d >= 1 && d <= 31
+}
+
+// onOrAfter returns the first day with weekday wd on or after from.
+func onOrAfter(from time.Time, wd time.Weekday) time.Time {
+	return from.AddDate(0, 0, (int(wd)-int(from.Weekday())+7)%7)
+}
+
+// firstWeekday returns the first day on or after from whose weekday is in names.
+func firstWeekday(from time.Time, names []string) time.Time {
+	var first time.Time
+	for _, name := range names {
+		if wd, ok := weekdays[name]; ok {
+			if d := onOrAfter(from, wd); first.IsZero() || d.Before(first) {
+				first = d
+			}
+		}
+	}
+	if first.IsZero() {
+		return from
+	}
+	return first
+}
+
+// --- times ---
+
+func (p *parser) matchTime(i int) int {
+	if p.res.Time != "" {
+		return 0
+	}
+	j := i
+	if p.at(j) == "at" {
+		j++
+	}
+	minutes, n := p.clockAt(j, j > i)
+	if n == 0 {
+		return 0
+	}
+	p.res.Time = formatClock(minutes)
+	return j - i + n
+}
+
+// matchRange parses "[from] 2pm to 3:30pm", "2pm - 3pm" and "2-3pm" into a
+// start time and a duration.
+func (p *parser) matchRange(i int) int {
+	if p.res.Time != "" || p.res.DurationMinutes != 0 {
+		return 0
+	}
+	j := i
+	if p.at(j) == "from" {
+		j++
+	}
+
+	// "2-3pm" or "2pm-3pm" in a single token
+	if a, b, ok := strings.Cut(p.at(j), "-"); ok {
+		end, ok := parseClock(b, "", false)
+		if !ok {
+			return 0
+		}
+		start, ok := parseClock(a, suffixOf(b), true)
+		if ok && start >= end && suffixOf(a) == "" && suffixOf(b) == "pm" {
+			start, ok = parseClock(a, "am", true) // "11-1pm" starts in the morning
+		}
+		if !ok {
+			return 0
+		}
+		return j - i + p.setRange(start, end, 1)
+	}
+
+	start, n := p.clockAt(j, j > i)
+	if n == 0 {
+		return 0
+	}
+	switch p.at(j + n) {
+	case "to", "until", "till", "-":
+	default:
+		return 0
+	}
+	end, m := p.clockAt(j+n+1, true)
+	if m == 0 {
+		return 0
+	}
+	return j - i + p.setRange(start, end, n+1+m)
+}
+
+// setRange sets the start time and the duration of a range on a single
+// day: an end at midnight is the end of the day.
+func (p *parser) setRange(start, end, n int) int {
+	if end == 0 {
+		end = 24 * 60
+	}
+	if end <= start {
+		p.fail(ErrEndBeforeStart)
+	}
+	p.res.Time = formatClock(start)
+	p.res.DurationMinutes = end - start
+	return n
+}
+
+// clockAt parses a time of day at token i, optionally followed by a separate
+// "am"/"pm" token. Bare hours ("at 5") are accepted only if allowBare is set.
+// Returns minutes since midnight and the number of consumed tokens.
+func (p *parser) clockAt(i int, allowBare bool) (int, int) {
+	s := p.at(i)
+	switch s {
+	case "noon":
+		return 12 * 60, 1
+	case "midnight":
+		return 0, 1
+	}
+	if next := p.at(i + 1); next == "am" || next == "pm" || next == "a.m" || next == "p.m" {
+		if m, ok := parseClock(s+next, "", true); ok {
+			return m, 2
+		}
+	}
+	if m, ok := parseClock(s, "", allowBare); ok {
+		return m, 1
+	}
+	return 0, 0
+}
+
+// parseClock parses "1pm", "1:30pm", "13:00" and, with allowBare, "13".
+// defaultSuffix applies "am"/"pm" to a clock without one, as in "2-3pm".
+func parseClock(s, defaultSuffix string, allowBare bool) (int, bool) {
+	m := clockRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	minutes := 0
+	if m[2] != "" {
+		minutes, _ = strconv.Atoi(m[2])
+	}
+	suffix := strings.ReplaceAll(m[3], ".", "")
+	if suffix == "" {
+		suffix = defaultSuffix
+	}
+	if suffix == "" && m[2] == "" && !allowBare {
+		return 0, false
+	}
+	if minutes > 59 {
+		return 0, false
+	}
+
+	switch suffix {
+	case "am", "pm":
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		h %= 12
+		if suffix == "pm" {
+			h += 12
+		}
+	default:
+		if h > 23 {
+			return 0, false
+		}
+	}
+	return h*60 + minutes, true
+}
+
+func suffixOf(s string) string {
+	if m := clockRe.FindStringSubmatch(s); m != nil {
+		return strings.ReplaceAll(m[3], ".", "")
+	}
+	return ""
+}
+
+func formatClock(minutes int) string {
+	return time.Date(0, 1, 1, minutes/60, minutes%60, 0, 0, time.UTC).Format("15:04")
+}
+
+// --- durations ---
+
+// maxDurationMinutes is the longest duration accepted: a week.
+const maxDurationMinutes = 7 * 24 * 60
+
+// setDuration sets a duration of v minutes, which must be at least a minute
+// and at most maxDurationMinutes, and returns n.
+func (p *parser) setDuration(v float64, n int) int {
+	if !(v >= 1 && v <= maxDurationMinutes) {
+		p.fail(ErrInvalidDuration)
+		return n
+	}
+	p.res.DurationMinutes = int(v)
+	return n
+}
+
+func (p *parser) matchDuration(i int) int {
+	if p.res.DurationMinutes != 0 {
+		return 0
+	}
+	j := i
+	if p.at(j) == "for" {
+		j++
+	}
+
+	if p.at(j) == "half" && p.at(j+1) == "an" && p.at(j+2) == "hour" {
+		p.res.DurationMinutes = 30
+		return j + 3 - i
+	}
+	if a := p.at(j); (a == "a" || a == "an") && j > i {
+		if unit, ok := minuteUnit[p.at(j+1)]; ok {
+			p.res.DurationMinutes = unit
+			return j + 2 - i
+		}
+	}
+
+	// "2 hours", "1.5 h", "90 minutes"
+	if v, err := strconv.ParseFloat(p.at(j), 64); err == nil {
+		if unit, ok := minuteUnit[p.at(j+1)]; ok {
+			return p.setDuration(v*float64(unit), j+2-i)
+		}
+	}
+
+	// "1h", "1h30m", "90min"
+	if m := compactRe.FindStringSubmatch(p.at(j)); m != nil && (m[1] != "" || m[2] != "") {
+		total := 0.0
+		if m[1] != "" {
+			h, _ := strconv.ParseFloat(m[1], 64)
+			total += h * 60
+		}
+		if m[2] != "" {
+			mins, _ := strconv.Atoi(m[2])
+			total += float64(mins)
+		}
+		return p.setDuration(total, j+1-i)
+	}
+	return 0
+}
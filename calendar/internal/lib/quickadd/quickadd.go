@@ -0,0 +1,158 @@
+package quickadd
+
+import (
+	"errors"
+	"fmt"
+	"http_calendar/internal/lib/models"
+	"strings"
+	"time"
+)
+
+// ErrNoTitle is returned when nothing is left for the event name after
+// date, time, duration and recurrence phrases are taken out.
+var ErrNoTitle = errors.New("no event description found")
+
+// ErrInvalidDuration is returned for durations shorter than a minute or
+// longer than a week, like "for 0 hours".
+var ErrInvalidDuration = errors.New("duration must be between 1 minute and 7 days")
+
+// ErrEndBeforeStart is returned for time ranges that end before they start,
+// like "from 5pm to 3pm".
+var ErrEndBeforeStart = errors.New("end time is before start time")
+
+// Result is the interpretation of a quick-add phrase.
+type Result struct {
+	Name            string             // Name is what remains of the phrase after the recognized parts.
+	Date            time.Time          // Date is the (first) day of the event at midnight in the user's location.
+	Time            string             // Time is the start time as HH:MM; empty for all-day events.
+	DurationMinutes int                // DurationMinutes is 0 if no duration was given.
+	Recurrence      *models.Recurrence // Recurrence is nil for one-off events.
+}
+
+// Parse interprets phrases like "Lunch with Ana tomorrow at 1pm for 1h" or
+// "retro every other Friday 16:00" relative to now; now also carries the
+// user's location. Parsing is deterministic and works offline: the same
+// phrase and now always give the same Result.
+//
+// Recognized English phrases (case-insensitive, in any order):
+//
+//	dates:       today, tonight, tomorrow, day after tomorrow, [on|this] friday,
+//	             next friday (a week after "friday"), next week, next month,
+//	             in 3 days|weeks|months, [on] july 16[th] [2025], [on] 16 jul [2025],
+//	             [on] 2025-07-16
+//	times:       [at] 1pm, [at] 1:30 pm, [at] 13:00, noon, midnight
+//	ranges:      [from] 2pm to|until|- 3:30pm
+//	durations:   for 1h, for 1h30m, for 90 min, for 2 hours, for an hour, for half an hour
+//	recurrences: daily, weekly, monthly, yearly, every day|week|month|year,
+//	             every weekday, every 2 weeks [on friday], every other friday,
+//	             every monday and wednesday
+//
+// A day-of-year without a year that has already passed refers to next year.
+// Without a date, the event is today, or on the first matching weekday of a
+// weekly recurrence. A range ends on the day it starts, at midnight at the
+// latest, and a duration is at most a week.
+func Parse(text string, now time.Time) (Result, error) {
+	p := &parser{
+		toks:  tokenize(text),
+		today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+	p.used = make([]bool, len(p.toks))
+
+	rules := []func(int) int{
+		p.matchRecurrence,
+		p.matchRange,
+		p.matchDate,
+		p.matchTime,
+		p.matchDuration,
+	}
+	for i := 0; i < len(p.toks); i++ {
+		for _, rule := range rules {
+			if n := rule(i); n > 0 {
+				p.consume(i, n)
+				i += n - 1
+				break
+			}
+		}
+	}
+
+	if p.err != nil {
+		return Result{}, p.err
+	}
+
+	var name []string
+	for i, t := range p.toks {
+		if !p.used[i] {
+			name = append(name, t.raw)
+		}
+	}
+	p.res.Name = strings.Trim(strings.Join(name, " "), " ,;-")
+	if p.res.Name == "" {
+		return Result{}, ErrNoTitle
+	}
+
+	if p.res.Date.IsZero() {
+		p.res.Date = p.today
+		if r := p.res.Recurrence; r != nil && len(r.Weekdays) > 0 {
+			p.res.Date = firstWeekday(p.today, r.Weekdays)
+		}
+	}
+	return p.res, nil
+}
+
+// Describe returns a human-readable interpretation of r, e.g.
+// `"Lunch with Ana" on Thu, 17 Jul 2025 at 13:00 for 1h (Europe/Berlin)`.
+// Recurrence is only recorded on the event, so a repeating event's
+// interpretation says that just the first occurrence is added.
+func (r Result) Describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q on %s", r.Name, r.Date.Format("Mon, 2 Jan 2006"))
+	if r.Time != "" {
+		b.WriteString(" at " + r.Time)
+	} else {
+		b.WriteString(" (all day)")
+	}
+	if r.DurationMinutes > 0 {
+		b.WriteString(" for " + formatMinutes(r.DurationMinutes))
+	}
+	if rec := r.Recurrence; rec != nil {
+		b.WriteString(", repeats " + describeRecurrence(rec))
+	}
+	fmt.Fprintf(&b, " (%s)", r.Date.Location())
+	if r.Recurrence != nil {
+		b.WriteString("; only the first occurrence is added to the calendar")
+	}
+	return b.String()
+}
+
+func describeRecurrence(r *models.Recurrence) string {
+	unit := map[models.Frequency]string{
+		models.FrequencyDaily:   "day",
+		models.FrequencyWeekly:  "week",
+		models.FrequencyMonthly: "month",
+		models.FrequencyYearly:  "year",
+	}[r.Frequency]
+
+	s := "every " + unit
+	switch {
+	case r.Interval == 2:
+		s = "every other " + unit
+	case r.Interval > 2:
+		s = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	if len(r.Weekdays) > 0 {
+		s += " on " + strings.Join(r.Weekdays, ", ")
+	}
+	return s
+}
+
+func formatMinutes(m int) string {
+	h, m := m/60, m%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
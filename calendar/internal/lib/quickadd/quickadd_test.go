@@ -0,0 +1,144 @@
+package quickadd_test
+
+import (
+	"http_calendar/internal/lib/models"
+	"http_calendar/internal/lib/quickadd"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday
+	now := time.Date(2025, 7, 16, 9, 30, 0, 0, berlin)
+
+	tests := []struct {
+		text string
+		want quickadd.Result
+	}{
+		{
+			"Lunch with Ana tomorrow at 1pm for 1h",
+			quickadd.Result{Name: "Lunch with Ana", Date: day(2025, 7, 17, berlin), Time: "13:00", DurationMinutes: 60},
+		},
+		{
+			"retro every other Friday 16:00",
+			quickadd.Result{
+				Name: "retro", Date: day(2025, 7, 18, berlin), Time: "16:00",
+				Recurrence: &models.Recurrence{Frequency: models.FrequencyWeekly, Interval: 2, Weekdays: []string{"friday"}},
+			},
+		},
+		{
+			"Dentist on July 3 at 9:15 am",
+			quickadd.Result{Name: "Dentist", Date: day(2026, 7, 3, berlin), Time: "09:15"},
+		},
+		{
+			"Standup every weekday at 10:00 for 15 min",
+			quickadd.Result{
+				Name: "Standup", Date: day(2025, 7, 16, berlin), Time: "10:00", DurationMinutes: 15,
+				Recurrence: &models.Recurrence{
+					Frequency: models.FrequencyWeekly, Interval: 1,
+					Weekdays: []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+				},
+			},
+		},
+		{
+			"Workshop from 2pm to 3:30pm next monday",
+			quickadd.Result{Name: "Workshop", Date: day(2025, 7, 28, berlin), Time: "14:00", DurationMinutes: 90},
+		},
+		{
+			"Call mom in 3 days 6-7pm",
+			quickadd.Result{Name: "Call mom", Date: day(2025, 7, 19, berlin), Time: "18:00", DurationMinutes: 60},
+		},
+		{
+			"Pay rent monthly 2025-08-01",
+			quickadd.Result{
+				Name: "Pay rent", Date: day(2025, 8, 1, berlin),
+				Recurrence: &models.Recurrence{Frequency: models.FrequencyMonthly, Interval: 1},
+			},
+		},
+		{
+			"Sun cream at noon for half an hour",
+			quickadd.Result{Name: "Sun cream", Date: day(2025, 7, 16, berlin), Time: "12:00", DurationMinutes: 30},
+		},
+		{
+			"x every 3 weeks on friday",
+			quickadd.Result{
+				Name: "x", Date: day(2025, 7, 18, berlin),
+				Recurrence: &models.Recurrence{Frequency: models.FrequencyWeekly, Interval: 3, Weekdays: []string{"friday"}},
+			},
+		},
+		{
+			"Party from 10pm to midnight",
+			quickadd.Result{Name: "Party", Date: day(2025, 7, 16, berlin), Time: "22:00", DurationMinutes: 120},
+		},
+		{
+			"Brunch 11-1pm",
+			quickadd.Result{Name: "Brunch", Date: day(2025, 7, 16, berlin), Time: "11:00", DurationMinutes: 120},
+		},
+		{
+			"Meeting at office friday",
+			quickadd.Result{Name: "Meeting at office", Date: day(2025, 7, 18, berlin)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := quickadd.Parse(tt.text, now)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v %+v\nwant %+v %+v", got, got.Recurrence, tt.want, tt.want.Recurrence)
+			}
+		})
+	}
+}
+
+func TestParseNoTitle(t *testing.T) {
+	if _, err := quickadd.Parse("tomorrow at 5pm", time.Now()); err != quickadd.ErrNoTitle {
+		t.Fatalf("got %v, want ErrNoTitle", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want error
+	}{
+		{"Nap for 0 hours", quickadd.ErrInvalidDuration},
+		{"Nap for -2 hours", quickadd.ErrInvalidDuration},
+		{"Nap for 0h", quickadd.ErrInvalidDuration},
+		{"Retreat for 99999999999999999 hours", quickadd.ErrInvalidDuration},
+		{"Retreat for 170h", quickadd.ErrInvalidDuration},
+		{"Review from 5pm to 3pm", quickadd.ErrEndBeforeStart},
+		{"Review 5pm-3pm", quickadd.ErrEndBeforeStart},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if _, err := quickadd.Parse(tt.text, time.Now()); err != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	r, err := quickadd.Parse("retro every other Friday 16:00 for 1h30m", time.Date(2025, 7, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := `"retro" on Fri, 18 Jul 2025 at 16:00 for 1h30m, repeats every other week on friday (UTC); only the first occurrence is added to the calendar`
+	if got := r.Describe(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func day(y int, m time.Month, d int, loc *time.Location) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
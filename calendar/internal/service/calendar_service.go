@@ -1,12 +1,26 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"http_calendar/internal/holidays"
+	"http_calendar/internal/lib/grid"
 	"http_calendar/internal/lib/models"
+	"http_calendar/internal/lib/quickadd"
 	"http_calendar/internal/storage"
+	"slices"
+	"sort"
 	"time"
 )
 
+// ErrDefaultCalendar is returned on attempts to delete a user's default calendar.
+var ErrDefaultCalendar = errors.New("default calendar cannot be deleted")
+
+// ErrInvalidQuickAdd wraps the errors of QuickAddEvent caused by its input:
+// text the parser rejects or an unknown time zone.
+var ErrInvalidQuickAdd = errors.New("invalid quick-add request")
+
 // CalendarService provides business logic for creating, updating,
 // deleting, and querying calendar events for users.
 // It depends on an abstract storage layer and supports operations
@@ -16,6 +30,13 @@ type CalendarService struct {
 	repo storage.Storage
 	// mondayBasedWeek indicates if weeks start on Monday (true) or Sunday (false).
 	mondayBasedWeek bool
+	// holidays is an optional holiday provider; nil disables the overlay
+	// and makes only weekends non-working.
+	holidays holidays.Provider
+	// country selects the holiday set of the holidays provider.
+	country string
+	// now is the clock quick-add phrases are resolved against.
+	now func() time.Time
 }
 
 // NewCalendarService constructs a CalendarService.
@@ -25,20 +46,123 @@ func NewCalendarService(repo storage.Storage, isMondayBased bool) *CalendarServi
 	return &CalendarService{
 		repo:            repo,
 		mondayBasedWeek: isMondayBased,
+		now:             time.Now,
+	}
+}
+
+// SetHolidays enables the holidays of country: they are overlaid on week and
+// month results and treated as non-working days by the business-day helpers.
+func (s *CalendarService) SetHolidays(p holidays.Provider, country string) {
+	s.holidays = p
+	s.country = country
+}
+
+// MigrateLegacyEvents moves events stored before multi-calendar support into
+// their owners' default calendars. It is a no-op for backends that do not
+// implement storage.Migrator. Returns the number of migrated events.
+func (s *CalendarService) MigrateLegacyEvents() (int, error) {
+	m, ok := s.repo.(storage.Migrator)
+	if !ok {
+		return 0, nil
 	}
+	return m.MigrateToDefaultCalendar()
 }
 
 // CreateEvent creates a new event for the specified user.
-// Delegates to repository SaveEvent.
+// Events without CalendarId go to the user's default calendar,
+// which is created on first use. Delegates to repository SaveEvent.
 func (s *CalendarService) CreateEvent(userId string, e models.Event) (models.Event, error) {
+	if err := s.resolveCalendar(userId, &e); err != nil {
+		return models.Event{}, err
+	}
 	e.Id = uuid.New().String()
 	return s.repo.SaveEvent(userId, e)
 }
 
-// UpdateEvent updates an existing event for the specified user.
+// QuickAddEvent parses a natural-language phrase such as
+// "Lunch with Ana tomorrow at 1pm for 1h" relative to the current time in
+// timeZone (IANA name, empty means UTC) and creates the resulting event.
+// With dryRun the event is only parsed, not stored, so clients can confirm
+// the interpretation first. Returns the event and a human-readable interpretation.
+// Errors caused by text or timeZone wrap ErrInvalidQuickAdd.
+func (s *CalendarService) QuickAddEvent(userId, calendarId, text, timeZone string, dryRun bool) (models.Event, string, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return models.Event{}, "", fmt.Errorf("%w: unknown time zone %q", ErrInvalidQuickAdd, timeZone)
+	}
+
+	res, err := quickadd.Parse(text, s.now().In(loc))
+	if err != nil {
+		return models.Event{}, "", fmt.Errorf("%w: %w", ErrInvalidQuickAdd, err)
+	}
+
+	e := models.NewEvent(userId, models.Date{Time: time.Date(res.Date.Year(), res.Date.Month(), res.Date.Day(), 0, 0, 0, 0, time.UTC)}, res.Name)
+	e.CalendarId = calendarId
+	e.Time = res.Time
+	e.DurationMinutes = res.DurationMinutes
+	e.TimeZone = loc.String()
+	e.Recurrence = res.Recurrence
+
+	if dryRun {
+		if calendarId == "" {
+			e.CalendarId = models.DefaultCalendarId
+		}
+		return *e, res.Describe(), nil
+	}
+
+	created, err := s.CreateEvent(userId, *e)
+	if err != nil {
+		return models.Event{}, "", err
+	}
+	return created, res.Describe(), nil
+}
+
+// UpdateEvent updates an existing event for the specified user, found by
+// e.Id on e.Date. Empty fields of e keep their stored values, so the time,
+// duration, time zone and recurrence of a quick-added event survive a rename.
+// If CalendarId is set, the event is moved to that calendar.
 // Delegates to repository UpdateEvent.
 func (s *CalendarService) UpdateEvent(userId string, e *models.Event) (*models.Event, error) {
-	return s.repo.UpdateEvent(userId, e)
+	current, err := s.getEvent(userId, e.Date, e.Id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Name != "" {
+		current.Name = e.Name
+	}
+	if e.CalendarId != "" {
+		current.CalendarId = e.CalendarId
+		if err := s.resolveCalendar(userId, &current); err != nil {
+			return nil, err
+		}
+	}
+	if e.Time != "" {
+		current.Time = e.Time
+	}
+	if e.DurationMinutes != 0 {
+		current.DurationMinutes = e.DurationMinutes
+	}
+	if e.TimeZone != "" {
+		current.TimeZone = e.TimeZone
+	}
+	if e.Recurrence != nil {
+		current.Recurrence = e.Recurrence
+	}
+	return s.repo.UpdateEvent(userId, &current)
+}
+
+// getEvent fetches the event eventId stored on date.
+func (s *CalendarService) getEvent(userId string, date models.Date, eventId string) (models.Event, error) {
+	events, err := s.repo.GetEvents(userId, nil, date.Time, date.Time)
+	if err != nil {
+		return models.Event{}, err
+	}
+	for _, e := range events {
+		if e.Id == eventId {
+			return e, nil
+		}
+	}
+	return models.Event{}, storage.NewEventNotFoundError(eventId)
 }
 
 // DeleteEvent removes an event by ID on the given date for the specified user.
@@ -49,33 +173,228 @@ func (s *CalendarService) DeleteEvent(userId string, date models.Date, eventId s
 
 // GetEventsForDay retrieves all events for a user on the specified date.
 // It queries the storage for events in the [date; date] range.
-func (s *CalendarService) GetEventsForDay(userId string, date models.Date) ([]models.Event, error) {
+// calendarIds restricts the result to the given calendars; empty means all.
+func (s *CalendarService) GetEventsForDay(userId string, date models.Date, calendarIds []string) ([]models.Event, error) {
 	// Use time.Time values for range boundaries
-	return s.repo.GetEvents(userId, date.Time, date.Time)
+	return s.getEvents(userId, calendarIds, date.Time, date.Time)
 }
 
 // GetEventsForWeek retrieves all events for a user in the week of the given date.
 // The week start is determined by mondayBasedWeek setting.
-func (s *CalendarService) GetEventsForWeek(userId string, date models.Date) ([]models.Event, error) {
-	weekday := int(date.Weekday())
-
-	if s.mondayBasedWeek {
-		if weekday == 0 {
-			weekday = 7
-		}
-		weekday--
-	}
-
-	start := date.AddDate(0, 0, -weekday)
+// Holidays are overlaid as read-only events, see SetHolidays.
+// calendarIds restricts the result to the given calendars; empty means all.
+func (s *CalendarService) GetEventsForWeek(userId string, date models.Date, calendarIds []string) ([]models.Event, error) {
+	start := s.weekStart(date.Time)
 	end := start.AddDate(0, 0, 6)
-	return s.repo.GetEvents(userId, start, end)
+	events, err := s.getEvents(userId, calendarIds, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return s.withHolidays(events, calendarIds, start, end), nil
 }
 
 // GetEventsForMonth retrieves all events for a user in the month of the given date.
 // It computes the first and last instants of the month.
-func (s *CalendarService) GetEventsForMonth(userId string, date models.Date) ([]models.Event, error) {
+// Holidays are overlaid as read-only events, see SetHolidays.
+// calendarIds restricts the result to the given calendars; empty means all.
+func (s *CalendarService) GetEventsForMonth(userId string, date models.Date, calendarIds []string) ([]models.Event, error) {
 	year, mon := date.Year(), date.Month()
 	start := time.Date(year, mon, 1, 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
-	return s.repo.GetEvents(userId, start, end)
+	events, err := s.getEvents(userId, calendarIds, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return s.withHolidays(events, calendarIds, start, end), nil
+}
+
+// getEvents returns the events of [from; to] from the storage. A user
+// without events, like a range without events, gets an empty list.
+func (s *CalendarService) getEvents(userId string, calendarIds []string, from, to time.Time) ([]models.Event, error) {
+	events, err := s.repo.GetEvents(userId, calendarIds, from, to)
+	if err != nil && !storage.IsUserHasNoEvents(err) {
+		return nil, err
+	}
+	if events == nil {
+		events = []models.Event{}
+	}
+	return events, nil
+}
+
+// CreateCalendar creates a new named calendar for the specified user.
+// Empty color and visibility fall back to models.DefaultCalendarColor and private.
+func (s *CalendarService) CreateCalendar(userId string, c models.Calendar) (models.Calendar, error) {
+	c.Id = uuid.New().String()
+	c.UserId = userId
+	if c.Color == "" {
+		c.Color = models.DefaultCalendarColor
+	}
+	if c.Visibility == "" {
+		c.Visibility = models.VisibilityPrivate
+	}
+	return s.repo.SaveCalendar(userId, c)
+}
+
+// UpdateCalendar changes name, color or visibility of an existing calendar.
+// Empty fields of c keep their stored values.
+func (s *CalendarService) UpdateCalendar(userId string, c *models.Calendar) (*models.Calendar, error) {
+	current, err := s.getCalendar(userId, c.Id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Name != "" {
+		current.Name = c.Name
+	}
+	if c.Color != "" {
+		current.Color = c.Color
+	}
+	if c.Visibility != "" {
+		current.Visibility = c.Visibility
+	}
+	return s.repo.UpdateCalendar(userId, &current)
+}
+
+// DeleteCalendar removes a calendar with all of its events.
+// The default calendar cannot be deleted.
+func (s *CalendarService) DeleteCalendar(userId string, calendarId string) error {
+	if calendarId == models.DefaultCalendarId {
+		return ErrDefaultCalendar
+	}
+	return s.repo.DeleteCalendar(userId, calendarId)
+}
+
+// GetCalendars returns all calendars of the user, the default one included.
+func (s *CalendarService) GetCalendars(userId string) ([]models.Calendar, error) {
+	if _, err := s.getCalendar(userId, models.DefaultCalendarId); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCalendars(userId)
+}
+
+// resolveCalendar points e at the default calendar when it has none and
+// checks that the target calendar exists.
+func (s *CalendarService) resolveCalendar(userId string, e *models.Event) error {
+	if e.CalendarId == "" {
+		e.CalendarId = models.DefaultCalendarId
+	}
+	_, err := s.getCalendar(userId, e.CalendarId)
+	return err
+}
+
+// getCalendar fetches a calendar, lazily creating the default one.
+func (s *CalendarService) getCalendar(userId string, calendarId string) (models.Calendar, error) {
+	c, err := s.repo.GetCalendar(userId, calendarId)
+	if err == nil || calendarId != models.DefaultCalendarId || !storage.IsCalendarNotFound(err) {
+		return c, err
+	}
+	created, err := s.repo.SaveCalendar(userId, *models.NewDefaultCalendar(userId))
+	if err != nil {
+		// a concurrent request may have created it first
+		return s.repo.GetCalendar(userId, calendarId)
+	}
+	return created, nil
+}
+
+// GetWeekView returns a one-row grid for the week of the given date.
+// calendarIds restricts the events to the given calendars; empty means all.
+func (s *CalendarService) GetWeekView(userId string, date models.Date, calendarIds []string) (models.Grid, error) {
+	start := s.weekStart(date.Time)
+	end := start.AddDate(0, 0, 6)
+	title := "Week " + models.Date{Time: start}.String() + " - " + models.Date{Time: end}.String()
+	return s.buildGrid(userId, calendarIds, title, start, end)
+}
+
+// GetMonthView returns a grid of full weeks covering the month of the given date.
+// Days of adjacent months that complete the first and last rows have InPeriod == false.
+// calendarIds restricts the events to the given calendars; empty means all.
+func (s *CalendarService) GetMonthView(userId string, date models.Date, calendarIds []string) (models.Grid, error) {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	return s.buildGrid(userId, calendarIds, first.Format("January 2006"), first, last)
+}
+
+// buildGrid lays out the full weeks covering [from; to] with the user's
+// events and holidays of the period.
+func (s *CalendarService) buildGrid(userId string, calendarIds []string, title string, from, to time.Time) (models.Grid, error) {
+	events, err := s.getEvents(userId, calendarIds, from, to)
+	if err != nil {
+		return models.Grid{}, err
+	}
+	events = s.withHolidays(events, calendarIds, from, to)
+	return grid.Build(title, from, to, s.mondayBasedWeek, events), nil
+}
+
+// weekStart returns midnight of the first day of the week containing t.
+// The week start is determined by mondayBasedWeek setting.
+func (s *CalendarService) weekStart(t time.Time) time.Time {
+	return grid.WeekStart(t, s.mondayBasedWeek)
+}
+
+// IsWorkingDay reports whether date is neither a Saturday, a Sunday nor a holiday.
+func (s *CalendarService) IsWorkingDay(date models.Date) bool {
+	switch date.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	return s.holidays == nil || !s.holidays.IsHoliday(s.country, date)
+}
+
+// NextWorkingDay returns date itself if it is a working day, or the first working day after it.
+func (s *CalendarService) NextWorkingDay(date models.Date) models.Date {
+	for !s.IsWorkingDay(date) {
+		date = models.Date{Time: date.AddDate(0, 0, 1)}
+	}
+	return date
+}
+
+// AddWorkingDays returns the date n working days after date, or before it if n is negative.
+// date itself is never counted, so AddWorkingDays(friday, 1) is the next working Monday.
+func (s *CalendarService) AddWorkingDays(date models.Date, n int) models.Date {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		date = models.Date{Time: date.AddDate(0, 0, step)}
+		if s.IsWorkingDay(date) {
+			n--
+		}
+	}
+	return date
+}
+
+// WorkingDaysBetween counts working days in [from; to]; it is 0 if to is before from.
+func (s *CalendarService) WorkingDaysBetween(from, to models.Date) int {
+	count := 0
+	for d := from; !d.After(to.Time); d = (models.Date{Time: d.AddDate(0, 0, 1)}) {
+		if s.IsWorkingDay(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// withHolidays overlays the holidays of [from; to] on the events of that
+// range. Holidays are skipped when calendarIds is set and does not include
+// models.HolidaysCalendarId. The result is ordered by date.
+func (s *CalendarService) withHolidays(events []models.Event, calendarIds []string, from, to time.Time) []models.Event {
+	if s.holidays == nil || (len(calendarIds) > 0 && !slices.Contains(calendarIds, models.HolidaysCalendarId)) {
+		return events
+	}
+
+	hs := s.holidays.Holidays(s.country, from, to)
+	if len(hs) == 0 {
+		return events
+	}
+	for _, h := range hs {
+		events = append(events, models.Event{
+			Id:         "holiday-" + h.Date.String(),
+			CalendarId: models.HolidaysCalendarId,
+			Date:       h.Date,
+			Name:       h.Name,
+			Holiday:    true,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date.Time) })
+	return events
 }
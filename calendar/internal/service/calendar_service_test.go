@@ -1,165 +1,446 @@
 package service_test
 
 import (
-    models2 "http_calendar/internal/lib/models"
-    "testing"
-    "time"
+	"errors"
+	"http_calendar/internal/holidays"
+	models2 "http_calendar/internal/lib/models"
+	"testing"
+	"time"
 
-    "http_calendar/internal/service"
-    "http_calendar/internal/storage"
+	"http_calendar/internal/service"
+	"http_calendar/internal/storage"
 )
 
 func parseDate(s string) models2.Date {
-    t, _ := time.Parse("2006-01-02", s)
-    return models2.Date{Time: t}
+	t, _ := time.Parse("2006-01-02", s)
+	return models2.Date{Time: t}
 }
 
-func makeEvent(id, userId int, dateStr, desc string) models2.Event {
-    return models2.Event{
-        Id:     id,
-        UserId: userId,
-        Date:   parseDate(dateStr),
-        Name:   desc,
-    }
+func makeEvent(id, userId string, dateStr, desc string) models2.Event {
+	return models2.Event{
+		Id:         id,
+		UserId:     userId,
+		CalendarId: models2.DefaultCalendarId,
+		Date:       parseDate(dateStr),
+		Name:       desc,
+	}
 }
 
 func TestCreateAndRetrieve(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, true)
-    userId := 1
-    e := makeEvent(100, userId, "2025-07-30", "Test Create")
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "1"
+	e := makeEvent("100", userId, "2025-07-30", "Test Create")
+	e.CalendarId = ""
 
-    if err := svc.CreateEvent(userId, e); err != nil {
-        t.Fatalf("CreateEvent failed: %v", err)
-    }
+	created, err := svc.CreateEvent(userId, e)
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if created.CalendarId != models2.DefaultCalendarId {
+		t.Fatalf("Expected event in default calendar, got %q", created.CalendarId)
+	}
 
-    evs, err := svc.GetEventsForDay(userId, e.Date)
-    if err != nil {
-        t.Fatalf("GetEventsForDay failed: %v", err)
-    }
-    if len(evs) != 1 || evs[0].Id != e.Id {
-        t.Fatalf("Expected 1 event id=100, got %v", evs)
-    }
+	evs, err := svc.GetEventsForDay(userId, e.Date, nil)
+	if err != nil {
+		t.Fatalf("GetEventsForDay failed: %v", err)
+	}
+	if len(evs) != 1 || evs[0].Id != created.Id {
+		t.Fatalf("Expected 1 event id=%s, got %v", created.Id, evs)
+	}
 }
 
 func TestUpdateNonExisting(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, true)
-    userId := 2
-    e := makeEvent(200, userId, "2025-08-01", "Original")
-    _ = mem.SaveEvent(userId, e)
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "2"
+	e := makeEvent("200", userId, "2025-08-01", "Original")
+	_, _ = mem.SaveEvent(userId, e)
 
-    e.Name = "Updated"
-    if err := svc.UpdateEvent(userId, e); err != nil {
-        t.Fatalf("UpdateEvent failed: %v", err)
-    }
+	e.Name = "Updated"
+	if _, err := svc.UpdateEvent(userId, &e); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
 
-    evs, _ := svc.GetEventsForDay(userId, e.Date)
-    if evs[0].Name != "Updated" {
-        t.Fatalf("Update did not persist, got description=%q", evs[0].Name)
-    }
+	evs, _ := svc.GetEventsForDay(userId, e.Date, nil)
+	if evs[0].Name != "Updated" {
+		t.Fatalf("Update did not persist, got description=%q", evs[0].Name)
+	}
 
-    e2 := makeEvent(999, userId, "2025-08-01", "Nope")
-    if err := svc.UpdateEvent(userId, e2); err == nil {
-        t.Fatalf("Expected error updating non-existent event, got nil")
-    }
+	e2 := makeEvent("999", userId, "2025-08-01", "Nope")
+	if _, err := svc.UpdateEvent(userId, &e2); err == nil {
+		t.Fatalf("Expected error updating non-existent event, got nil")
+	}
 }
 
 func TestDeleteNonExisting(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, true)
-    userId := 3
-    e1 := makeEvent(300, userId, "2025-08-05", "One")
-    e2 := makeEvent(301, userId, "2025-08-05", "Two")
-    _ = mem.SaveEvent(userId, e1)
-    _ = mem.SaveEvent(userId, e2)
-
-    if err := svc.DeleteEvent(userId, e1.Date, e1.Id); err != nil {
-        t.Fatalf("DeleteEvent failed: %v", err)
-    }
-    evs, _ := svc.GetEventsForDay(userId, e1.Date)
-    if len(evs) != 1 || evs[0].Id != e2.Id {
-        t.Fatalf("DeleteEvent did not remove correct event, got %v", evs)
-    }
-
-    if err := svc.DeleteEvent(userId, e1.Date, 9999); err == nil {
-        t.Fatalf("Expected error deleting non-existent event, got nil")
-    }
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "3"
+	e1 := makeEvent("300", userId, "2025-08-05", "One")
+	e2 := makeEvent("301", userId, "2025-08-05", "Two")
+	_, _ = mem.SaveEvent(userId, e1)
+	_, _ = mem.SaveEvent(userId, e2)
+
+	if err := svc.DeleteEvent(userId, e1.Date, e1.Id); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+	evs, _ := svc.GetEventsForDay(userId, e1.Date, nil)
+	if len(evs) != 1 || evs[0].Id != e2.Id {
+		t.Fatalf("DeleteEvent did not remove correct event, got %v", evs)
+	}
+
+	if err := svc.DeleteEvent(userId, e1.Date, "9999"); err == nil {
+		t.Fatalf("Expected error deleting non-existent event, got nil")
+	}
 }
 
 func TestGetEventsForDayExplicit(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, true)
-    userId := 6
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "6"
 
-    e1 := makeEvent(600, userId, "2025-09-01", "DayEvent")
-    _ = mem.SaveEvent(userId, e1)
+	e1 := makeEvent("600", userId, "2025-09-01", "DayEvent")
+	_, _ = mem.SaveEvent(userId, e1)
 
-    evs, err := svc.GetEventsForDay(userId, parseDate("2025-09-01"))
-    if err != nil {
-        t.Fatalf("GetEventsForDay failed: %v", err)
-    }
-    if len(evs) != 1 || evs[0].Id != e1.Id {
-        t.Fatalf("Expected one event on day, got %v", evs)
-    }
+	evs, err := svc.GetEventsForDay(userId, parseDate("2025-09-01"), nil)
+	if err != nil {
+		t.Fatalf("GetEventsForDay failed: %v", err)
+	}
+	if len(evs) != 1 || evs[0].Id != e1.Id {
+		t.Fatalf("Expected one event on day, got %v", evs)
+	}
 
-    evs, err = svc.GetEventsForDay(userId, parseDate("2025-09-02"))
-    if err == nil {
-        t.Fatalf("Expected error for empty day, got events %v", evs)
-    }
+	evs, err = svc.GetEventsForDay(userId, parseDate("2025-09-02"), nil)
+	if err != nil || len(evs) != 0 {
+		t.Fatalf("Expected no events for empty day, got events %v, err %v", evs, err)
+	}
+}
+
+func TestGetEventsForUserWithoutEvents(t *testing.T) {
+	svc := service.NewCalendarService(storage.NewInMemoryStorage(), true)
+
+	for name, get := range map[string]func(string, models2.Date, []string) ([]models2.Event, error){
+		"day":   svc.GetEventsForDay,
+		"week":  svc.GetEventsForWeek,
+		"month": svc.GetEventsForMonth,
+	} {
+		evs, err := get("nobody", parseDate("2025-09-01"), nil)
+		if err != nil || evs == nil || len(evs) != 0 {
+			t.Fatalf("%s: expected an empty list, got %#v, err %v", name, evs, err)
+		}
+	}
 }
 
 func TestGetEventsForWeek(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, true)
-    userId := 4
-    monday := makeEvent(400, userId, "2025-07-28", "Mon event")
-    sunday := makeEvent(401, userId, "2025-08-03", "Sun event")
-    _ = mem.SaveEvent(userId, monday)
-    _ = mem.SaveEvent(userId, sunday)
-
-    evs, err := svc.GetEventsForWeek(userId, parseDate("2025-07-30"))
-    if err != nil {
-        t.Fatalf("GetEventsForWeek failed: %v", err)
-    }
-    if len(evs) != 2 {
-        t.Fatalf("Expected 2 events in week, got %d", len(evs))
-    }
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "4"
+	monday := makeEvent("400", userId, "2025-07-28", "Mon event")
+	sunday := makeEvent("401", userId, "2025-08-03", "Sun event")
+	_, _ = mem.SaveEvent(userId, monday)
+	_, _ = mem.SaveEvent(userId, sunday)
+
+	evs, err := svc.GetEventsForWeek(userId, parseDate("2025-07-30"), nil)
+	if err != nil {
+		t.Fatalf("GetEventsForWeek failed: %v", err)
+	}
+	if len(evs) != 2 {
+		t.Fatalf("Expected 2 events in week, got %d", len(evs))
+	}
 }
 
 func TestGetEventsForMonth(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, true)
-    userId := 5
-    eJuly := makeEvent(500, userId, "2025-07-15", "July")
-    eAug := makeEvent(501, userId, "2025-08-01", "Aug")
-    _ = mem.SaveEvent(userId, eJuly)
-    _ = mem.SaveEvent(userId, eAug)
-
-    evs, err := svc.GetEventsForMonth(userId, parseDate("2025-07-10"))
-    if err != nil {
-        t.Fatalf("GetEventsForMonth failed: %v", err)
-    }
-    if len(evs) != 1 || evs[0].Id != eJuly.Id {
-        t.Fatalf("Expected only July event, got %v", evs)
-    }
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "5"
+	eJuly := makeEvent("500", userId, "2025-07-15", "July")
+	eAug := makeEvent("501", userId, "2025-08-01", "Aug")
+	_, _ = mem.SaveEvent(userId, eJuly)
+	_, _ = mem.SaveEvent(userId, eAug)
+
+	evs, err := svc.GetEventsForMonth(userId, parseDate("2025-07-10"), nil)
+	if err != nil {
+		t.Fatalf("GetEventsForMonth failed: %v", err)
+	}
+	if len(evs) != 1 || evs[0].Id != eJuly.Id {
+		t.Fatalf("Expected only July event, got %v", evs)
+	}
 }
 
 func TestGetEventsForWeekSundayStart(t *testing.T) {
-    mem := storage.NewInMemoryStorage()
-    svc := service.NewCalendarService(mem, false)
-    userId := 7
-
-    eSun := makeEvent(700, userId, "2025-07-27", "SunEvent")
-    eSat := makeEvent(701, userId, "2025-08-02", "SatEvent")
-    _ = mem.SaveEvent(userId, eSun)
-    _ = mem.SaveEvent(userId, eSat)
-
-    evs, err := svc.GetEventsForWeek(userId, parseDate("2025-07-29"))
-    if err != nil {
-        t.Fatalf("GetEventsForWeek (Sunday start) failed: %v", err)
-    }
-    if len(evs) != 2 {
-        t.Fatalf("Expected 2 events in sunday-based week, got %d", len(evs))
-    }
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, false)
+	userId := "7"
+
+	eSun := makeEvent("700", userId, "2025-07-27", "SunEvent")
+	eSat := makeEvent("701", userId, "2025-08-02", "SatEvent")
+	_, _ = mem.SaveEvent(userId, eSun)
+	_, _ = mem.SaveEvent(userId, eSat)
+
+	evs, err := svc.GetEventsForWeek(userId, parseDate("2025-07-29"), nil)
+	if err != nil {
+		t.Fatalf("GetEventsForWeek (Sunday start) failed: %v", err)
+	}
+	if len(evs) != 2 {
+		t.Fatalf("Expected 2 events in sunday-based week, got %d", len(evs))
+	}
+}
+
+func TestCalendarsFilterEvents(t *testing.T) {
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "8"
+
+	work, err := svc.CreateCalendar(userId, *models2.NewCalendar(userId, "work", "", ""))
+	if err != nil {
+		t.Fatalf("CreateCalendar failed: %v", err)
+	}
+	if work.Color != models2.DefaultCalendarColor || work.Visibility != models2.VisibilityPrivate {
+		t.Fatalf("Expected default color and visibility, got %+v", work)
+	}
+
+	e := makeEvent("", userId, "2025-07-30", "Standup")
+	e.CalendarId = work.Id
+	if _, err := svc.CreateEvent(userId, e); err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if _, err := svc.CreateEvent(userId, makeEvent("", userId, "2025-07-30", "Gym")); err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+
+	evs, _ := svc.GetEventsForDay(userId, parseDate("2025-07-30"), []string{work.Id})
+	if len(evs) != 1 || evs[0].Name != "Standup" {
+		t.Fatalf("Expected only work event, got %v", evs)
+	}
+	evs, _ = svc.GetEventsForDay(userId, parseDate("2025-07-30"), nil)
+	if len(evs) != 2 {
+		t.Fatalf("Expected events of all calendars, got %v", evs)
+	}
+
+	cals, _ := svc.GetCalendars(userId)
+	if len(cals) != 2 {
+		t.Fatalf("Expected work and default calendars, got %v", cals)
+	}
+
+	e.CalendarId = "missing"
+	if _, err := svc.CreateEvent(userId, e); err == nil {
+		t.Fatalf("Expected error creating event in unknown calendar, got nil")
+	}
+}
+
+func TestUpdateAndDeleteCalendar(t *testing.T) {
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "9"
+
+	oncall, _ := svc.CreateCalendar(userId, *models2.NewCalendar(userId, "on-call", "#ff0000", models2.VisibilityBusy))
+	updated, err := svc.UpdateCalendar(userId, &models2.Calendar{Id: oncall.Id, Visibility: models2.VisibilityPublic})
+	if err != nil {
+		t.Fatalf("UpdateCalendar failed: %v", err)
+	}
+	if updated.Name != "on-call" || updated.Color != "#ff0000" || updated.Visibility != models2.VisibilityPublic {
+		t.Fatalf("Expected only visibility to change, got %+v", updated)
+	}
+
+	e := makeEvent("", userId, "2025-07-30", "Pager")
+	e.CalendarId = oncall.Id
+	_, _ = svc.CreateEvent(userId, e)
+
+	if err := svc.DeleteCalendar(userId, oncall.Id); err != nil {
+		t.Fatalf("DeleteCalendar failed: %v", err)
+	}
+	if evs, err := svc.GetEventsForDay(userId, parseDate("2025-07-30"), nil); err != nil || len(evs) != 0 {
+		t.Fatalf("Expected events of deleted calendar to be gone, got %v, err %v", evs, err)
+	}
+	if err := svc.DeleteCalendar(userId, models2.DefaultCalendarId); err == nil {
+		t.Fatalf("Expected error deleting default calendar, got nil")
+	}
+}
+
+func TestMigrateLegacyEvents(t *testing.T) {
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "10"
+
+	legacy := makeEvent("1000", userId, "2025-07-30", "Legacy")
+	legacy.CalendarId = ""
+	_, _ = mem.SaveEvent(userId, legacy)
+
+	n, err := svc.MigrateLegacyEvents()
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 migrated event, got %d, err %v", n, err)
+	}
+	evs, _ := svc.GetEventsForDay(userId, legacy.Date, []string{models2.DefaultCalendarId})
+	if len(evs) != 1 {
+		t.Fatalf("Expected legacy event in default calendar, got %v", evs)
+	}
+	if _, err := mem.GetCalendar(userId, models2.DefaultCalendarId); err != nil {
+		t.Fatalf("Expected default calendar to be created: %v", err)
+	}
+}
+
+func TestGetMonthView(t *testing.T) {
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "11"
+	_, _ = mem.SaveEvent(userId, makeEvent("1100", userId, "2025-07-16", "Workshop"))
+	_, _ = mem.SaveEvent(userId, makeEvent("1101", userId, "2025-08-01", "Next month"))
+
+	g, err := svc.GetMonthView(userId, parseDate("2025-07-10"), nil)
+	if err != nil {
+		t.Fatalf("GetMonthView failed: %v", err)
+	}
+	if len(g.Weeks) != 5 || g.Weekdays[0] != "Mo" {
+		t.Fatalf("Expected 5 monday-based weeks, got %d starting with %s", len(g.Weeks), g.Weekdays[0])
+	}
+
+	first, last := g.Weeks[0][0], g.Weeks[4][6]
+	if first.Date.String() != "2025-06-30" || first.InPeriod {
+		t.Fatalf("Expected padding day 2025-06-30, got %+v", first)
+	}
+	if last.Date.String() != "2025-08-03" || last.InPeriod {
+		t.Fatalf("Expected padding day 2025-08-03, got %+v", last)
+	}
+	if evs := g.Weeks[2][2].Events; len(evs) != 1 || evs[0].Id != "1100" {
+		t.Fatalf("Expected workshop on 2025-07-16, got %v", evs)
+	}
+	if evs := g.Weeks[4][4].Events; len(evs) != 0 {
+		t.Fatalf("Expected no events on padding day, got %v", evs)
+	}
+
+	if _, err := svc.GetWeekView("unknown", parseDate("2025-07-10"), nil); err != nil {
+		t.Fatalf("Expected empty view for user without events, got %v", err)
+	}
+}
+
+func newHolidayService(t *testing.T) *service.CalendarService {
+	t.Helper()
+	set := holidays.NewSet()
+	set.Add(holidays.Holiday{Date: parseDate("2025-07-04"), Name: "Independence Day", Country: "US"})
+	svc := service.NewCalendarService(storage.NewInMemoryStorage(), true)
+	svc.SetHolidays(set, "US")
+	return svc
+}
+
+func TestHolidayOverlay(t *testing.T) {
+	svc := newHolidayService(t)
+	userId := "12"
+	_, _ = svc.CreateEvent(userId, makeEvent("", userId, "2025-07-03", "BBQ prep"))
+
+	evs, err := svc.GetEventsForWeek(userId, parseDate("2025-07-02"), nil)
+	if err != nil {
+		t.Fatalf("GetEventsForWeek failed: %v", err)
+	}
+	if len(evs) != 2 || !evs[1].Holiday || evs[1].CalendarId != models2.HolidaysCalendarId {
+		t.Fatalf("Expected event followed by holiday, got %+v", evs)
+	}
+
+	evs, _ = svc.GetEventsForMonth(userId, parseDate("2025-07-02"), []string{models2.DefaultCalendarId})
+	if len(evs) != 1 || evs[0].Holiday {
+		t.Fatalf("Expected holidays to be filtered out, got %+v", evs)
+	}
+
+	evs, err = svc.GetEventsForMonth("no-events", parseDate("2025-07-02"), nil)
+	if err != nil || len(evs) != 1 {
+		t.Fatalf("Expected holiday for user without events, got %+v, err %v", evs, err)
+	}
+	evs, err = svc.GetEventsForMonth("no-events", parseDate("2025-08-02"), nil)
+	if err != nil || evs == nil || len(evs) != 0 {
+		t.Fatalf("Expected an empty list for user without events or holidays, got %#v, err %v", evs, err)
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	svc := newHolidayService(t)
+
+	if svc.IsWorkingDay(parseDate("2025-07-04")) || svc.IsWorkingDay(parseDate("2025-07-05")) {
+		t.Fatalf("Expected holiday and Saturday to be non-working")
+	}
+	if !svc.IsWorkingDay(parseDate("2025-07-03")) {
+		t.Fatalf("Expected Thursday to be a working day")
+	}
+
+	tests := []struct {
+		from string
+		n    int
+		want string
+	}{
+		{"2025-07-03", 1, "2025-07-07"},  // skips the holiday and the weekend
+		{"2025-07-03", 3, "2025-07-09"},  // Mon, Tue, Wed
+		{"2025-07-07", -1, "2025-07-03"}, // backwards over the weekend and the holiday
+		{"2025-07-05", 0, "2025-07-05"},
+	}
+	for _, tt := range tests {
+		if got := svc.AddWorkingDays(parseDate(tt.from), tt.n).String(); got != tt.want {
+			t.Errorf("AddWorkingDays(%s, %d) = %s, want %s", tt.from, tt.n, got, tt.want)
+		}
+	}
+
+	if got := svc.NextWorkingDay(parseDate("2025-07-04")).String(); got != "2025-07-07" {
+		t.Errorf("NextWorkingDay = %s, want 2025-07-07", got)
+	}
+	if got := svc.WorkingDaysBetween(parseDate("2025-07-01"), parseDate("2025-07-31")); got != 22 {
+		t.Errorf("WorkingDaysBetween = %d, want 22", got)
+	}
+}
+
+func TestQuickAddEvent(t *testing.T) {
+	mem := storage.NewInMemoryStorage()
+	svc := service.NewCalendarService(mem, true)
+	userId := "13"
+
+	e, interpretation, err := svc.QuickAddEvent(userId, "", "Review 2025-07-16 at 3pm for 45 min", "UTC", true)
+	if err != nil {
+		t.Fatalf("QuickAddEvent failed: %v", err)
+	}
+	if e.Name != "Review" || e.Date.String() != "2025-07-16" || e.Time != "15:00" || e.DurationMinutes != 45 {
+		t.Fatalf("Unexpected event %+v", e)
+	}
+	if interpretation == "" {
+		t.Fatalf("Expected an interpretation")
+	}
+	if evs, _ := svc.GetEventsForDay(userId, e.Date, nil); len(evs) != 0 {
+		t.Fatalf("Expected dry run not to store the event, got %v", evs)
+	}
+
+	created, _, err := svc.QuickAddEvent(userId, "", "Review 2025-07-16 at 3pm", "UTC", false)
+	if err != nil || created.Id == "" {
+		t.Fatalf("Expected event to be created, got %+v, err %v", created, err)
+	}
+
+	if _, _, err := svc.QuickAddEvent(userId, "", "Review", "Mars/Olympus", true); !errors.Is(err, service.ErrInvalidQuickAdd) {
+		t.Fatalf("Expected ErrInvalidQuickAdd for unknown time zone, got %v", err)
+	}
+	if _, _, err := svc.QuickAddEvent(userId, "", "at 3pm", "UTC", true); !errors.Is(err, service.ErrInvalidQuickAdd) {
+		t.Fatalf("Expected ErrInvalidQuickAdd for text without a title, got %v", err)
+	}
+}
+
+func TestUpdateQuickAddedEventKeepsDetails(t *testing.T) {
+	svc := service.NewCalendarService(storage.NewInMemoryStorage(), true)
+	userId := "14"
+
+	created, _, err := svc.QuickAddEvent(userId, "", "retro every other Friday 2025-07-18 at 4pm for 1h", "Europe/Berlin", false)
+	if err != nil {
+		t.Fatalf("QuickAddEvent failed: %v", err)
+	}
+
+	// the update handler sends only the id, date, name and calendar
+	rename := models2.NewEvent(userId, created.Date, "sprint retro")
+	rename.Id = created.Id
+	if _, err := svc.UpdateEvent(userId, rename); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+
+	evs, err := svc.GetEventsForDay(userId, created.Date, nil)
+	if err != nil || len(evs) != 1 {
+		t.Fatalf("Expected the renamed event, got %v, err %v", evs, err)
+	}
+	got := evs[0]
+	if got.Name != "sprint retro" || got.CalendarId != created.CalendarId || got.Time != "16:00" ||
+		got.DurationMinutes != 60 || got.TimeZone != "Europe/Berlin" ||
+		got.Recurrence == nil || got.Recurrence.Interval != 2 || len(got.Recurrence.Weekdays) != 1 {
+		t.Fatalf("Expected only the name to change, got %+v (recurrence %+v)", got, got.Recurrence)
+	}
 }
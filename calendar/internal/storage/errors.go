@@ -1,34 +1,56 @@
 package storage
 
 import (
-    "errors"
-    "fmt"
-    "http_calendar/internal/lib/models"
+	"errors"
+	"fmt"
+	"http_calendar/internal/lib/models"
 )
 
 var (
-    errEventExists   = errors.New("event already exists")
-    errEventNotFound = errors.New("event not found")
-    errNoEvents      = errors.New("user has no events")
+	errEventExists      = errors.New("event already exists")
+	errEventNotFound    = errors.New("event not found")
+	errNoEvents         = errors.New("user has no events")
+	errCalendarExists   = errors.New("calendar already exists")
+	errCalendarNotFound = errors.New("calendar not found")
 )
 
 // NewEventExistsError returns an error indicating that the event already exists
 func NewEventExistsError(id string) error {
-    return fmt.Errorf("%w: %d", errEventExists, id)
+	return fmt.Errorf("%w: %s", errEventExists, id)
 }
 
 // NewEventNotFoundError returns an error indicating that the specified event was not found
 func NewEventNotFoundError(id string) error {
-    return fmt.Errorf("%w: %d", errEventNotFound, id)
+	return fmt.Errorf("%w: %s", errEventNotFound, id)
 }
 
 // NewEventNotFoundByDateError returns an error indicating that no event was found
 // for the given date and user ID.
 func NewEventNotFoundByDateError(date models.Date, userId string) error {
-    return fmt.Errorf("%w: %+v, user: %d", errEventNotFound, date, userId)
+	return fmt.Errorf("%w: %s, user: %s", errEventNotFound, date, userId)
 }
 
 // NewUserHasNoEventsError returns an error indicating that no user was found for given ID
 func NewUserHasNoEventsError(userId string) error {
-    return fmt.Errorf("%w: %d", errNoEvents, userId)
+	return fmt.Errorf("%w: %s", errNoEvents, userId)
+}
+
+// NewCalendarExistsError returns an error indicating that the calendar already exists
+func NewCalendarExistsError(id string) error {
+	return fmt.Errorf("%w: %s", errCalendarExists, id)
+}
+
+// NewCalendarNotFoundError returns an error indicating that the specified calendar was not found
+func NewCalendarNotFoundError(id string) error {
+	return fmt.Errorf("%w: %s", errCalendarNotFound, id)
+}
+
+// IsCalendarNotFound reports whether err was produced by NewCalendarNotFoundError.
+func IsCalendarNotFound(err error) bool {
+	return errors.Is(err, errCalendarNotFound)
+}
+
+// IsUserHasNoEvents reports whether err was produced by NewUserHasNoEventsError.
+func IsUserHasNoEvents(err error) bool {
+	return errors.Is(err, errNoEvents)
 }
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"http_calendar/internal/lib/models"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 )
@@ -9,15 +11,18 @@ import (
 // InMemoryStorage provides a thread-safe, in-memory implementation of the Storage interface.
 // It stores events in a nested map structure: userId → date string → slice of Event.
 // Date strings use the format YYYY-MM-DD (models.Date.String()).
+// Calendars are kept separately: userId → calendarId → Calendar.
 type InMemoryStorage struct {
-	mu      sync.RWMutex                         // protects records for concurrent access
-	records map[string]map[string][]models.Event // records[userId][dateKey] = []Event
+	mu        sync.RWMutex                         // protects records and calendars for concurrent access
+	records   map[string]map[string][]models.Event // records[userId][dateKey] = []Event
+	calendars map[string]map[string]models.Calendar
 }
 
 // NewInMemoryStorage initializes and returns a new InMemoryStorage instance.
 func NewInMemoryStorage() *InMemoryStorage {
 	return &InMemoryStorage{
-		records: make(map[string]map[string][]models.Event),
+		records:   make(map[string]map[string][]models.Event),
+		calendars: make(map[string]map[string]models.Calendar),
 	}
 }
 
@@ -35,6 +40,11 @@ func (c *InMemoryStorage) SaveEvent(userId string, event models.Event) (models.E
 	}
 
 	eventsOnDate := c.records[userId][dateKey]
+	for _, e := range eventsOnDate {
+		if e.Id == event.Id {
+			return models.Event{}, NewEventExistsError(event.Id)
+		}
+	}
 	c.records[userId][dateKey] = append(eventsOnDate, event)
 	return event, nil
 }
@@ -59,9 +69,12 @@ func (c *InMemoryStorage) UpdateEvent(userId string, event *models.Event) (*mode
 
 	for i, e := range eventsOnDate {
 		if e.Id == event.Id {
+			if event.CalendarId == "" {
+				event.CalendarId = e.CalendarId
+			}
 			eventsOnDate[i] = *event
 			c.records[userId][dateKey] = eventsOnDate
-			return &e, nil
+			return event, nil
 		}
 	}
 	return nil, NewEventNotFoundError(event.Id)
@@ -111,7 +124,8 @@ func (c *InMemoryStorage) DeleteEvent(userId string, date models.Date, eventId s
 
 // GetEvents returns all events for userId between from and to inclusive.
 // Iterates day-by-day, concatenating events for each date key found.
-func (c *InMemoryStorage) GetEvents(userId string, from, to time.Time) ([]models.Event, error) {
+// If calendarIds is not empty, events of other calendars are skipped.
+func (c *InMemoryStorage) GetEvents(userId string, calendarIds []string, from, to time.Time) ([]models.Event, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -126,9 +140,136 @@ func (c *InMemoryStorage) GetEvents(userId string, from, to time.Time) ([]models
 
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
 		key := models.Date{Time: d}.String()
-		if dayEvents, ok := userDates[key]; ok {
-			result = append(result, dayEvents...)
+		for _, e := range userDates[key] {
+			if len(calendarIds) == 0 || slices.Contains(calendarIds, e.CalendarId) {
+				result = append(result, e)
+			}
 		}
 	}
 	return result, nil
 }
+
+// SaveCalendar adds a new calendar for the given userId.
+// Returns an error if a calendar with the same ID already exists.
+func (c *InMemoryStorage) SaveCalendar(userId string, calendar models.Calendar) (models.Calendar, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.saveCalendar(userId, calendar)
+}
+
+// saveCalendar is SaveCalendar without locking; the caller must hold c.mu.
+func (c *InMemoryStorage) saveCalendar(userId string, calendar models.Calendar) (models.Calendar, error) {
+	if _, exists := c.calendars[userId]; !exists {
+		c.calendars[userId] = make(map[string]models.Calendar)
+	}
+	if _, exists := c.calendars[userId][calendar.Id]; exists {
+		return models.Calendar{}, NewCalendarExistsError(calendar.Id)
+	}
+	c.calendars[userId][calendar.Id] = calendar
+	return calendar, nil
+}
+
+// UpdateCalendar replaces the calendar identified by calendar.Id for the given userId.
+// Returns an error if the calendar is not found.
+func (c *InMemoryStorage) UpdateCalendar(userId string, calendar *models.Calendar) (*models.Calendar, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.calendars[userId][calendar.Id]; !exists {
+		return nil, NewCalendarNotFoundError(calendar.Id)
+	}
+	c.calendars[userId][calendar.Id] = *calendar
+	return calendar, nil
+}
+
+// DeleteCalendar removes the calendar with calendarId for userId along with all of its events.
+// Date keys and users left without events are removed, as in DeleteEvent.
+func (c *InMemoryStorage) DeleteCalendar(userId string, calendarId string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	userCalendars, exists := c.calendars[userId]
+	if !exists {
+		return NewCalendarNotFoundError(calendarId)
+	}
+	if _, exists := userCalendars[calendarId]; !exists {
+		return NewCalendarNotFoundError(calendarId)
+	}
+	delete(userCalendars, calendarId)
+	if len(userCalendars) == 0 {
+		delete(c.calendars, userId)
+	}
+
+	userDates := c.records[userId]
+	for dateKey, eventsOnDate := range userDates {
+		kept := slices.DeleteFunc(eventsOnDate, func(e models.Event) bool {
+			return e.CalendarId == calendarId
+		})
+		if len(kept) == 0 {
+			delete(userDates, dateKey)
+		} else {
+			userDates[dateKey] = kept
+		}
+	}
+	if userDates != nil && len(userDates) == 0 {
+		delete(c.records, userId)
+	}
+	return nil
+}
+
+// GetCalendar returns the calendar with calendarId for userId.
+func (c *InMemoryStorage) GetCalendar(userId string, calendarId string) (models.Calendar, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	calendar, exists := c.calendars[userId][calendarId]
+	if !exists {
+		return models.Calendar{}, NewCalendarNotFoundError(calendarId)
+	}
+	return calendar, nil
+}
+
+// GetCalendars returns all calendars of userId sorted by Id.
+// A user without calendars gets an empty slice, not an error.
+func (c *InMemoryStorage) GetCalendars(userId string) ([]models.Calendar, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]models.Calendar, 0, len(c.calendars[userId]))
+	for _, calendar := range c.calendars[userId] {
+		result = append(result, calendar)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result, nil
+}
+
+// MigrateToDefaultCalendar assigns every event with an empty CalendarId to
+// models.DefaultCalendarId and creates the default calendar for users who lack it.
+func (c *InMemoryStorage) MigrateToDefaultCalendar() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	migrated := 0
+	for userId, userDates := range c.records {
+		userMigrated := 0
+		for _, eventsOnDate := range userDates {
+			for i := range eventsOnDate {
+				if eventsOnDate[i].CalendarId == "" {
+					eventsOnDate[i].CalendarId = models.DefaultCalendarId
+					userMigrated++
+				}
+			}
+		}
+		if userMigrated == 0 {
+			continue
+		}
+		if _, exists := c.calendars[userId][models.DefaultCalendarId]; !exists {
+			if _, err := c.saveCalendar(userId, *models.NewDefaultCalendar(userId)); err != nil {
+				return migrated, err
+			}
+		}
+		migrated += userMigrated
+	}
+	return migrated, nil
+}
@@ -8,6 +8,12 @@ import (
 // Storage is the low‑level data store interface for calendar events.
 // It knows nothing about HTTP or business rules — only how to persist and retrieve events.
 type Storage interface {
+	EventStorage
+	CalendarStorage
+}
+
+// EventStorage persists events of users' calendars.
+type EventStorage interface {
 	// SaveEvent stores a new event for userId.
 	// Returns an error if an event with the same Id already exists.
 	SaveEvent(userId string, e models.Event) (models.Event, error)
@@ -23,5 +29,37 @@ type Storage interface {
 	// GetEvents returns all events for userId between from and to inclusive.
 	// The `from` and `to` parameters are time.Time values; events whose Date fall within
 	// that range (date-only precision) will be returned.
-	GetEvents(userId string, from, to time.Time) ([]models.Event, error)
+	// If calendarIds is not empty, only events of those calendars are returned.
+	GetEvents(userId string, calendarIds []string, from, to time.Time) ([]models.Event, error)
+}
+
+// CalendarStorage persists the named calendars of users.
+type CalendarStorage interface {
+	// SaveCalendar stores a new calendar for userId.
+	// Returns an error if a calendar with the same Id already exists.
+	SaveCalendar(userId string, c models.Calendar) (models.Calendar, error)
+
+	// UpdateCalendar replaces an existing calendar of userId.
+	// Returns an error if the calendar does not exist.
+	UpdateCalendar(userId string, c *models.Calendar) (*models.Calendar, error)
+
+	// DeleteCalendar deletes the calendar with calendarId together with all its events.
+	// Returns an error if the calendar does not exist.
+	DeleteCalendar(userId string, calendarId string) error
+
+	// GetCalendar returns the calendar with calendarId of userId.
+	// Returns an error if the calendar does not exist.
+	GetCalendar(userId string, calendarId string) (models.Calendar, error)
+
+	// GetCalendars returns all calendars of userId ordered by Id.
+	GetCalendars(userId string) ([]models.Calendar, error)
+}
+
+// Migrator is implemented by backends that may hold events stored before
+// multi-calendar support, i.e. events with an empty CalendarId.
+type Migrator interface {
+	// MigrateToDefaultCalendar moves every event without a calendar into the
+	// owner's default calendar (models.DefaultCalendarId), creating that calendar
+	// where it is missing. Returns the number of migrated events.
+	MigrateToDefaultCalendar() (int, error)
 }
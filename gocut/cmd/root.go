@@ -6,6 +6,7 @@ import (
 	"cut/internal/parser"
 	"fmt"
 	"io"
+	"lineio"
 	"log"
 	"os"
 
@@ -16,6 +17,7 @@ var (
 	fieldSpec string
 	delimiter string
 	separated bool
+	nullData  bool
 )
 
 func openInputSource(args []string) (io.Reader, error) {
@@ -45,17 +47,28 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
-		scanner := bufio.NewScanner(source)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if out := extractor.Extract(line); out != "" {
-				fmt.Println(out)
-			}
+		delim := lineio.Newline
+		if nullData {
+			delim = lineio.NUL
 		}
-		if err := scanner.Err(); err != nil {
-			return fmt.Errorf("error reading: %w", err)
+		reader := lineio.NewReader(source, delim)
+		out := bufio.NewWriter(cmd.OutOrStdout())
+		for {
+			rec, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("error reading: %w", err)
+			}
+			if extracted := extractor.Extract(rec.Text); extracted != "" {
+				rec.Text = extracted
+				if _, err := out.WriteString(rec.Terminated(delim)); err != nil {
+					return err
+				}
+			}
 		}
-		return nil
+		return out.Flush()
 	},
 }
 
@@ -68,6 +81,7 @@ func init() {
 
 	rootCmd.Flags().StringVarP(&delimiter, "delimiter", "d", "\t", "field delimiter (default: tab)")
 	rootCmd.Flags().BoolVarP(&separated, "separated", "s", false, "only show lines that include delimiter")
+	rootCmd.Flags().BoolVarP(&nullData, "null-data", "z", false, "lines are terminated by NUL bytes instead of newlines")
 }
 
 func Execute() {
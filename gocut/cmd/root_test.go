@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCutLineTerminators(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	tests := []struct {
+		name string
+		args []string
+		in   string
+		want string
+	}{
+		{"long_line", []string{"-f", "2"}, "a\t" + long + "\tc\n", long + "\n"},
+		{"crlf", []string{"-f", "1"}, "a\tb\r\nc\td\n", "a\r\nc\n"},
+		{"no_final_newline", []string{"-f", "2", "-d", ":"}, "a:b\nc:d", "b\nd\n"},
+		{"null_data", []string{"-z", "-f", "2", "-d", ":"}, "a:b\nc\x00d:e", "b\nc\x00e\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "input")
+			if err := os.WriteFile(path, []byte(tt.in), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// flags keep their values between executions
+			delimiter, separated, nullData = "\t", false, false
+
+			var out bytes.Buffer
+			rootCmd.SetOut(&out)
+			rootCmd.SetArgs(append(tt.args, path))
+			if err := rootCmd.Execute(); err != nil {
+				t.Fatalf("command failed: %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %.40q, want %.40q", got, tt.want)
+			}
+		})
+	}
+}
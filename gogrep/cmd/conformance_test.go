@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record the golden files of the conformance tests with GNU grep")
+
+const conformanceDir = "testdata/conformance"
+
+// conformanceCase is a gogrep invocation whose output must match GNU grep's.
+type conformanceCase struct {
+	name string
+	args []string
+}
+
+// TestConformance runs gogrep with the arguments listed in cases.txt on the
+// files in inputs/ and compares its output and exit status with the ones
+// recorded from GNU grep in golden/.
+func TestConformance(t *testing.T) {
+	cases, err := readConformanceCases(filepath.Join(conformanceDir, "cases.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prog := "grep"
+	if !*update {
+		prog = buildGogrep(t)
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := runConformanceCase(prog, c.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join(conformanceDir, "golden", c.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("gogrep %s:\ngot:\n%s\nwant:\n%s", strings.Join(c.args, " "), got, want)
+			}
+		})
+	}
+}
+
+// buildGogrep builds the gogrep binary into a temporary directory.
+func buildGogrep(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "gogrep")
+	out, err := exec.Command("go", "build", "-o", bin, "grep/cmd/gogrep").CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build gogrep: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runConformanceCase runs prog in the inputs directory and returns its
+// standard output followed by the exit status.
+func runConformanceCase(prog string, args []string) ([]byte, error) {
+	cmd := exec.Command(prog, args...)
+	cmd.Dir = filepath.Join(conformanceDir, "inputs")
+	out, err := cmd.Output()
+
+	status := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		status = exitErr.ExitCode()
+	} else if err != nil {
+		return nil, err
+	}
+	return append(out, fmt.Sprintf("[exit %d]\n", status)...), nil
+}
+
+// readConformanceCases parses the "name: arguments" lines of path,
+// skipping blank lines and # comments.
+func readConformanceCases(path string) ([]conformanceCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []conformanceCase
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, args, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s: malformed case %q", path, line)
+		}
+		split, err := splitArgs(args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: case %s: %w", path, name, err)
+		}
+		cases = append(cases, conformanceCase{name: name, args: split})
+	}
+	return cases, scanner.Err()
+}
+
+// splitArgs splits s on spaces; single quotes group words, as in the shell.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for _, r := range s {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			inArg = true
+		case r == ' ' && !quoted:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+			}
+			inArg = false
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
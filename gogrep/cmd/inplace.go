@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"grep/internal/grepper"
+)
+
+// rewriteFile edits the file path in place (--in-place): its matches are
+// replaced in a temporary file in the same directory, which is then renamed
+// over the file, so that readers see either the old or the new contents.
+// With a backup suffix, the original is kept as path+suffix. Files without
+// selected lines are left alone.
+func rewriteFile(ctx context.Context, g *grepper.Grepper, path string) (stats grepper.Stats, err error) {
+	// edit the target of a symbolic link rather than replace the link
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return grepper.Stats{}, err
+	}
+	f, err := os.Open(target)
+	if err != nil {
+		return grepper.Stats{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return grepper.Stats{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*")
+	if err != nil {
+		return grepper.Stats{}, err
+	}
+	defer func() {
+		if tmp != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	stats, err = g.Rewrite(ctx, f, tmp)
+	if err != nil || stats.MatchedLines == 0 {
+		return stats, err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return stats, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return stats, err
+	}
+	if err := tmp.Close(); err != nil {
+		return stats, err
+	}
+	if backupSuffix != "" {
+		if err := backup(target, target+backupSuffix); err != nil {
+			return stats, err
+		}
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return stats, err
+	}
+	tmp = nil
+	return stats, nil
+}
+
+// backup keeps the file path as backup, replacing an older backup: as a hard
+// link if possible, otherwise as a copy.
+func backup(path, backup string) error {
+	if err := os.Remove(backup); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if os.Link(path, backup) == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
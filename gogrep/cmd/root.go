@@ -1,94 +1,410 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"grep/internal/archive"
+	"grep/internal/follow"
 	"grep/internal/grepper"
+	"grep/internal/walker"
+	"lineio"
 )
 
-var cfg grepper.Config
+var (
+	cfg     grepper.Config
+	walkCfg walker.Options
+
+	withFilename bool // -H
+	noFilename   bool // -h
+	text         bool // -a
+	skipBinary   bool // -I
+	binaryFiles  string
+	encoding     string
+	jobs         int  // -j
+	searchZip    bool // -z
+	followFile   bool // --follow
+	color        string
+
+	groupSeparator string
+	replacement    string // --replace
+	editInPlace    bool   // --in-place
+	backupSuffix   string // --in-place=SUFFIX
+
+	maxCount   int  // -m
+	noMessages bool // -s
+
+	basicRegexp    bool // -G
+	extendedRegexp bool // -E
+	perlRegexp     bool // -P
+
+	patterns     []string // -e
+	patternFiles []string // -f
+)
+
+// Exit statuses, as in grep.
+const (
+	exitSelected    = 0   // some line was selected
+	exitNotSelected = 1   // no line was selected
+	exitError       = 2   // an error occurred, unless -q is used and some line was selected
+	exitInterrupted = 130 // the search was cancelled by a signal
+)
+
+// result is the outcome of the last run, used for the exit status.
+var result outcome
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// It exits with grep's exit status.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// cancel outstanding searches on the first signal, terminate on the next one
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(rootCmd.ErrOrStderr(), "gogrep:", err)
+	}
+	os.Exit(exitStatus(result, err))
+}
+
+// exitStatus maps the outcome of a run to grep's exit status.
+func exitStatus(res outcome, err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case res.selected && cfg.Quiet:
+		return exitSelected
+	case err != nil || res.failed:
+		return exitError
+	case res.selected:
+		return exitSelected
+	default:
+		return exitNotSelected
 	}
 }
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "gogrep [flags] pattern [file]",
+	Use:   "gogrep [flags] pattern [file...]\n  gogrep [flags] -e pattern... [-f file]... [file...]\n  gogrep [flags] --jq-field predicate... [-e pattern]... [file...]\n  gogrep [flags] --query query [-e pattern]... [file...]",
 	Short: "a minimal unix grep-like text filter for files or stdin",
 	Long: `A minimal unix grep-like tool for filtering text streams.
 
-It reads input from a file or stdin and prints lines that match a given pattern
+It reads input from files or stdin and prints lines that match a given pattern
 (substring or regular expression). Behavior is intentionally close to UNIX grep.
 
-If [file] is omitted or set to "-" the input is read from stdin.
+If no file is given, or a file is "-", the input is read from stdin; with -r
+the working directory is searched instead. When several files are searched,
+each output line is prefixed with the file name.
     
 Examples:
   echo -e "alpha\nBeta\nGAMMA" | gogrep -A 1 "alpha"
   gogrep -c "pattern" file.txt
+  gogrep -rn --include '*.go' "TODO" .
 `,
 
-	Args: cobra.RangeArgs(1, 2),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(patterns) == 0 && len(patternFiles) == 0 && len(cfg.FieldQuery) == 0 && cfg.Query == "" {
+			return cobra.MinimumNArgs(1)(cmd, args)
+		}
+		return nil
+	},
 
 	RunE: runGrep,
+
+	// errors are reported by Execute, in grep's format
+	SilenceErrors: true,
 }
 
 func runGrep(cmd *cobra.Command, args []string) error {
-	pattern := args[0]
+	// errors from here on are not about the command-line syntax
+	cmd.SilenceUsage = true
 
+	pats, paths, err := collectPatterns(args)
+	if err != nil {
+		return err
+	}
+
+	if err := applyFlags(cmd, paths); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	if jobs < 0 {
+		return errors.New("invalid configuration: the number of jobs must be non-negative")
+	}
+	if err := walkCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
 
-	source, err := openInputSource(args)
+	g, err := grepper.New(pats, cfg)
 	if err != nil {
-		return err
+		return fmt.Errorf("grep failed: %w", err)
 	}
 
-	_, err = grepper.GrepLines(source, cmd.OutOrStdout(), pattern, cfg)
+	if maxCount == 0 {
+		return nil // like grep, don't even open the files
+	}
+
+	stderr := cmd.ErrOrStderr()
+	if noMessages {
+		stderr = io.Discard
+	}
+	start := time.Now()
+	result, err = searchAll(cmd.Context(), g, paths, cmd.OutOrStdout(), stderr)
+	if err == nil && cfg.JSON && !cfg.Quiet {
+		result.summary.Elapsed = time.Since(start)
+		err = grepper.WriteJSONSummary(cmd.OutOrStdout(), result.summary)
+	}
+	return err
+}
+
+// collectPatterns returns the patterns given with -e and -f, or the first
+// argument if there are none, and the remaining arguments. As in grep, each
+// line of a pattern is a separate pattern. With --jq-field or --query,
+// patterns are only given with -e and -f, and all the arguments are files.
+func collectPatterns(args []string) ([]string, []string, error) {
+	if len(patterns) == 0 && len(patternFiles) == 0 {
+		if len(cfg.FieldQuery) > 0 || cfg.Query != "" {
+			return nil, args, nil
+		}
+		return strings.Split(args[0], "\n"), args[1:], nil
+	}
+
+	var pats []string
+	for _, p := range patterns {
+		pats = append(pats, strings.Split(p, "\n")...)
+	}
+	for _, path := range patternFiles {
+		filePats, err := readPatternFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		pats = append(pats, filePats...)
+	}
+	return pats, args, nil
+}
+
+// readPatternFile reads one pattern per line from path ("-" is stdin).
+// An empty file contains no patterns and so matches nothing.
+func readPatternFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != walker.Stdin {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read patterns: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	lines, err := lineio.ReadAll(r, lineio.Newline)
 	if err != nil {
-		return fmt.Errorf("grep failed: %w", err)
+		return nil, fmt.Errorf("failed to read patterns from %s: %w", path, err)
+	}
+	pats := make([]string, len(lines))
+	for i, l := range lines {
+		pats[i] = l.Text
+	}
+	return pats, nil
+}
+
+// applyFlags derives the configuration from flags that don't map one-to-one
+// onto grepper.Config.
+func applyFlags(cmd *cobra.Command, paths []string) error {
+	cfg.MaxCount = max(maxCount, 0)
+
+	// explicit -A/-B override -C whatever their order, even when zero
+	if cmd.Flags().Changed("context") {
+		if !cmd.Flags().Changed("after") {
+			cfg.After = cfg.Context
+		}
+		if !cmd.Flags().Changed("before") {
+			cfg.Before = cfg.Context
+		}
+		cfg.Context = 0
+	}
+	if cmd.Flags().Changed("group-separator") {
+		cfg.GroupSeparator = &groupSeparator
+	}
+	cfg.Replace = nil
+	if cmd.Flags().Changed("replace") {
+		cfg.Replace = &replacement
+	}
+
+	fuzzy := cmd.Flags().Changed("fuzzy")
+	matchers := 0
+	for _, set := range []bool{cfg.Fixed, fuzzy, basicRegexp, extendedRegexp, perlRegexp} {
+		if set {
+			matchers++
+		}
+	}
+	switch {
+	case matchers > 1:
+		return errors.New("conflicting matchers specified")
+	case fuzzy && cfg.Fuzzy == 0:
+		cfg.Fixed = true // no edits: exact fixed strings
+	case basicRegexp:
+		cfg.Syntax = grepper.SyntaxBasic
+	case extendedRegexp:
+		cfg.Syntax = grepper.SyntaxExtended
+	case perlRegexp:
+		cfg.Syntax = grepper.SyntaxPerl
+	}
+
+	switch {
+	case text:
+		cfg.Binary = grepper.BinaryText
+	case skipBinary:
+		cfg.Binary = grepper.BinaryWithoutMatch
+	default:
+		cfg.Binary = grepper.BinaryMode(binaryFiles)
+	}
+
+	if encoding != "auto" {
+		cfg.Encoding = grepper.Encoding(strings.ToLower(encoding))
+	}
+
+	if followFile {
+		switch {
+		case len(paths) != 1 || paths[0] == walker.Stdin || walkCfg.IsRecursive():
+			return errors.New("--follow needs a single file")
+		case searchZip:
+			return errors.New("--follow and -z are mutually exclusive")
+		}
+	}
+
+	editInPlace = cmd.Flags().Changed("in-place")
+	if editInPlace {
+		switch {
+		case cfg.Replace == nil:
+			return errors.New("--in-place needs --replace")
+		case len(paths) == 0 && !walkCfg.IsRecursive() || slices.Contains(paths, walker.Stdin):
+			return errors.New("--in-place can't edit standard input")
+		case cfg.Invert:
+			return errors.New("--in-place and -v are mutually exclusive")
+		case searchZip:
+			return errors.New("--in-place and -z are mutually exclusive")
+		case followFile:
+			return errors.New("--in-place and --follow are mutually exclusive")
+		case cfg.Encoding != grepper.EncodingAuto && cfg.Encoding != grepper.EncodingUTF8:
+			return errors.New("--in-place only edits UTF-8 files")
+		}
+	}
+
+	switch {
+	case withFilename && noFilename:
+		return errors.New("-H and -h are mutually exclusive")
+	case withFilename, noFilename:
+		cfg.WithFilename = withFilename
+	default:
+		cfg.WithFilename = len(paths) > 1 || walkCfg.IsRecursive() && !isSingleFile(paths)
+	}
+
+	useColor, err := colorEnabled(color)
+	if err != nil {
+		return err
+	}
+	if useColor {
+		colors := grepper.ParseColors(os.Getenv("GREP_COLORS"))
+		cfg.Colors = &colors
 	}
 	return nil
 }
 
-// openInputSource returns an io.Reader for the input source (file or stdin).
-func openInputSource(args []string) (io.Reader, error) {
-	if len(args) == 2 && args[1] != "-" {
-		f, err := os.Open(args[1])
+// colorEnabled resolves --color=auto|always|never; auto highlights only
+// when writing to a terminal.
+func colorEnabled(when string) (bool, error) {
+	switch when {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb", nil
+	default:
+		return false, fmt.Errorf("invalid --color value %q: must be auto, always or never", when)
+	}
+}
+
+// isSingleFile reports whether paths is a single operand that isn't a directory.
+func isSingleFile(paths []string) bool {
+	if len(paths) != 1 {
+		return false
+	}
+	info, err := os.Stat(paths[0])
+	return paths[0] == walker.Stdin || err == nil && !info.IsDir()
+}
+
+// openInputSource opens the input (file or stdin) and returns it
+// along with the name to show in the output. With -z, gzip, bzip2 and
+// zstd input is decompressed; with --follow, the file is read as it grows
+// until ctx is done.
+func openInputSource(ctx context.Context, path string) (io.ReadCloser, string, error) {
+	if followFile {
+		r, err := follow.Open(ctx, path, 0)
+		return r, path, err
+	}
+
+	var f io.ReadCloser = io.NopCloser(os.Stdin)
+	name := grepper.StdinName
+	if path != walker.Stdin {
+		file, err := os.Open(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to open file %s: %w", args[1], err)
+			return nil, "", err
 		}
-		return &fileReader{f}, nil
+		f, name = file, path
+	}
+	if !searchZip {
+		return f, name, nil
 	}
-	return os.Stdin, nil
+
+	zr, _, err := archive.Decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, "", err
+	}
+	return decompressed{ReadCloser: zr, file: f}, name, nil
 }
 
-// fileReader wraps *os.File to close automatically after reading is done.
-// Implements io.Reader.
-type fileReader struct {
-	f *os.File
+// decompressed reads a decompressed file; closing it closes the decoder and the file.
+type decompressed struct {
+	io.ReadCloser
+	file io.Closer
 }
 
-func (fr *fileReader) Read(p []byte) (int, error) {
-	n, err := fr.f.Read(p)
-	if err == io.EOF {
-		closeErr := fr.f.Close()
-		if closeErr != nil {
-			_, err := fmt.Fprintf(os.Stderr, "failed to close file: %v\n", closeErr)
-			if err != nil {
-				return 0, err
-			}
-		}
+func (d decompressed) Close() error {
+	return errors.Join(d.ReadCloser.Close(), d.file.Close())
+}
+
+// reportError prints a per-file error in grep's "gogrep: path: reason" format.
+func reportError(w io.Writer, path string, err error) {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	msg := err.Error()
+	if errors.As(err, new(syscall.Errno)) && msg != "" {
+		// capitalized like strerror(3), as grep prints them
+		msg = strings.ToUpper(msg[:1]) + msg[1:]
 	}
-	return n, err
+	fmt.Fprintf(w, "gogrep: %s: %s\n", path, msg)
 }
 
 // init registers all command-line flags for the root command.
@@ -97,9 +413,70 @@ func init() {
 	rootCmd.Flags().IntVarP(&cfg.Before, "before", "B", 0, "print N lines of leading context before each match")
 	rootCmd.Flags().IntVarP(&cfg.Context, "context", "C", 0, "print N lines of context around each match (equivalent to -A N -B N)")
 
+	rootCmd.Flags().StringVar(&groupSeparator, "group-separator", "--", "print SEP between non-adjacent groups of context lines")
+	rootCmd.Flags().BoolVar(&cfg.NoGroupSeparator, "no-group-separator", false, "print nothing between groups of context lines")
+
 	rootCmd.Flags().BoolVarP(&cfg.CountOnly, "count", "c", false, "print only a count of matching lines")
 	rootCmd.Flags().BoolVarP(&cfg.IgnoreCase, "ignore-case", "i", false, "ignore case distinctions")
 	rootCmd.Flags().BoolVarP(&cfg.Invert, "invert-match", "v", false, "select non-matching lines")
 	rootCmd.Flags().BoolVarP(&cfg.Fixed, "fixed-strings", "F", false, "interpret pattern as a fixed substring (not a regular expression)")
+	rootCmd.Flags().IntVar(&cfg.Fuzzy, "fuzzy", 0, "match fixed strings approximately, within K insertions, deletions or substitutions of characters")
+	rootCmd.Flags().BoolVarP(&basicRegexp, "basic-regexp", "G", false, "interpret patterns as POSIX basic regular expressions")
+	rootCmd.Flags().BoolVarP(&extendedRegexp, "extended-regexp", "E", false, "interpret patterns as POSIX extended regular expressions")
+	rootCmd.Flags().BoolVarP(&perlRegexp, "perl-regexp", "P", false, "interpret patterns as Perl-compatible regular expressions (lookaround, backreferences)")
 	rootCmd.Flags().BoolVarP(&cfg.WithLineNo, "line-number", "n", false, "print line number with each output line")
+
+	rootCmd.Flags().BoolVarP(&cfg.Quiet, "quiet", "q", false, "print nothing, exit with status 0 at the first match")
+	rootCmd.Flags().BoolVar(&cfg.Quiet, "silent", false, "same as --quiet")
+	rootCmd.Flags().IntVarP(&maxCount, "max-count", "m", -1, "stop reading a file after NUM selected lines (negative: no limit)")
+	rootCmd.Flags().BoolVarP(&noMessages, "no-messages", "s", false, "suppress error messages about nonexistent or unreadable files")
+
+	rootCmd.Flags().StringArrayVarP(&patterns, "regexp", "e", nil, "use PATTERN for matching; can be repeated")
+	rootCmd.Flags().StringArrayVarP(&patternFiles, "file", "f", nil, "read patterns from FILE, one per line; can be repeated")
+	rootCmd.Flags().StringVar(&cfg.Query, "query", "", "select lines satisfying QUERY, strings and /regexps/ combined with AND, OR, NOT and parentheses, e.g. 'ERROR AND (db OR cache) AND NOT timeout'")
+	rootCmd.Flags().StringArrayVar(&cfg.FieldQuery, "jq-field", nil, "select JSON or logfmt lines whose fields satisfy PREDICATE (key=value, key!=value, key~regexp, key>=N, key, !key; alternatives joined by ||); can be repeated, all must hold")
+	rootCmd.Flags().StringSliceVar(&cfg.Project, "jq-project", nil, "print only the comma-separated fields of JSON and logfmt lines")
+	rootCmd.Flags().StringVar(&replacement, "replace", "", "print selected lines with their matches replaced by TEMPLATE, where $1 or ${name} is the text of a group")
+	rootCmd.Flags().StringVar(&backupSuffix, "in-place", "", "with --replace, edit the files instead of printing the lines, keeping the originals with SUFFIX appended (.bak if not given, none if empty)")
+	rootCmd.Flags().Lookup("in-place").NoOptDefVal = ".bak"
+	rootCmd.Flags().BoolVarP(&cfg.WordRegexp, "word-regexp", "w", false, "match only whole words")
+	rootCmd.Flags().BoolVarP(&cfg.LineRegexp, "line-regexp", "x", false, "match only whole lines")
+
+	rootCmd.Flags().BoolVarP(&cfg.OnlyMatching, "only-matching", "o", false, "print only the matched parts of matching lines, each on its own line")
+	rootCmd.Flags().BoolVarP(&cfg.ByteOffset, "byte-offset", "b", false, "print the byte offset of each output line (of each match with -o)")
+	rootCmd.Flags().BoolVar(&cfg.Column, "column", false, "print the column number of the first match")
+	rootCmd.Flags().BoolVar(&cfg.JSON, "json", false, "print results as JSON lines: begin, match, context and end records per file and a final summary")
+	rootCmd.Flags().StringVar(&color, "color", "never", "highlight matches: auto, always or never (GREP_COLORS sets the colors)")
+	rootCmd.Flags().Lookup("color").NoOptDefVal = "auto"
+
+	rootCmd.Flags().BoolVarP(&withFilename, "with-filename", "H", false, "print the file name for each match")
+	rootCmd.Flags().BoolVarP(&noFilename, "no-filename", "h", false, "suppress the file name prefix on output")
+	rootCmd.Flags().BoolVarP(&cfg.FilesWithMatches, "files-with-matches", "l", false, "print only names of files with matches")
+	rootCmd.Flags().BoolVarP(&cfg.FilesWithoutMatch, "files-without-match", "L", false, "print only names of files without matches")
+
+	rootCmd.Flags().BoolVarP(&walkCfg.Recursive, "recursive", "r", false, "search directories recursively, skipping symbolic links")
+	rootCmd.Flags().BoolVarP(&walkCfg.FollowSymlinks, "dereference-recursive", "R", false, "search directories recursively, following symbolic links")
+	rootCmd.Flags().StringArrayVar(&walkCfg.Include, "include", nil, "search only files whose base name matches GLOB")
+	rootCmd.Flags().StringArrayVar(&walkCfg.Exclude, "exclude", nil, "skip files whose base name matches GLOB")
+	rootCmd.Flags().StringArrayVar(&walkCfg.ExcludeDir, "exclude-dir", nil, "skip directories whose base name matches GLOB")
+	rootCmd.Flags().StringSliceVarP(&walkCfg.Types, "type", "t", nil, "search only files of TYPE: "+strings.Join(walker.TypeNames(), ", "))
+	rootCmd.Flags().BoolVar(&walkCfg.Hidden, "hidden", false, "also search hidden files and directories with -r")
+	rootCmd.Flags().BoolVar(&walkCfg.NoIgnore, "no-ignore", false, "don't skip .git and the files excluded by .gitignore and .ignore files with -r")
+
+	rootCmd.Flags().StringVar(&binaryFiles, "binary-files", string(grepper.BinaryDefault), "how to treat binary files: binary, text or without-match")
+	rootCmd.Flags().BoolVarP(&text, "text", "a", false, "process binary files as text (--binary-files=text)")
+	rootCmd.Flags().BoolVarP(&skipBinary, "skip-binary", "I", false, "assume binary files don't match (--binary-files=without-match)")
+
+	rootCmd.Flags().StringVar(&encoding, "encoding", "auto", "character encoding of the input: auto (UTF-8, or UTF-16 with a byte order mark), utf-8, utf-16le, utf-16be or latin1")
+
+	rootCmd.Flags().BoolVar(&cfg.NullData, "null-data", false, "input and output lines are terminated by NUL bytes instead of newlines")
+
+	rootCmd.Flags().BoolVarP(&searchZip, "search-zip", "z", false, "search in gzip, bzip2 and zstd compressed files and in the files of tar archives")
+
+	rootCmd.Flags().BoolVar(&followFile, "follow", false, "keep searching the file as it grows, like tail -F, also across truncation and rotation; stop with an interrupt")
+
+	rootCmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "search N files in parallel (default: number of CPUs)")
+
+	// -h is taken by --no-filename, as in grep
+	rootCmd.Flags().Bool("help", false, "help for gogrep")
 }
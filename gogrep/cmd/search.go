@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+
+	"grep/internal/archive"
+	"grep/internal/grepper"
+	"grep/internal/parallel"
+	"grep/internal/walker"
+)
+
+// errStopSearch stops the search early once its outcome is known (-q).
+var errStopSearch = errors.New("search stopped")
+
+// fileTask is a file to search, or the error met when walking to it.
+type fileTask struct {
+	path string
+	err  error
+}
+
+// fileResult is the buffered output of searching one file.
+type fileResult struct {
+	fileTask
+	out   bytes.Buffer
+	stats grepper.Stats
+}
+
+// outcome summarizes a search for the exit status and the --json summary.
+type outcome struct {
+	selected bool // some line was selected
+	failed   bool // some inputs couldn't be searched
+	summary  grepper.Summary
+}
+
+// add accounts for a searched file.
+func (o *outcome) add(stats grepper.Stats, err error) {
+	o.failed = o.failed || err != nil
+	o.selected = o.selected || stats.MatchedLines > 0
+
+	if err == nil {
+		o.summary.Searches++
+		if stats.MatchedLines > 0 {
+			o.summary.SearchesWithMatch++
+		}
+	}
+	o.summary.Add(stats)
+}
+
+// done reports whether searching further inputs can't change the result.
+func (o *outcome) done() bool {
+	return cfg.Quiet && o.selected
+}
+
+// searchAll searches all inputs denoted by paths and writes the results to
+// out in argument/walk order, and per-file errors to stderr. The returned
+// error is fatal; errors of single inputs are reflected in the outcome.
+//
+// Several files are searched on a pool of -j workers. A single input is
+// searched directly, so its output is streamed rather than buffered.
+func searchAll(ctx context.Context, g *grepper.Grepper, paths []string, out, stderr io.Writer) (outcome, error) {
+	var res outcome
+	sepOut := &separatedWriter{w: out, sep: g.GroupSeparator()}
+	account := func(path string, stats grepper.Stats, err error) error {
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		res.add(stats, err)
+		if err != nil {
+			reportError(stderr, path, err)
+		}
+		if res.done() {
+			return errStopSearch
+		}
+		return nil
+	}
+
+	n := jobs
+	if n == 0 {
+		n = runtime.NumCPU()
+	}
+	var err error
+	if n == 1 || isSingleInput(paths) {
+		err = walker.Walk(paths, walkCfg, func(path string, err error) error {
+			var stats grepper.Stats
+			if err == nil {
+				sepOut.next()
+				stats, err = searchFile(ctx, g, path, sepOut)
+			}
+			return account(path, stats, err)
+		})
+	} else {
+		err = parallel.Ordered(ctx, n,
+			func(ctx context.Context, submit parallel.SubmitFunc[fileTask]) error {
+				return walker.Walk(paths, walkCfg, func(path string, err error) error {
+					if !submit(fileTask{path: path, err: err}) {
+						return ctx.Err()
+					}
+					return nil
+				})
+			},
+			func(ctx context.Context, task fileTask) *fileResult {
+				r := &fileResult{fileTask: task}
+				if r.err == nil {
+					r.stats, r.err = searchFile(ctx, g, r.path, &r.out)
+				}
+				return r
+			},
+			func(r *fileResult) error {
+				sepOut.next()
+				if _, err := r.out.WriteTo(sepOut); err != nil {
+					return err
+				}
+				return account(r.path, r.stats, r.err)
+			},
+		)
+	}
+
+	if errors.Is(err, errStopSearch) {
+		err = nil
+	}
+	return res, err
+}
+
+// isSingleInput reports whether paths denote exactly one input to search.
+func isSingleInput(paths []string) bool {
+	return len(paths) == 0 && !walkCfg.IsRecursive() || isSingleFile(paths)
+}
+
+// searchFile greps a single input and writes the results to out.
+// With -z, the files of a tar archive are searched one by one; with
+// --in-place, the file is edited instead and nothing is written to out.
+func searchFile(ctx context.Context, g *grepper.Grepper, path string, out io.Writer) (grepper.Stats, error) {
+	if editInPlace {
+		return rewriteFile(ctx, g, path)
+	}
+	r, name, err := openInputSource(ctx, path)
+	if err != nil {
+		return grepper.Stats{}, err
+	}
+	defer r.Close()
+
+	if !searchZip {
+		return g.Search(ctx, r, name, out)
+	}
+	br := bufio.NewReader(r)
+	if !archive.IsTar(br) {
+		return g.Search(ctx, br, name, out)
+	}
+	return searchTar(ctx, g, br, name, out)
+}
+
+// searchTar searches each file of the tar archive r as an input named
+// "archive:member", decompressing compressed members. Unless -h is given,
+// the lines are prefixed with that name even if the archive is the only input.
+func searchTar(ctx context.Context, g *grepper.Grepper, r io.Reader, name string, out io.Writer) (grepper.Stats, error) {
+	if !noFilename {
+		g = g.WithFilename(true)
+	}
+	sepOut := &separatedWriter{w: out, sep: g.GroupSeparator()}
+
+	var total grepper.Stats
+	err := archive.Walk(r, func(member string, r io.Reader) error {
+		zr, _, err := archive.Decompress(r)
+		if err != nil {
+			return fmt.Errorf("%s: %w", member, err)
+		}
+		defer zr.Close()
+
+		sepOut.next()
+		stats, err := g.Search(ctx, zr, name+":"+member, sepOut)
+		total.Add(stats)
+		return err
+	})
+	return total, err
+}
+
+// separatedWriter writes the group separator before the output of an input
+// if an earlier input had output, so that with context the groups of
+// different files are separated like the groups within a file.
+type separatedWriter struct {
+	w       io.Writer
+	sep     string
+	wrote   bool // something was written
+	pending bool // the separator is due before the next write
+}
+
+// next marks the start of the output of the next input.
+func (s *separatedWriter) next() {
+	s.pending = s.wrote && s.sep != ""
+}
+
+func (s *separatedWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if s.pending {
+		s.pending = false
+		if _, err := io.WriteString(s.w, s.sep); err != nil {
+			return 0, err
+		}
+	}
+	s.wrote = true
+	return s.w.Write(p)
+}
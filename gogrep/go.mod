@@ -2,8 +2,17 @@ module grep
 
 go 1.24.2
 
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/spf13/cobra v1.9.1
+	golang.org/x/text v0.26.0
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.9.1 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 )
+
+require lineio v0.0.0
+
+replace lineio => ../lineio
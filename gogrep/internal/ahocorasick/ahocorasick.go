@@ -0,0 +1,185 @@
+// Package ahocorasick implements the Aho–Corasick automaton for finding
+// many fixed strings in a single pass over the input.
+package ahocorasick
+
+import "sort"
+
+// Matcher finds occurrences of a set of byte strings. It is safe for
+// concurrent use.
+type Matcher struct {
+	nodes     []node
+	root      [256]int32 // transitions out of the root, including the ones back to it
+	maxLen    int
+	foldASCII bool
+}
+
+type node struct {
+	edges []edge // sorted by label
+	fail  int32  // node of the longest proper suffix of this node's path that is in the trie
+	out   int32  // length of the longest pattern that is a suffix of this node's path, 0 if none
+	depth int32
+}
+
+type edge struct {
+	label byte
+	to    int32
+}
+
+// New builds a Matcher for patterns. With foldASCII, ASCII letters match
+// regardless of case. Empty patterns are ignored.
+func New(patterns []string, foldASCII bool) *Matcher {
+	m := &Matcher{nodes: []node{{}}, foldASCII: foldASCII}
+	for _, p := range patterns {
+		m.insert(p)
+	}
+	m.link()
+	return m
+}
+
+func (m *Matcher) insert(p string) {
+	if p == "" {
+		return
+	}
+	m.maxLen = max(m.maxLen, len(p))
+
+	state := int32(0)
+	for i := 0; i < len(p); i++ {
+		b := m.fold(p[i])
+		next, ok := m.nodes[state].child(b)
+		if !ok {
+			next = int32(len(m.nodes))
+			m.nodes = append(m.nodes, node{depth: m.nodes[state].depth + 1})
+			m.nodes[state].addEdge(b, next)
+		}
+		state = next
+	}
+	m.nodes[state].out = m.nodes[state].depth
+}
+
+// link computes failure links and outputs in breadth-first order.
+func (m *Matcher) link() {
+	for _, e := range m.nodes[0].edges {
+		m.root[e.label] = e.to
+	}
+
+	queue := make([]int32, 0, len(m.nodes))
+	for _, e := range m.nodes[0].edges {
+		queue = append(queue, e.to)
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, e := range m.nodes[u].edges {
+			v := &m.nodes[e.to]
+			if u != 0 {
+				v.fail = m.step(m.nodes[u].fail, e.label)
+			}
+			if v.out == 0 {
+				v.out = m.nodes[v.fail].out
+			}
+			queue = append(queue, e.to)
+		}
+	}
+}
+
+// step returns the state after reading b in state.
+func (m *Matcher) step(state int32, b byte) int32 {
+	for state != 0 {
+		if next, ok := m.nodes[state].child(b); ok {
+			return next
+		}
+		state = m.nodes[state].fail
+	}
+	return m.root[b]
+}
+
+func (m *Matcher) fold(b byte) byte {
+	if m.foldASCII && 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// Match reports whether s contains any of the patterns.
+func (m *Matcher) Match(s string) bool {
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		state = m.step(state, m.fold(s[i]))
+		if m.nodes[state].out > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexBytes returns the start of the occurrence of a pattern in b that
+// ends first, or -1 if there is none.
+func (m *Matcher) IndexBytes(b []byte) int {
+	state := int32(0)
+	for i := 0; i < len(b); i++ {
+		state = m.step(state, m.fold(b[i]))
+		if n := int(m.nodes[state].out); n > 0 {
+			return i + 1 - n
+		}
+	}
+	return -1
+}
+
+// FindAll returns the [start, end) byte offsets of the successive
+// non-overlapping occurrences of the patterns in s. Among occurrences
+// starting at the same position the longest one is chosen (leftmost-longest,
+// as POSIX grep does).
+func (m *Matcher) FindAll(s string) [][2]int {
+	var matches [][2]int
+	best := [2]int{-1, -1}
+	state := int32(0)
+	for i := 0; ; i++ {
+		if i < len(s) {
+			state = m.step(state, m.fold(s[i]))
+			if n := int(m.nodes[state].out); n > 0 {
+				// the longest pattern ending here starts leftmost
+				start := i + 1 - n
+				if best[0] < 0 || start < best[0] || start == best[0] && i+1 > best[1] {
+					best = [2]int{start, i + 1}
+				}
+			}
+		}
+
+		if best[0] < 0 {
+			if i >= len(s) {
+				return matches
+			}
+			continue
+		}
+		// once no pattern ending further on can start at or before best,
+		// take it and continue right after it
+		if i >= len(s) || i+1-best[0] >= m.maxLen {
+			matches = append(matches, best)
+			i, state = best[1]-1, 0
+			best = [2]int{-1, -1}
+		}
+	}
+}
+
+func (n *node) child(b byte) (int32, bool) {
+	if len(n.edges) <= 8 {
+		for _, e := range n.edges {
+			if e.label == b {
+				return e.to, true
+			}
+		}
+		return 0, false
+	}
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].label >= b })
+	if i < len(n.edges) && n.edges[i].label == b {
+		return n.edges[i].to, true
+	}
+	return 0, false
+}
+
+func (n *node) addEdge(b byte, to int32) {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].label >= b })
+	n.edges = append(n.edges, edge{})
+	copy(n.edges[i+1:], n.edges[i:])
+	n.edges[i] = edge{label: b, to: to}
+}
@@ -0,0 +1,116 @@
+package ahocorasick
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		fold     bool
+		s        string
+		want     [][2]int
+	}{
+		{[]string{"he", "she", "his", "hers"}, false, "ushers", [][2]int{{1, 4}}},
+		{[]string{"a", "ab", "abc"}, false, "xabcab", [][2]int{{1, 4}, {4, 6}}},
+		{[]string{"bcd", "abcde"}, false, "abcdx", [][2]int{{1, 4}}},
+		{[]string{"bc", "abcd"}, false, "abcd abc", [][2]int{{0, 4}, {6, 8}}},
+		{[]string{"ERROR", "warn"}, true, "error: WARN", [][2]int{{0, 5}, {7, 11}}},
+		{[]string{"aa"}, false, "aaaaa", [][2]int{{0, 2}, {2, 4}}},
+		{[]string{"x"}, false, "abc", nil},
+		{[]string{""}, false, "abc", nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.patterns, tt.s), func(t *testing.T) {
+			m := New(tt.patterns, tt.fold)
+			if got := m.FindAll(tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+			if got := m.Match(tt.s); got != (tt.want != nil) {
+				t.Errorf("Match(%q) = %v, want %v", tt.s, got, tt.want != nil)
+			}
+		})
+	}
+}
+
+func TestIndexBytes(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		fold     bool
+		s        string
+		want     int
+	}{
+		{[]string{"he", "she", "his", "hers"}, false, "ushers", 1},
+		{[]string{"bc", "abcd"}, false, "abcd", 1}, // bc ends first
+		{[]string{"ERROR", "warn"}, true, "an Error", 3},
+		{[]string{"x"}, false, "abc", -1},
+		{[]string{"x"}, false, "", -1},
+	}
+	for _, tt := range tests {
+		if got := New(tt.patterns, tt.fold).IndexBytes([]byte(tt.s)); got != tt.want {
+			t.Errorf("%q: IndexBytes(%q) = %d, want %d", tt.patterns, tt.s, got, tt.want)
+		}
+	}
+}
+
+// naiveFindAll is the reference leftmost-longest implementation.
+func naiveFindAll(patterns []string, s string) [][2]int {
+	var matches [][2]int
+	for i := 0; i < len(s); {
+		end := -1
+		for _, p := range patterns {
+			if p != "" && strings.HasPrefix(s[i:], p) {
+				end = max(end, i+len(p))
+			}
+		}
+		if end < 0 {
+			i++
+			continue
+		}
+		matches = append(matches, [2]int{i, end})
+		i = end
+	}
+	return matches
+}
+
+func TestFindAll_AgainstNaive(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	word := func(n int) string {
+		b := make([]byte, 1+rnd.IntN(n))
+		for i := range b {
+			b[i] = "abc"[rnd.IntN(3)]
+		}
+		return string(b)
+	}
+
+	for range 500 {
+		patterns := make([]string, 1+rnd.IntN(6))
+		for i := range patterns {
+			patterns[i] = word(4)
+		}
+		s := word(30)
+
+		got := New(patterns, false).FindAll(s)
+		if want := naiveFindAll(patterns, s); !reflect.DeepEqual(got, want) {
+			t.Fatalf("patterns %q in %q: got %v, want %v", patterns, s, got, want)
+		}
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	patterns := make([]string, 5000)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("user-%d-session", i*7919)
+	}
+	m := New(patterns, false)
+	line := strings.Repeat("2025-07-16T10:00:00Z level=INFO msg=request user=user-42 ", 4)
+
+	b.ResetTimer()
+	for range b.N {
+		m.Match(line)
+	}
+}
@@ -0,0 +1,106 @@
+// Package archive gives gogrep's -z access to the contents of compressed
+// files and tar archives. Formats are recognized by their magic bytes, not
+// by file name extensions.
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Format is a compression format.
+type Format string
+
+const (
+	None  Format = ""
+	Gzip  Format = "gzip"
+	Bzip2 Format = "bzip2"
+	Zstd  Format = "zstd"
+)
+
+var magics = []struct {
+	format Format
+	magic  []byte
+}{
+	{Gzip, []byte{0x1f, 0x8b}},
+	{Bzip2, []byte("BZh")},
+	{Zstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+// Detect returns the compression format of the data buffered in r,
+// peeking at its first bytes.
+func Detect(r *bufio.Reader) Format {
+	head, _ := r.Peek(4)
+	for _, m := range magics {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.format
+		}
+	}
+	return None
+}
+
+// Decompress returns a reader of the decompressed contents of r, or of r
+// itself if it isn't compressed. Closing it releases the decoder, not r.
+// Concatenated streams, as written by `cat a.gz b.gz`, are read as one.
+func Decompress(r io.Reader) (io.ReadCloser, Format, error) {
+	br := bufio.NewReader(r)
+	format := Detect(br)
+	switch format {
+	case Gzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, format, err
+		}
+		return zr, format, nil
+	case Bzip2:
+		return io.NopCloser(bzip2.NewReader(br)), format, nil
+	case Zstd:
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, format, err
+		}
+		return zr.IOReadCloser(), format, nil
+	default:
+		return io.NopCloser(br), format, nil
+	}
+}
+
+// tarMagicOffset is where the ustar header (POSIX and GNU) has its magic.
+const tarMagicOffset = 257
+
+// IsTar reports whether r starts with a tar header.
+func IsTar(r *bufio.Reader) bool {
+	head, _ := r.Peek(tarMagicOffset + 5)
+	return len(head) == tarMagicOffset+5 && string(head[tarMagicOffset:]) == "ustar"
+}
+
+// WalkFunc is called with the name and the contents of an archive member.
+type WalkFunc func(name string, r io.Reader) error
+
+// Walk calls fn for each regular file in the tar archive r, in archive
+// order. It stops at the first error, returned by fn or met when reading
+// the archive.
+func Walk(r io.Reader, fn WalkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// bzip2Data is "ERROR bz\n" compressed by bzip2(1); the standard library
+// has no bzip2 writer.
+var bzip2Data = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xe4, 0xaf,
+	0x42, 0x51, 0x00, 0x00, 0x01, 0x57, 0x80, 0x00, 0x10, 0x40, 0x00, 0x02,
+	0x00, 0x90, 0x00, 0x10, 0x00, 0x00, 0x10, 0x20, 0x00, 0x31, 0x0c, 0x01,
+	0x0d, 0x33, 0x49, 0x4e, 0x42, 0x0b, 0xe2, 0xee, 0x48, 0xa7, 0x0a, 0x12,
+	0x1c, 0x95, 0xe8, 0x4a, 0x20,
+}
+
+func gzipData(t *testing.T, s string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func zstdData(t *testing.T, s string) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll([]byte(s), nil)
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		format Format
+		want   string
+	}{
+		{"plain", []byte("ERROR plain\n"), None, "ERROR plain\n"},
+		{"empty", nil, None, ""},
+		{"gzip", gzipData(t, "ERROR gz\n"), Gzip, "ERROR gz\n"},
+		{"gzip_concatenated", append(gzipData(t, "one\n"), gzipData(t, "two\n")...), Gzip, "one\ntwo\n"},
+		{"bzip2", bzip2Data, Bzip2, "ERROR bz\n"},
+		{"zstd", zstdData(t, "ERROR zst\n"), Zstd, "ERROR zst\n"},
+		{"short_magic_prefix", []byte{0x1f}, None, "\x1f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, format, err := Decompress(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			if format != tt.format {
+				t.Errorf("got format %q, want %q", format, tt.format)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompress_Corrupt(t *testing.T) {
+	data := gzipData(t, "some text\n")
+	r, _, err := Decompress(bytes.NewReader(data[:len(data)-3]))
+	if err == nil {
+		_, err = io.ReadAll(r)
+	}
+	if err == nil {
+		t.Error("expected an error for truncated gzip data")
+	}
+}
+
+func tarData(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	if err := w.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(files); i += 2 {
+		name, body := files[i], files[i+1]
+		if err := w.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/a.log"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestIsTar(t *testing.T) {
+	if !IsTar(bufio.NewReader(bytes.NewReader(tarData(t)))) {
+		t.Error("a tar archive wasn't recognized")
+	}
+	for _, s := range []string{"", "short", strings.Repeat("x", 1024)} {
+		if IsTar(bufio.NewReader(strings.NewReader(s))) {
+			t.Errorf("%.10q... was taken for a tar archive", s)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	data := tarData(t, "dir/a.log", "ERROR a\n", "b.log", "", "c.log", "c\n")
+
+	var got []string
+	err := Walk(bytes.NewReader(data), func(name string, r io.Reader) error {
+		body, err := io.ReadAll(r)
+		got = append(got, name+"="+string(body))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"dir/a.log=ERROR a\n", "b.log=", "c.log=c\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got members %q, want %q", got, want)
+	}
+}
+
+func TestWalk_StopsAtError(t *testing.T) {
+	data := tarData(t, "a.log", "a\n", "b.log", "b\n")
+	stop := io.ErrShortWrite
+
+	var calls int
+	err := Walk(bytes.NewReader(data), func(string, io.Reader) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("got error %v after %d calls, want %v after 1", err, calls, stop)
+	}
+
+	// a member that is cut short is an error of the archive
+	if err := Walk(bytes.NewReader(data[:600]), func(_ string, r io.Reader) error {
+		_, err := io.ReadAll(r)
+		return err
+	}); err == nil {
+		t.Error("expected an error for a truncated archive")
+	}
+}
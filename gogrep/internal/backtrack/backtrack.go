@@ -0,0 +1,798 @@
+// Package backtrack implements a backtracking regular expression engine for
+// Perl-compatible patterns (gogrep -P). Unlike Go's regexp, it supports
+// lookaround assertions, backreferences, atomic groups and possessive
+// quantifiers, at the price of exponential time in the worst case: every
+// search is bounded by a budget of steps and of time, and fails with
+// ErrStepLimit or ErrTimeLimit when it runs out.
+//
+// Matches are leftmost-first, as in Perl. Patterns match UTF-8 text; \d and
+// the POSIX classes are ASCII, \w, \s and \b are Unicode-aware.
+package backtrack
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default budget of a search.
+const (
+	DefaultMaxSteps = 10_000_000
+	DefaultTimeout  = time.Second
+)
+
+var (
+	// ErrStepLimit is returned when a search takes more steps than allowed.
+	ErrStepLimit = errors.New("exceeded the backtracking step limit")
+	// ErrTimeLimit is returned when a search takes longer than allowed.
+	ErrTimeLimit = errors.New("exceeded the backtracking time limit")
+)
+
+// Options control the compilation and the budget of searches.
+type Options struct {
+	IgnoreCase bool          // match case-insensitively, as with (?i)
+	MaxSteps   int           // steps a search may take; 0 means DefaultMaxSteps
+	Timeout    time.Duration // time a search may take; 0 means DefaultTimeout
+}
+
+// Regexp is a compiled pattern. It is safe for concurrent use.
+type Regexp struct {
+	prog     node
+	ncap     int
+	names    map[string]int
+	anchored bool // the pattern only matches at the start of the text
+	dotStar  bool // the pattern starts with .*, so it only matches at the start of lines
+	required rune // a rune every match contains, or -1
+	maxSteps int
+	timeout  time.Duration
+}
+
+// Compile parses a Perl-compatible pattern.
+func Compile(pattern string, opts Options) (*Regexp, error) {
+	prog, ncap, names, err := parse(pattern, opts.IgnoreCase)
+	if err != nil {
+		return nil, err
+	}
+	re := &Regexp{
+		prog:     prog,
+		ncap:     ncap,
+		names:    names,
+		anchored: startsAnchored(prog),
+		dotStar:  startsWithDotStar(prog),
+		required: requiredRune(prog),
+		maxSteps: opts.MaxSteps,
+		timeout:  opts.Timeout,
+	}
+	if re.maxSteps <= 0 {
+		re.maxSteps = DefaultMaxSteps
+	}
+	if re.timeout <= 0 {
+		re.timeout = DefaultTimeout
+	}
+	return re, nil
+}
+
+// NumSubexp returns the number of capturing groups.
+func (re *Regexp) NumSubexp() int {
+	return re.ncap
+}
+
+// MatchString reports whether s contains a match.
+func (re *Regexp) MatchString(s string) (matched bool, err error) {
+	m := re.newMachine(s)
+	defer m.recover(&err)
+	_, _, matched = m.find(0)
+	return matched, nil
+}
+
+// FindStringSubmatchIndex returns the leftmost match in s and the spans of
+// its groups as pairs of indexes, -1 for groups that didn't participate,
+// or nil if there is no match.
+func (re *Regexp) FindStringSubmatchIndex(s string) (loc []int, err error) {
+	m := re.newMachine(s)
+	defer m.recover(&err)
+	start, end, ok := m.find(0)
+	if !ok {
+		return nil, nil
+	}
+	loc = slices.Clone(m.caps)
+	loc[0], loc[1] = start, end
+	return loc, nil
+}
+
+// SubexpIndex returns the index of the group with the given name, or -1 if
+// there is none.
+func (re *Regexp) SubexpIndex(name string) int {
+	if i, ok := re.names[name]; ok {
+		return i
+	}
+	return -1
+}
+
+// FindAllStringIndex returns the spans of all successive non-overlapping
+// matches in s. As in Go's regexp, an empty match right after a match is
+// ignored.
+func (re *Regexp) FindAllStringIndex(s string) (spans [][2]int, err error) {
+	err = re.findAll(s, func(m *machine, start, end int) {
+		spans = append(spans, [2]int{start, end})
+	})
+	return spans, err
+}
+
+// FindAllStringSubmatchIndex is like FindAllStringIndex but also returns the
+// spans of the groups of every match, as FindStringSubmatchIndex does.
+func (re *Regexp) FindAllStringSubmatchIndex(s string) (locs [][]int, err error) {
+	err = re.findAll(s, func(m *machine, start, end int) {
+		loc := slices.Clone(m.caps)
+		loc[0], loc[1] = start, end
+		locs = append(locs, loc)
+	})
+	return locs, err
+}
+
+// findAll calls found for the successive non-overlapping matches in s,
+// while the captures of the match are in m.
+func (re *Regexp) findAll(s string, found func(m *machine, start, end int)) (err error) {
+	m := re.newMachine(s)
+	defer m.recover(&err)
+
+	prevEnd := -1
+	for pos := 0; pos <= len(s); {
+		start, end, ok := m.find(pos)
+		if !ok {
+			break
+		}
+		if start == end && start == prevEnd {
+			if start == len(s) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(s[start:])
+			pos = start + size
+			continue
+		}
+		found(m, start, end)
+		prevEnd, pos = end, end
+		if start == end {
+			if end == len(s) {
+				break
+			}
+			_, size := utf8.DecodeRuneInString(s[end:])
+			pos += size
+		}
+	}
+	return nil
+}
+
+// limitError carries a budget error up the recursion of a search.
+type limitError struct {
+	err error
+}
+
+// machine is the state of a search.
+type machine struct {
+	re       *Regexp
+	input    string
+	caps     []int // start and end of each group, -1 if unset
+	steps    int
+	deadline time.Time
+}
+
+func (re *Regexp) newMachine(s string) *machine {
+	caps := make([]int, 2*(re.ncap+1))
+	for i := range caps {
+		caps[i] = -1
+	}
+	return &machine{re: re, input: s, caps: caps, deadline: time.Now().Add(re.timeout)}
+}
+
+// step accounts for a step of the search and aborts it once the budget
+// is exhausted. The clock is looked at every few thousand steps only.
+func (m *machine) step() {
+	m.steps++
+	if m.steps > m.re.maxSteps {
+		panic(limitError{ErrStepLimit})
+	}
+	if m.steps&0xfff == 0 && time.Now().After(m.deadline) {
+		panic(limitError{ErrTimeLimit})
+	}
+}
+
+// recover turns an aborted search into its error.
+func (m *machine) recover(err *error) {
+	if r := recover(); r != nil {
+		limit, ok := r.(limitError)
+		if !ok {
+			panic(r)
+		}
+		*err = limit.err
+	}
+}
+
+// find returns the leftmost match starting at or after from.
+func (m *machine) find(from int) (start, end int, ok bool) {
+	accept := func(j int) bool {
+		end = j
+		return true
+	}
+	if m.re.required >= 0 && !strings.ContainsRune(m.input[from:], m.re.required) {
+		return -1, -1, false
+	}
+	for i := from; i <= len(m.input); {
+		if m.re.prog.match(m, i, accept) {
+			return i, end, true
+		}
+		if m.re.anchored || i == len(m.input) {
+			break
+		}
+		if m.re.dotStar {
+			// .* tried all the matches starting up to the next line break
+			nl := strings.IndexByte(m.input[i:], '\n')
+			if nl < 0 {
+				break
+			}
+			i += nl + 1
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(m.input[i:])
+		i += size
+	}
+	return -1, -1, false
+}
+
+// saveCaps returns a copy of the groups to restore them with restoreCaps.
+func (m *machine) saveCaps() []int {
+	return slices.Clone(m.caps)
+}
+
+func (m *machine) restoreCaps(saved []int) {
+	copy(m.caps, saved)
+}
+
+// node is a part of a compiled pattern.
+type node interface {
+	// match matches the node at position i and calls k with the end of the
+	// match, backtracking into the node while k returns false. It returns
+	// whether k eventually succeeded.
+	match(m *machine, i int, k func(int) bool) bool
+}
+
+// startsAnchored reports whether n can only match at the start of the text.
+func startsAnchored(n node) bool {
+	switch n := n.(type) {
+	case seq:
+		return len(n) > 0 && startsAnchored(n[0])
+	case *capture:
+		return startsAnchored(n.sub)
+	case *atomic:
+		return startsAnchored(n.sub)
+	case alt:
+		for _, a := range n {
+			if !startsAnchored(a) {
+				return false
+			}
+		}
+		return len(n) > 0
+	case assertion:
+		return n == beginText
+	}
+	return false
+}
+
+// startsWithDotStar reports whether n starts with a greedy .* that doesn't
+// match line breaks. If such a pattern doesn't match at some position, it
+// doesn't match anywhere up to the next line break either.
+func startsWithDotStar(n node) bool {
+	switch n := n.(type) {
+	case seq:
+		return len(n) > 0 && startsWithDotStar(n[0])
+	case *repeat:
+		o, ok := n.sub.(*one)
+		if !ok || n.min != 0 || n.max >= 0 || n.mode != greedy {
+			return false
+		}
+		a, ok := o.set.(anyRune)
+		return ok && !a.dotAll
+	}
+	return false
+}
+
+// requiredRune returns a rune that every match of n contains, or -1 if
+// there is none (that is easy to find). Searches skip texts without it.
+func requiredRune(n node) rune {
+	switch n := n.(type) {
+	case seq:
+		for _, s := range n {
+			if r := requiredRune(s); r >= 0 {
+				return r
+			}
+		}
+	case *one:
+		if l, ok := n.set.(*litRune); ok && !l.fold {
+			return l.r
+		}
+	case *capture:
+		return requiredRune(n.sub)
+	case *atomic:
+		return requiredRune(n.sub)
+	case *repeat:
+		if n.min > 0 {
+			return requiredRune(n.sub)
+		}
+	}
+	return -1
+}
+
+// seq matches its nodes one after the other.
+type seq []node
+
+func (s seq) match(m *machine, i int, k func(int) bool) bool {
+	if len(s) == 0 {
+		return k(i)
+	}
+	if len(s) == 1 {
+		return s[0].match(m, i, k)
+	}
+	return s[0].match(m, i, func(j int) bool {
+		return s[1:].match(m, j, k)
+	})
+}
+
+// alt tries its alternatives in order.
+type alt []node
+
+func (a alt) match(m *machine, i int, k func(int) bool) bool {
+	for _, n := range a {
+		m.step()
+		if n.match(m, i, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// capture records the span of its match as group idx.
+type capture struct {
+	sub node
+	idx int
+}
+
+func (c *capture) match(m *machine, i int, k func(int) bool) bool {
+	return c.sub.match(m, i, func(j int) bool {
+		oldStart, oldEnd := m.caps[2*c.idx], m.caps[2*c.idx+1]
+		m.caps[2*c.idx], m.caps[2*c.idx+1] = i, j
+		if k(j) {
+			return true
+		}
+		m.caps[2*c.idx], m.caps[2*c.idx+1] = oldStart, oldEnd
+		return false
+	})
+}
+
+type repeatMode int
+
+const (
+	greedy     repeatMode = iota // as many as possible, then back off
+	lazy                         // as few as possible, then extend
+	possessive                   // as many as possible, never backing off
+)
+
+// repeat matches sub between min and max times (max < 0: unbounded).
+type repeat struct {
+	sub      node
+	min, max int
+	mode     repeatMode
+}
+
+func (r *repeat) match(m *machine, i int, k func(int) bool) bool {
+	if r.mode == possessive {
+		return r.matchPossessive(m, i, k)
+	}
+	if o, ok := r.sub.(*one); ok {
+		return r.matchRunes(m, o.set, i, k)
+	}
+	return r.iterate(m, i, 0, k)
+}
+
+func (r *repeat) more(n int) bool {
+	return r.max < 0 || n < r.max
+}
+
+// iterate matches further repetitions after n of them ended at i.
+// A repetition matching the empty string ends the loop once min is reached.
+func (r *repeat) iterate(m *machine, i, n int, k func(int) bool) bool {
+	m.step()
+	again := func(j int) bool {
+		if j == i && n >= r.min {
+			return k(j) // an empty repetition ends the loop
+		}
+		return r.iterate(m, j, n+1, k)
+	}
+	if r.mode == lazy {
+		if n >= r.min && k(i) {
+			return true
+		}
+		return r.more(n) && r.sub.match(m, i, again)
+	}
+	if r.more(n) && r.sub.match(m, i, again) {
+		return true
+	}
+	return n >= r.min && k(i)
+}
+
+// matchRunes repeats a single-rune node without recursing for every rune,
+// so long lines don't make the stack grow.
+func (r *repeat) matchRunes(m *machine, set runeSet, i int, k func(int) bool) bool {
+	n, j := 0, i
+	if r.mode == lazy {
+		for {
+			if n >= r.min {
+				m.step()
+				if k(j) {
+					return true
+				}
+			}
+			if !r.more(n) || j >= len(m.input) {
+				return false
+			}
+			c, size := utf8.DecodeRuneInString(m.input[j:])
+			if !set.contains(c) {
+				return false
+			}
+			j += size
+			n++
+		}
+	}
+
+	for r.more(n) && j < len(m.input) {
+		c, size := utf8.DecodeRuneInString(m.input[j:])
+		if !set.contains(c) {
+			break
+		}
+		m.step()
+		j += size
+		n++
+	}
+	for ; n >= r.min; n-- {
+		m.step()
+		if k(j) {
+			return true
+		}
+		if n == 0 {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(m.input[i:j])
+		j -= size
+	}
+	return false
+}
+
+// matchPossessive matches as many repetitions as possible and never gives
+// them back.
+func (r *repeat) matchPossessive(m *machine, i int, k func(int) bool) bool {
+	n, j := 0, i
+	for r.more(n) {
+		m.step()
+		next := -1
+		if !r.sub.match(m, j, func(e int) bool { next = e; return true }) {
+			break
+		}
+		n++
+		if next == j {
+			n = max(n, r.min) // empty repetitions can be repeated at will
+			break
+		}
+		j = next
+	}
+	return n >= r.min && k(j)
+}
+
+// atomic matches sub once, without backtracking into it: (?>...).
+type atomic struct {
+	sub node
+}
+
+func (a *atomic) match(m *machine, i int, k func(int) bool) bool {
+	saved := m.saveCaps()
+	end := -1
+	if !a.sub.match(m, i, func(j int) bool { end = j; return true }) {
+		return false
+	}
+	if k(end) {
+		return true
+	}
+	m.restoreCaps(saved)
+	return false
+}
+
+// look is a lookahead assertion: (?=...) or, negated, (?!...).
+type look struct {
+	sub    node
+	negate bool
+}
+
+func (l *look) match(m *machine, i int, k func(int) bool) bool {
+	saved := m.saveCaps()
+	found := l.sub.match(m, i, func(int) bool { return true })
+	if found == l.negate {
+		m.restoreCaps(saved)
+		return false
+	}
+	if l.negate {
+		m.restoreCaps(saved) // groups of a failed match are unset
+	}
+	if k(i) {
+		return true
+	}
+	m.restoreCaps(saved)
+	return false
+}
+
+// lookBehind is a lookbehind assertion: (?<=...) or, negated, (?<!...).
+// Unlike in Perl, sub may have any length; it is tried from all starting
+// points within its possible length.
+type lookBehind struct {
+	sub              node
+	negate           bool
+	minLen, maxRunes int // bounds of the length of a match of sub; maxRunes < 0: unbounded
+}
+
+func newLookBehind(sub node, negate bool) *lookBehind {
+	lo, hi := width(sub)
+	return &lookBehind{sub: sub, negate: negate, minLen: lo, maxRunes: hi}
+}
+
+func (l *lookBehind) match(m *machine, i int, k func(int) bool) bool {
+	saved := m.saveCaps()
+	found := false
+	lowest := 0
+	if l.maxRunes >= 0 {
+		lowest = max(0, i-utf8.UTFMax*l.maxRunes)
+	}
+	for s := i - l.minLen; s >= lowest && !found; s-- {
+		if s < len(m.input) && !utf8.RuneStart(m.input[s]) {
+			continue
+		}
+		m.step()
+		found = l.sub.match(m, s, func(j int) bool { return j == i })
+	}
+	if found == l.negate {
+		m.restoreCaps(saved)
+		return false
+	}
+	if l.negate {
+		m.restoreCaps(saved)
+	}
+	if k(i) {
+		return true
+	}
+	m.restoreCaps(saved)
+	return false
+}
+
+// width returns the minimum number of bytes and the maximum number of runes
+// that n can match, or -1 for the latter if it is unbounded.
+func width(n node) (minBytes, maxRunes int) {
+	switch n := n.(type) {
+	case *one:
+		return 1, 1
+	case seq:
+		for _, s := range n {
+			lo, hi := width(s)
+			minBytes += lo
+			maxRunes = addWidth(maxRunes, hi)
+		}
+		return minBytes, maxRunes
+	case alt:
+		for i, a := range n {
+			lo, hi := width(a)
+			if i == 0 || lo < minBytes {
+				minBytes = lo
+			}
+			if i == 0 || maxRunes >= 0 && (hi < 0 || hi > maxRunes) {
+				maxRunes = hi
+			}
+		}
+		return minBytes, maxRunes
+	case *capture:
+		return width(n.sub)
+	case *atomic:
+		return width(n.sub)
+	case *repeat:
+		lo, hi := width(n.sub)
+		if n.max < 0 || hi < 0 {
+			return lo * n.min, -1
+		}
+		return lo * n.min, hi * n.max
+	case *backref:
+		return 0, -1
+	}
+	return 0, 0 // assertions
+}
+
+func addWidth(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
+
+// backref matches the text of group idx again; it fails if the group
+// didn't participate in the match.
+type backref struct {
+	idx  int
+	fold bool
+}
+
+func (b *backref) match(m *machine, i int, k func(int) bool) bool {
+	m.step()
+	start, end := m.caps[2*b.idx], m.caps[2*b.idx+1]
+	if start < 0 {
+		return false
+	}
+	text := m.input[start:end]
+	rest := m.input[i:]
+	if !b.fold {
+		return len(rest) >= len(text) && rest[:len(text)] == text && k(i+len(text))
+	}
+	n := 0
+	for _, want := range text {
+		if n >= len(rest) {
+			return false
+		}
+		got, size := utf8.DecodeRuneInString(rest[n:])
+		if !equalFold(got, want) {
+			return false
+		}
+		n += size
+	}
+	return k(i + n)
+}
+
+// assertion is a zero-width test of the position.
+type assertion int
+
+const (
+	beginText      assertion = iota // ^, \A
+	beginLine                       // ^ with (?m)
+	endText                         // \z
+	endTextOptNL                    // $, \Z: end of text or before a final \n
+	endLine                         // $ with (?m)
+	wordBoundary                    // \b
+	noWordBoundary                  // \B
+)
+
+func (a assertion) match(m *machine, i int, k func(int) bool) bool {
+	m.step()
+	s := m.input
+	var ok bool
+	switch a {
+	case beginText:
+		ok = i == 0
+	case beginLine:
+		ok = i == 0 || s[i-1] == '\n'
+	case endText:
+		ok = i == len(s)
+	case endTextOptNL:
+		ok = i == len(s) || i == len(s)-1 && s[i] == '\n'
+	case endLine:
+		ok = i == len(s) || s[i] == '\n'
+	case wordBoundary, noWordBoundary:
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i:])
+		ok = (i > 0 && IsWordRune(before)) != (i < len(s) && IsWordRune(after))
+		ok = ok == (a == wordBoundary)
+	}
+	return ok && k(i)
+}
+
+// one matches a single rune of a set.
+type one struct {
+	set runeSet
+}
+
+func (o *one) match(m *machine, i int, k func(int) bool) bool {
+	m.step()
+	if i >= len(m.input) {
+		return false
+	}
+	r, size := utf8.DecodeRuneInString(m.input[i:])
+	return o.set.contains(r) && k(i+size)
+}
+
+// runeSet is a set of runes.
+type runeSet interface {
+	contains(r rune) bool
+}
+
+// litRune is a single rune, or its case variants with fold.
+type litRune struct {
+	r    rune
+	fold bool
+}
+
+func (l *litRune) contains(r rune) bool {
+	return r == l.r || l.fold && equalFold(r, l.r)
+}
+
+// anyRune is '.', which doesn't match \n unless dotAll.
+type anyRune struct {
+	dotAll bool
+}
+
+func (a anyRune) contains(r rune) bool {
+	return a.dotAll || r != '\n'
+}
+
+type runeFunc func(rune) bool
+
+func (f runeFunc) contains(r rune) bool {
+	return f(r)
+}
+
+type notSet struct {
+	runeSet
+}
+
+func (n notSet) contains(r rune) bool {
+	return !n.runeSet.contains(r)
+}
+
+type tableSet struct {
+	table *unicode.RangeTable
+}
+
+func (t tableSet) contains(r rune) bool {
+	return unicode.Is(t.table, r)
+}
+
+type runeRange struct {
+	lo, hi rune
+}
+
+// charClass is a bracket expression.
+type charClass struct {
+	ranges []runeRange
+	sets   []runeSet
+	negate bool
+	fold   bool
+}
+
+func (c *charClass) contains(r rune) bool {
+	found := c.has(r)
+	if !found && c.fold {
+		for f := unicode.SimpleFold(r); f != r && !found; f = unicode.SimpleFold(f) {
+			found = c.has(f)
+		}
+	}
+	return found != c.negate
+}
+
+func (c *charClass) has(r rune) bool {
+	for _, rr := range c.ranges {
+		if rr.lo <= r && r <= rr.hi {
+			return true
+		}
+	}
+	for _, s := range c.sets {
+		if s.contains(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalFold reports whether a and b are equal under simple case folding.
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
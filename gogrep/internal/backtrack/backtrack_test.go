@@ -0,0 +1,221 @@
+package backtrack
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindStringSubmatchIndex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		want    []int // nil: no match
+	}{
+		// basics
+		{`abc`, "xxabcxx", []int{2, 5}},
+		{`a.c`, "abc", []int{0, 3}},
+		{`a.c`, "a\nc", nil},
+		{`(?s)a.c`, "a\nc", []int{0, 3}},
+		{`^abc$`, "abc", []int{0, 3}},
+		{`^abc$`, "abc\n", []int{0, 3}},
+		{`^b`, "ab", nil},
+		{`(?m)^b`, "a\nb", []int{2, 3}},
+		{`a\z`, "a\n", nil},
+		{`x*`, "", []int{0, 0}},
+		{`a|ab`, "ab", []int{0, 1}}, // leftmost-first, not longest
+		{`(a|ab)(c|bcd)`, "abcd", []int{0, 4, 0, 1, 1, 4}},
+		{`[^a-c]+`, "abcdef", []int{3, 6}},
+		{`[]a]+`, "]a]b", []int{0, 3}},
+		{`[\d-]+`, "x12-3x", []int{1, 5}},
+		{`[[:alpha:]]+`, "12ab3", []int{2, 4}},
+		{`\d+`, "ab123", []int{2, 5}},
+		{`\w+`, "  héllo_1 ", []int{2, 10}},
+		{`\bfoo\b`, "afoo foo", []int{5, 8}},
+		{`\p{Greek}+`, "abc αβγ", []int{4, 10}},
+		{`\P{L}+`, "ab12cd", []int{2, 4}},
+		{`\x41\x{42}`, "xAB", []int{1, 3}},
+		{`\Qa.b\E`, "axb a.b", []int{4, 7}},
+		{`a(?#comment)b`, "ab", []int{0, 2}},
+		{`a{`, "a{", []int{0, 2}},
+		{`a{,2}b`, "aaab", []int{1, 4}},
+
+		// quantifiers
+		{`a{2,3}`, "aaaa", []int{0, 3}},
+		{`a{2}`, "a", nil},
+		{`a+?`, "aaa", []int{0, 1}},
+		{`<.*>`, "<a><b>", []int{0, 6}},
+		{`<.*?>`, "<a><b>", []int{0, 3}},
+		{`a*+a`, "aaa", nil},
+		{`a++b`, "aaab", []int{0, 4}},
+		{`(?:ab)*c`, "ababc", []int{0, 5}},
+		{`(a*)*b`, "aab", []int{0, 3, 2, 2}},
+		{`(a|b)*?c`, "abc", []int{0, 3, 1, 2}},
+
+		// case folding
+		{`(?i)straße`, "STRASSE Straße", []int{8, 15}},
+		{`(?i)k`, "K", []int{0, 3}},
+		{`(?i)[a-c]+`, "xABCx", []int{1, 4}},
+		{`a(?i)b|c`, "AB aB C", []int{3, 5}},
+		{`(?i:a)b`, "Ab AB", []int{0, 2}},
+
+		// lookaround
+		{`foo(?=bar)`, "foobaz foobar", []int{7, 10}},
+		{`foo(?!bar)`, "foobar foobaz", []int{7, 10}},
+		{`(?<=\$)\d+`, "a1 $42", []int{4, 6}},
+		{`(?<!\$)\b\d+`, "$42 17", []int{4, 6}},
+		{`(?<=ab|c)d`, "abd", []int{2, 3}},
+		{`(?<=a.*)z`, "xaxxz", []int{4, 5}},
+		{`(?<=é)x`, "éx", []int{2, 3}},
+		{`^(?=.*\d)(?=.*[a-z]).{6,}$`, "abc123", []int{0, 6}},
+		{`^(?=.*\d)(?=.*[a-z]).{6,}$`, "abcdef", nil},
+
+		// backreferences
+		{`(a+)b\1`, "aaba", []int{1, 4, 1, 2}},
+		{`(\w)\1`, "abccd", []int{2, 4, 2, 3}},
+		{`(?<q>['"]).*?\k<q>`, `say "hi" 'x'`, []int{4, 8, 4, 5}},
+		{`(?P<q>x)(?P=q)`, "xx", []int{0, 2, 0, 1}},
+		{`(a)(b)\g{-1}\g1`, "abba", []int{0, 4, 0, 1, 1, 2}},
+		{`(?i)(a)\1`, "aA", []int{0, 2, 0, 1}},
+		{`(a)?b\1`, "b", nil},
+
+		// atomic groups
+		{`(?>a+)b`, "aab", []int{0, 3}},
+		{`(?>a|ab)c`, "abc", nil},
+	}
+	for _, tt := range tests {
+		re, err := Compile(tt.pattern, Options{})
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.pattern, err)
+			continue
+		}
+		got, err := re.FindStringSubmatchIndex(tt.input)
+		if err != nil {
+			t.Errorf("%q on %q: %v", tt.pattern, tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q on %q: got %v, want %v", tt.pattern, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreCaseOption(t *testing.T) {
+	re, err := Compile(`error|(?-i:WARN)`, Options{IgnoreCase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for input, want := range map[string]bool{"ERROR": true, "Error": true, "WARN": true, "warn": false} {
+		if got, _ := re.MatchString(input); got != want {
+			t.Errorf("MatchString(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+// TestAgainstRegexp compares leftmost-first matches of patterns that Go's
+// regexp supports too.
+func TestAgainstRegexp(t *testing.T) {
+	patterns := []string{`a+`, `(a|b)c*`, `[0-9]{2,3}`, `x*`, `\bw\w*`, `(?i)ab+`, `^\s*\S`, `.+?;`, `(a|ab)(c|bcd)`}
+	inputs := []string{"", "abc", "aaa bcc", "1 12 123 1234", "word wide web", "AbBb", "  x", "a;b;", "abcd"}
+	for _, p := range patterns {
+		want := regexp.MustCompile(p)
+		re, err := Compile(p, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, in := range inputs {
+			got, err := re.FindAllStringIndex(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wantSpans [][2]int
+			for _, loc := range want.FindAllStringIndex(in, -1) {
+				wantSpans = append(wantSpans, [2]int{loc[0], loc[1]})
+			}
+			if !reflect.DeepEqual(got, wantSpans) {
+				t.Errorf("%q on %q: got %v, want %v", p, in, got, wantSpans)
+			}
+
+			gotLocs, err := re.FindAllStringSubmatchIndex(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if wantLocs := want.FindAllStringSubmatchIndex(in, -1); !reflect.DeepEqual(gotLocs, wantLocs) {
+				t.Errorf("%q on %q: got submatches %v, want %v", p, in, gotLocs, wantLocs)
+			}
+		}
+	}
+}
+
+func TestSubexpIndex(t *testing.T) {
+	re, err := Compile(`(?<year>\d{4})-(\d\d)-(?P<day>\d\d)`, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]int{"year": 1, "day": 3, "month": -1, "": -1} {
+		if got := re.SubexpIndex(name); got != want {
+			t.Errorf("SubexpIndex(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, p := range []string{
+		`(ab`, `ab)`, `[ab`, `*a`, `a**`, `a{3,2}`, `a{70000}`, `\1(a)(b)\3`, `(a)\2`,
+		`\k<nope>`, `(?<n>a)(?<n>b)`, `(?z)`, `[[:nope:]]`, `\p{Nope}`, `\q`, `[z-a]`, `a\`,
+	} {
+		if _, err := Compile(p, Options{}); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", p)
+		}
+	}
+	if _, err := Compile(`\1(a)`, Options{}); err != nil {
+		t.Errorf("a forward reference is valid: %v", err)
+	}
+}
+
+func TestStepLimit(t *testing.T) {
+	re, err := Compile(`(a|a)+b`, Options{MaxSteps: 100_000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = re.MatchString(strings.Repeat("a", 40) + "cb")
+	if !errors.Is(err, ErrStepLimit) {
+		t.Errorf("got error %v, want %v", err, ErrStepLimit)
+	}
+
+	// the budget is per search
+	if ok, err := re.MatchString("aab"); !ok || err != nil {
+		t.Errorf("got %v, %v, want a match", ok, err)
+	}
+}
+
+func TestTimeLimit(t *testing.T) {
+	re, err := Compile(`(x+x+)+y`, Options{MaxSteps: 1 << 62, Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err = re.MatchString(strings.Repeat("x", 60) + "-y")
+	if !errors.Is(err, ErrTimeLimit) {
+		t.Errorf("got error %v, want %v", err, ErrTimeLimit)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("the search took %v", elapsed)
+	}
+}
+
+func TestLongLines(t *testing.T) {
+	line := strings.Repeat("x", 1<<20)
+	for _, p := range []string{`x*y`, `.*y`, `^x+$`, `(?:x)*`} {
+		re, err := Compile(p, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := re.MatchString(line); err != nil {
+			t.Errorf("%q: %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,714 @@
+package backtrack
+
+import (
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error codes not known to regexp/syntax.
+const (
+	errInvalidBackref   syntax.ErrorCode = "invalid backreference"
+	errUnknownGroupName syntax.ErrorCode = "reference to unknown group name"
+	errDuplicateName    syntax.ErrorCode = "duplicate group name"
+	errUnknownClass     syntax.ErrorCode = "unknown property or POSIX class"
+)
+
+// maxRepeat is the largest count allowed in {n,m}, as in PCRE.
+const maxRepeat = 65535
+
+// flags are the options that can be changed inside a pattern with (?imsx).
+type flags struct {
+	fold      bool // i: case-insensitive
+	multiline bool // m: ^ and $ also match at line breaks
+	dotAll    bool // s: . also matches \n
+}
+
+type parser struct {
+	src   string
+	pos   int
+	flags flags
+	ncap  int
+	names map[string]int
+
+	backrefs []*backref // resolved once all groups are known
+	refNames []string   // names of the backrefs by name, "" for numbered ones
+}
+
+func parse(src string, fold bool) (node, int, map[string]int, error) {
+	p := &parser{src: src, flags: flags{fold: fold}, names: map[string]int{}}
+	n, err := p.parseAlt()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if p.pos < len(p.src) {
+		// parseAlt only stops early at a ')'
+		return nil, 0, nil, p.error(syntax.ErrUnexpectedParen, p.src)
+	}
+	for i, ref := range p.backrefs {
+		if name := p.refNames[i]; name != "" {
+			idx, ok := p.names[name]
+			if !ok {
+				return nil, 0, nil, p.error(errUnknownGroupName, name)
+			}
+			ref.idx = idx
+		}
+		if ref.idx < 1 || ref.idx > p.ncap {
+			return nil, 0, nil, p.error(errInvalidBackref, `\`+strconv.Itoa(ref.idx))
+		}
+	}
+	return n, p.ncap, p.names, nil
+}
+
+func (p *parser) error(code syntax.ErrorCode, expr string) error {
+	return &syntax.Error{Code: code, Expr: expr}
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+func (p *parser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	return r
+}
+
+func (p *parser) consume(prefix string) bool {
+	if strings.HasPrefix(p.src[p.pos:], prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// parseAlt parses alternatives up to the end of the pattern or a ')'.
+// Flags changed with (?i) apply up to the end of the enclosing group.
+func (p *parser) parseAlt() (node, error) {
+	saved := p.flags
+	defer func() { p.flags = saved }()
+
+	var alts alt
+	for {
+		n, err := p.parseSeq()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, n)
+		if !p.consume("|") {
+			break
+		}
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return alts, nil
+}
+
+// parseSeq parses quantified atoms up to a '|', a ')' or the end.
+func (p *parser) parseSeq() (node, error) {
+	var s seq
+	for !p.eof() {
+		switch p.peek() {
+		case '|', ')':
+			if len(s) == 1 {
+				return s[0], nil
+			}
+			return s, nil
+		case '*', '+', '?':
+			return nil, p.error(syntax.ErrMissingRepeatArgument, p.src[p.pos:p.pos+1])
+		}
+		if p.consume(`\Q`) {
+			// quoted literal up to \E
+			end := strings.Index(p.src[p.pos:], `\E`)
+			if end < 0 {
+				end = len(p.src) - p.pos
+			}
+			for _, r := range p.src[p.pos : p.pos+end] {
+				s = append(s, &one{&litRune{r: r, fold: p.flags.fold}})
+			}
+			p.pos = min(p.pos+end+2, len(p.src))
+			continue
+		}
+		if p.consume("(?#") {
+			end := strings.IndexByte(p.src[p.pos:], ')')
+			if end < 0 {
+				return nil, p.error(syntax.ErrMissingParen, p.src)
+			}
+			p.pos += end + 1
+			continue
+		}
+
+		start := p.pos
+		n, err := p.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if n == nil {
+			continue // a flag group like (?i)
+		}
+		n, err = p.parseRepeat(n, start)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, n)
+	}
+	if len(s) == 1 {
+		return s[0], nil
+	}
+	return s, nil
+}
+
+// parseRepeat parses the quantifiers following the atom n, which starts at start.
+func (p *parser) parseRepeat(n node, start int) (node, error) {
+	for !p.eof() {
+		qstart := p.pos
+		lo, hi := -1, -1
+		switch p.peek() {
+		case '*':
+			p.next()
+			lo, hi = 0, -1
+		case '+':
+			p.next()
+			lo, hi = 1, -1
+		case '?':
+			p.next()
+			lo, hi = 0, 1
+		case '{':
+			var ok bool
+			lo, hi, ok = p.parseInterval()
+			if !ok {
+				return n, nil // a literal '{'
+			}
+			if lo > maxRepeat || hi > maxRepeat || hi >= 0 && hi < lo {
+				return nil, p.error(syntax.ErrInvalidRepeatSize, p.src[qstart:p.pos])
+			}
+		default:
+			return n, nil
+		}
+
+		mode := greedy
+		if p.consume("?") {
+			mode = lazy
+		} else if p.consume("+") {
+			mode = possessive
+		}
+		if !p.eof() && strings.ContainsRune("*+?", p.peek()) {
+			return nil, p.error(syntax.ErrInvalidRepeatOp, p.src[start:p.pos+1])
+		}
+		n = &repeat{sub: n, min: lo, max: hi, mode: mode}
+	}
+	return n, nil
+}
+
+// parseInterval parses {n}, {n,}, {n,m} or {,m}. If the text isn't an
+// interval, it returns false and consumes nothing: the '{' is a literal.
+func (p *parser) parseInterval() (lo, hi int, ok bool) {
+	start := p.pos
+	p.next() // '{'
+	lo, okLo := p.parseInt()
+	hi = lo
+	okHi := okLo
+	if p.consume(",") {
+		hi, okHi = p.parseInt()
+		if !okHi {
+			hi, okHi = -1, true
+		}
+		if !okLo {
+			lo, okLo = 0, okHi && hi >= 0
+		}
+	}
+	if !okLo || !okHi || !p.consume("}") {
+		p.pos = start
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+func (p *parser) parseInt() (int, bool) {
+	start := p.pos
+	for !p.eof() && '0' <= p.src[p.pos] && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return maxRepeat + 1, true // too large
+	}
+	return n, true
+}
+
+// parseAtom parses an atom; it returns nil for groups that only set flags.
+func (p *parser) parseAtom() (node, error) {
+	switch r := p.next(); r {
+	case '(':
+		return p.parseGroup()
+	case '[':
+		c, err := p.parseClass()
+		if err != nil {
+			return nil, err
+		}
+		return &one{c}, nil
+	case '.':
+		return &one{anyRune{dotAll: p.flags.dotAll}}, nil
+	case '^':
+		if p.flags.multiline {
+			return assertion(beginLine), nil
+		}
+		return assertion(beginText), nil
+	case '$':
+		if p.flags.multiline {
+			return assertion(endLine), nil
+		}
+		return assertion(endTextOptNL), nil
+	case '\\':
+		return p.parseEscape()
+	case '{':
+		if p.pos--; p.isInterval() {
+			return nil, p.error(syntax.ErrMissingRepeatArgument, p.src[p.pos:])
+		}
+		p.pos++
+		return &one{&litRune{r: '{'}}, nil
+	default:
+		return &one{&litRune{r: r, fold: p.flags.fold}}, nil
+	}
+}
+
+func (p *parser) isInterval() bool {
+	start := p.pos
+	_, _, ok := p.parseInterval()
+	p.pos = start
+	return ok
+}
+
+// parseGroup parses a group after its '('.
+func (p *parser) parseGroup() (node, error) {
+	start := p.pos - 1
+	var build func(node) node
+
+	switch {
+	case p.consume("?:"):
+		build = func(n node) node { return n }
+	case p.consume("?="):
+		build = func(n node) node { return &look{sub: n} }
+	case p.consume("?!"):
+		build = func(n node) node { return &look{sub: n, negate: true} }
+	case p.consume("?<="):
+		build = func(n node) node { return newLookBehind(n, false) }
+	case p.consume("?<!"):
+		build = func(n node) node { return newLookBehind(n, true) }
+	case p.consume("?>"):
+		build = func(n node) node { return &atomic{sub: n} }
+	case p.consume("?P="):
+		name, err := p.parseName(')')
+		if err != nil {
+			return nil, err
+		}
+		return p.newBackref(0, name), nil
+	case p.consume("?P<"), p.consume("?<"):
+		name, err := p.parseName('>')
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := p.names[name]; dup {
+			return nil, p.error(errDuplicateName, name)
+		}
+		p.ncap++
+		p.names[name] = p.ncap
+		idx := p.ncap
+		build = func(n node) node { return &capture{sub: n, idx: idx} }
+	case p.consume("?'"):
+		name, err := p.parseName('\'')
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := p.names[name]; dup {
+			return nil, p.error(errDuplicateName, name)
+		}
+		p.ncap++
+		p.names[name] = p.ncap
+		idx := p.ncap
+		build = func(n node) node { return &capture{sub: n, idx: idx} }
+	case p.consume("?"):
+		// flags: (?ims-imsx) for the rest of the group or (?imsx-imsx:...)
+		f := p.flags
+		on := true
+		for {
+			if p.eof() {
+				return nil, p.error(syntax.ErrMissingParen, p.src[start:])
+			}
+			switch c := p.next(); c {
+			case 'i':
+				f.fold = on
+			case 'm':
+				f.multiline = on
+			case 's':
+				f.dotAll = on
+			case '-':
+				if !on {
+					return nil, p.error(syntax.ErrInvalidPerlOp, p.src[start:p.pos])
+				}
+				on = false
+			case ')':
+				p.flags = f
+				return nil, nil
+			case ':':
+				saved := p.flags
+				p.flags = f
+				n, err := p.parseAlt()
+				p.flags = saved
+				if err != nil {
+					return nil, err
+				}
+				if !p.consume(")") {
+					return nil, p.error(syntax.ErrMissingParen, p.src[start:])
+				}
+				return n, nil
+			default:
+				return nil, p.error(syntax.ErrInvalidPerlOp, p.src[start:p.pos])
+			}
+		}
+	default:
+		p.ncap++
+		idx := p.ncap
+		build = func(n node) node { return &capture{sub: n, idx: idx} }
+	}
+
+	n, err := p.parseAlt()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume(")") {
+		return nil, p.error(syntax.ErrMissingParen, p.src[start:])
+	}
+	return build(n), nil
+}
+
+// parseName parses a group name up to the delimiter end.
+func (p *parser) parseName(end byte) (string, error) {
+	i := strings.IndexByte(p.src[p.pos:], end)
+	if i <= 0 {
+		return "", p.error(syntax.ErrInvalidNamedCapture, p.src[p.pos:])
+	}
+	name := p.src[p.pos : p.pos+i]
+	for _, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", p.error(syntax.ErrInvalidNamedCapture, name)
+		}
+	}
+	p.pos += i + 1
+	return name, nil
+}
+
+func (p *parser) newBackref(idx int, name string) node {
+	ref := &backref{idx: idx, fold: p.flags.fold}
+	p.backrefs = append(p.backrefs, ref)
+	p.refNames = append(p.refNames, name)
+	return ref
+}
+
+// parseEscape parses an escape sequence after its '\' outside of classes.
+func (p *parser) parseEscape() (node, error) {
+	if p.eof() {
+		return nil, p.error(syntax.ErrTrailingBackslash, "")
+	}
+	start := p.pos - 1
+	switch r := p.peek(); r {
+	case 'A':
+		p.next()
+		return assertion(beginText), nil
+	case 'z':
+		p.next()
+		return assertion(endText), nil
+	case 'Z':
+		p.next()
+		return assertion(endTextOptNL), nil
+	case 'b':
+		p.next()
+		return assertion(wordBoundary), nil
+	case 'B':
+		p.next()
+		return assertion(noWordBoundary), nil
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// a single digit; \g{10} refers to higher groups
+		p.next()
+		return p.newBackref(int(r-'0'), ""), nil
+	case 'g':
+		p.next()
+		braced := p.consume("{")
+		neg := p.consume("-")
+		idx, ok := p.parseInt()
+		if !ok && braced && !neg {
+			name, err := p.parseName('}')
+			if err != nil {
+				return nil, err
+			}
+			return p.newBackref(0, name), nil
+		}
+		if !ok || braced && !p.consume("}") {
+			return nil, p.error(errInvalidBackref, p.src[start:p.pos])
+		}
+		if neg {
+			idx = p.ncap + 1 - idx // relative to the groups opened so far
+		}
+		return p.newBackref(idx, ""), nil
+	case 'k':
+		p.next()
+		ends := map[rune]byte{'<': '>', '{': '}', '\'': '\''}
+		end, ok := ends[p.peek()]
+		if !ok {
+			return nil, p.error(errInvalidBackref, p.src[start:p.pos])
+		}
+		p.next()
+		name, err := p.parseName(end)
+		if err != nil {
+			return nil, err
+		}
+		return p.newBackref(0, name), nil
+	}
+
+	s, err := p.parseRuneEscape(false)
+	if err != nil {
+		return nil, err
+	}
+	return &one{s}, nil
+}
+
+// parseRuneEscape parses an escape that matches a single rune, such as \d,
+// \p{Greek} or \x41, after its '\'. Inside classes, \b is a backspace.
+func (p *parser) parseRuneEscape(inClass bool) (runeSet, error) {
+	start := p.pos - 1
+	lit := func(r rune) runeSet { return &litRune{r: r, fold: p.flags.fold} }
+
+	switch r := p.next(); r {
+	case 'd':
+		return runeFunc(isDigit), nil
+	case 'D':
+		return notSet{runeFunc(isDigit)}, nil
+	case 'w':
+		return runeFunc(IsWordRune), nil
+	case 'W':
+		return notSet{runeFunc(IsWordRune)}, nil
+	case 's':
+		return runeFunc(unicode.IsSpace), nil
+	case 'S':
+		return notSet{runeFunc(unicode.IsSpace)}, nil
+	case 'h':
+		return runeFunc(isHorizontalSpace), nil
+	case 'H':
+		return notSet{runeFunc(isHorizontalSpace)}, nil
+	case 'N':
+		return anyRune{}, nil
+	case 'p', 'P':
+		set, err := p.parseProperty()
+		if err != nil {
+			return nil, err
+		}
+		if r == 'P' {
+			return notSet{set}, nil
+		}
+		return set, nil
+	case 't':
+		return lit('\t'), nil
+	case 'n':
+		return lit('\n'), nil
+	case 'r':
+		return lit('\r'), nil
+	case 'f':
+		return lit('\f'), nil
+	case 'a':
+		return lit('\a'), nil
+	case 'e':
+		return lit('\x1b'), nil
+	case '0':
+		return lit(0), nil
+	case 'b':
+		if inClass {
+			return lit('\b'), nil
+		}
+	case 'x':
+		var hex string
+		if p.consume("{") {
+			end := strings.IndexByte(p.src[p.pos:], '}')
+			if end < 0 {
+				return nil, p.error(syntax.ErrInvalidEscape, p.src[start:])
+			}
+			hex = p.src[p.pos : p.pos+end]
+			p.pos += end + 1
+		} else {
+			for n := 0; n < 2 && !p.eof() && isHexDigit(rune(p.src[p.pos])); n++ {
+				p.pos++
+			}
+			hex = p.src[start+2 : p.pos]
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || v > unicode.MaxRune {
+			return nil, p.error(syntax.ErrInvalidEscape, p.src[start:p.pos])
+		}
+		return lit(rune(v)), nil
+	default:
+		if r < utf8.RuneSelf && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return lit(r), nil // escaped punctuation
+		}
+	}
+	return nil, p.error(syntax.ErrInvalidEscape, p.src[start:p.pos])
+}
+
+// parseProperty parses the name of \p{Name}, \p{^Name} or \pL.
+func (p *parser) parseProperty() (runeSet, error) {
+	start := p.pos - 2
+	var name string
+	if p.consume("{") {
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return nil, p.error(syntax.ErrInvalidCharRange, p.src[start:])
+		}
+		name = p.src[p.pos : p.pos+end]
+		p.pos += end + 1
+	} else if !p.eof() {
+		name = string(p.next())
+	}
+
+	negate := strings.HasPrefix(name, "^")
+	name = strings.TrimPrefix(name, "^")
+	var set runeSet
+	switch {
+	case name == "Any":
+		set = anyRune{dotAll: true}
+	case unicode.Categories[name] != nil:
+		set = tableSet{unicode.Categories[name]}
+	case unicode.Scripts[name] != nil:
+		set = tableSet{unicode.Scripts[name]}
+	default:
+		return nil, p.error(errUnknownClass, p.src[start:p.pos])
+	}
+	if negate {
+		return notSet{set}, nil
+	}
+	return set, nil
+}
+
+// parseClass parses a bracket expression after its '['.
+func (p *parser) parseClass() (*charClass, error) {
+	start := p.pos - 1
+	c := &charClass{fold: p.flags.fold}
+	c.negate = p.consume("^")
+
+	for first := true; ; first = false {
+		if p.eof() {
+			return nil, p.error(syntax.ErrMissingBracket, p.src[start:])
+		}
+		if p.peek() == ']' && !first {
+			p.next()
+			return c, nil
+		}
+		if p.consume("[:") {
+			end := strings.Index(p.src[p.pos:], ":]")
+			if end < 0 {
+				return nil, p.error(syntax.ErrMissingBracket, p.src[start:])
+			}
+			name := p.src[p.pos : p.pos+end]
+			p.pos += end + 2
+			negate := strings.HasPrefix(name, "^")
+			set, ok := posixClasses[strings.TrimPrefix(name, "^")]
+			if !ok {
+				return nil, p.error(errUnknownClass, "[:"+name+":]")
+			}
+			if negate {
+				set = notSet{set}
+			}
+			c.sets = append(c.sets, set)
+			continue
+		}
+
+		lo, set, err := p.parseClassRune()
+		if err != nil {
+			return nil, err
+		}
+		if set != nil {
+			c.sets = append(c.sets, set)
+			continue
+		}
+		if strings.HasPrefix(p.src[p.pos:], "-") && !strings.HasPrefix(p.src[p.pos:], "-]") {
+			p.next()
+			hi, set, err := p.parseClassRune()
+			if err != nil {
+				return nil, err
+			}
+			if set != nil || hi < lo {
+				return nil, p.error(syntax.ErrInvalidCharRange, p.src[start:p.pos])
+			}
+			c.ranges = append(c.ranges, runeRange{lo, hi})
+			continue
+		}
+		c.ranges = append(c.ranges, runeRange{lo, lo})
+	}
+}
+
+// parseClassRune parses a rune of a class, or an escape standing for a set
+// of runes such as \d.
+func (p *parser) parseClassRune() (rune, runeSet, error) {
+	r := p.next()
+	if r != '\\' {
+		return r, nil, nil
+	}
+	if p.eof() {
+		return 0, nil, p.error(syntax.ErrTrailingBackslash, "")
+	}
+	set, err := p.parseRuneEscape(true)
+	if err != nil {
+		return 0, nil, err
+	}
+	if l, ok := set.(*litRune); ok {
+		return l.r, nil, nil
+	}
+	return 0, set, nil
+}
+
+var posixClasses = map[string]runeSet{
+	"alnum":  runeFunc(func(r rune) bool { return isDigit(r) || isASCIILetter(r) }),
+	"alpha":  runeFunc(isASCIILetter),
+	"ascii":  runeFunc(func(r rune) bool { return r < utf8.RuneSelf }),
+	"blank":  runeFunc(func(r rune) bool { return r == ' ' || r == '\t' }),
+	"cntrl":  runeFunc(func(r rune) bool { return r < ' ' || r == 0x7f }),
+	"digit":  runeFunc(isDigit),
+	"graph":  runeFunc(func(r rune) bool { return '!' <= r && r <= '~' }),
+	"lower":  runeFunc(func(r rune) bool { return 'a' <= r && r <= 'z' }),
+	"print":  runeFunc(func(r rune) bool { return ' ' <= r && r <= '~' }),
+	"punct":  runeFunc(func(r rune) bool { return '!' <= r && r <= '~' && !isDigit(r) && !isASCIILetter(r) }),
+	"space":  runeFunc(func(r rune) bool { return r == ' ' || '\t' <= r && r <= '\r' }),
+	"upper":  runeFunc(func(r rune) bool { return 'A' <= r && r <= 'Z' }),
+	"word":   runeFunc(IsWordRune),
+	"xdigit": runeFunc(isHexDigit),
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isHexDigit(r rune) bool {
+	return isDigit(r) || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F'
+}
+
+func isASCIILetter(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+}
+
+func isHorizontalSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == 0xa0 || unicode.Is(unicode.Zs, r)
+}
+
+// IsWordRune reports whether r is a word constituent for \w and \b:
+// a letter, digit or underscore.
+func IsWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
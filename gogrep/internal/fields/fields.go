@@ -0,0 +1,204 @@
+// Package fields matches lines of structured logs by the values of their
+// fields: JSON objects, as written by log/slog's JSONHandler, and logfmt,
+// as written by its TextHandler.
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Format is the format of a line.
+type Format int
+
+const (
+	Text   Format = iota // not structured
+	JSON                 // a JSON object
+	Logfmt               // key=value pairs separated by spaces
+)
+
+// Record is the fields of a structured line.
+type Record struct {
+	Format Format
+	values map[string]any // JSON values (with json.Number for numbers), or logfmt strings
+}
+
+// Parse parses line as a JSON object or else as logfmt. A Text record is
+// returned if it's neither; a logfmt line consists of key=value pairs only.
+func Parse(line string) Record {
+	if values, ok := parseJSON(line); ok {
+		return Record{Format: JSON, values: values}
+	}
+	if values, ok := parseLogfmt(line); ok {
+		return Record{Format: Logfmt, values: values}
+	}
+	return Record{Format: Text}
+}
+
+func parseJSON(line string) (map[string]any, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return nil, false
+	}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	var values map[string]any
+	if err := dec.Decode(&values); err != nil || dec.InputOffset() != int64(len(line)) {
+		return nil, false
+	}
+	return values, true
+}
+
+func parseLogfmt(line string) (map[string]any, bool) {
+	values := make(map[string]any)
+	for i := 0; i < len(line); {
+		if isSpace(line[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != '"' && !isSpace(line[i]) {
+			i++
+		}
+		if i == start || i == len(line) || line[i] != '=' {
+			return nil, false
+		}
+		key := line[start:i]
+		i++
+
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, false
+			}
+			v, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, false
+			}
+			values[key] = v
+			i = end + 1
+		} else {
+			start := i
+			for i < len(line) && !isSpace(line[i]) {
+				i++
+			}
+			values[key] = line[start:i]
+		}
+		if i < len(line) && !isSpace(line[i]) {
+			return nil, false
+		}
+	}
+	return values, len(values) > 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// Lookup returns the value of the field key: a top-level field, or else a
+// field of nested JSON objects named by a dotted path, like "http.status".
+func (r Record) Lookup(key string) (any, bool) {
+	if v, ok := r.values[key]; ok || !strings.Contains(key, ".") {
+		return v, ok
+	}
+	var v any = r.values
+	for name := range strings.SplitSeq(key, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[name]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// String returns the text of a field value: strings as they are, other
+// JSON values in compact JSON.
+func String(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return string(v)
+	default:
+		return string(marshal(v))
+	}
+}
+
+// Number returns the numeric value of a field: JSON numbers, and strings
+// holding a number.
+func Number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Project returns the fields keys of line in the format of the line, in
+// the order of keys and leaving out missing ones. Lines that aren't
+// structured are returned as they are.
+func Project(line string, keys []string) string {
+	r := Parse(line)
+	var b strings.Builder
+	switch r.Format {
+	case JSON:
+		b.WriteByte('{')
+		for _, k := range keys {
+			if v, ok := r.Lookup(k); ok {
+				if b.Len() > 1 {
+					b.WriteByte(',')
+				}
+				b.Write(marshal(k))
+				b.WriteByte(':')
+				b.Write(marshal(v))
+			}
+		}
+		b.WriteByte('}')
+	case Logfmt:
+		for _, k := range keys {
+			if v, ok := r.Lookup(k); ok {
+				if b.Len() > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(k + "=" + quoteLogfmt(String(v)))
+			}
+		}
+	default:
+		return line
+	}
+	return b.String()
+}
+
+// marshal encodes v in compact JSON without escaping HTML characters.
+func marshal(v any) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil // decoded values are always encodable
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// quoteLogfmt quotes s if it would not be a single logfmt value otherwise.
+func quoteLogfmt(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t=\"\\") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
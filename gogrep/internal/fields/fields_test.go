@@ -0,0 +1,131 @@
+package fields
+
+import (
+	"testing"
+)
+
+const (
+	jsonLine   = `{"time":"2025-07-16T10:00:03Z","level":"ERROR","msg":"query failed","http":{"status":503,"path":"/api"},"took_ms":1250.5,"retry":true}`
+	logfmtLine = `time=2025-07-16T10:00:01Z level=INFO msg="request done" status=200 path=/api/users empty=`
+	textLine   = `2025-07-16 10:00:02 ERROR disk full on /dev/sda1`
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Format
+	}{
+		{jsonLine, JSON},
+		{"  {} ", JSON},
+		{logfmtLine, Logfmt},
+		{`a=1 b="x \"y\" z"`, Logfmt},
+		{textLine, Text},
+		{"", Text},
+		{`{"a":1} trailing`, Text},
+		{`[1,2]`, Text},
+		{`a=1 b`, Text},
+		{`a="unterminated`, Text},
+		{`a="x"b`, Text},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.line).Format; got != tt.want {
+			t.Errorf("Parse(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRecord_Lookup(t *testing.T) {
+	r := Parse(jsonLine)
+	for key, want := range map[string]string{
+		"level":       "ERROR",
+		"http.status": "503",
+		"http":        `{"path":"/api","status":503}`,
+		"took_ms":     "1250.5",
+		"retry":       "true",
+	} {
+		v, ok := r.Lookup(key)
+		if !ok || String(v) != want {
+			t.Errorf("Lookup(%q) = %v, %v, want %q", key, String(v), ok, want)
+		}
+	}
+	for _, key := range []string{"nope", "http.nope", "level.x", "http."} {
+		if v, ok := r.Lookup(key); ok {
+			t.Errorf("Lookup(%q) = %v, want none", key, v)
+		}
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	tests := []struct {
+		exprs      []string
+		ignoreCase bool
+		want       [3]bool // jsonLine, logfmtLine, textLine
+	}{
+		{[]string{"level=ERROR"}, false, [3]bool{true, false, true}},
+		{[]string{"level=error"}, false, [3]bool{false, false, false}},
+		{[]string{"level=error"}, true, [3]bool{true, false, true}},
+		{[]string{"level!=INFO"}, false, [3]bool{true, false, true}},
+		{[]string{"level=ERROR || status>=500"}, false, [3]bool{true, false, true}},
+		{[]string{"http.status>=500", "took_ms>1000"}, false, [3]bool{true, false, false}},
+		{[]string{"status<300"}, false, [3]bool{false, true, false}},
+		{[]string{"status=200.0"}, false, [3]bool{false, true, false}},
+		{[]string{"time>2025-07-16T10:00:02Z"}, false, [3]bool{true, false, false}},
+		{[]string{"msg~^(query|request) "}, false, [3]bool{true, true, false}},
+		{[]string{"msg!~fail"}, false, [3]bool{false, true, true}},
+		// "||" stays in the regexp, where its empty alternative matches anything
+		{[]string{"msg~^x||y$"}, false, [3]bool{true, true, true}},
+		{[]string{"level=WARN || msg~^x||y$"}, false, [3]bool{true, true, true}},
+		{[]string{"msg~^x||y$", "status=200"}, false, [3]bool{false, true, false}},
+		{[]string{"msg~`^request|x||y` || level=ERROR"}, false, [3]bool{true, true, true}},
+		{[]string{`msg="request done"`}, false, [3]bool{false, true, false}},
+		{[]string{"time>=2025-07-16"}, false, [3]bool{true, true, false}},
+		{[]string{"path~^/api$"}, false, [3]bool{false, false, false}},
+		{[]string{"http.path~^/api$"}, false, [3]bool{true, false, false}},
+		{[]string{"retry"}, false, [3]bool{true, false, false}},
+		{[]string{"!retry"}, false, [3]bool{false, true, true}},
+		{[]string{"empty="}, false, [3]bool{false, true, true}},
+		{[]string{"disk"}, false, [3]bool{false, false, true}},
+	}
+	lines := [3]string{jsonLine, logfmtLine, textLine}
+	for _, tt := range tests {
+		q, err := Compile(tt.exprs, tt.ignoreCase)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.exprs, err)
+		}
+		for i, line := range lines {
+			if got := q.Match(line); got != tt.want[i] {
+				t.Errorf("%q (ignore case %v) on %.30q: got %v, want %v", tt.exprs, tt.ignoreCase, line, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"", "!", "=x", "a!b", "a~(", "a=1 || ",
+		"latency>abc", "latency<=1ms", "a>=1 || b<x", `a="x`, "a=`x`y",
+	} {
+		if _, err := Compile([]string{expr}, false); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		line string
+		keys []string
+		want string
+	}{
+		{jsonLine, []string{"level", "nope", "http.status", "msg"}, `{"level":"ERROR","http.status":503,"msg":"query failed"}`},
+		{jsonLine, []string{"nope"}, `{}`},
+		{`{"msg":"a<b & c"}`, []string{"msg"}, `{"msg":"a<b & c"}`},
+		{logfmtLine, []string{"msg", "status", "empty"}, `msg="request done" status=200 empty=""`},
+		{textLine, []string{"level"}, textLine},
+	}
+	for _, tt := range tests {
+		if got := Project(tt.line, tt.keys); got != tt.want {
+			t.Errorf("Project(%.30q, %q) = %q, want %q", tt.line, tt.keys, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package fields
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// op is the comparison of a predicate.
+type op int
+
+const (
+	opExists  op = iota // key
+	opAbsent            // !key
+	opEq                // key=value
+	opNe                // key!=value
+	opMatch             // key~regexp
+	opNoMatch           // key!~regexp
+	opLt                // key<value
+	opLe                // key<=value
+	opGt                // key>value
+	opGe                // key>=value
+)
+
+// operators are tried in order, so two-character ones come first.
+var operators = []struct {
+	text string
+	op   op
+}{
+	{"!=", opNe}, {"!~", opNoMatch}, {"<=", opLe}, {">=", opGe},
+	{"=", opEq}, {"~", opMatch}, {"<", opLt}, {">", opGt},
+}
+
+// predicate is a condition on a field.
+type predicate struct {
+	key   string
+	op    op
+	value string
+	re    *regexp.Regexp // the regexp of opMatch and opNoMatch
+	text  *regexp.Regexp // matches lines that aren't structured; nil for comparisons
+}
+
+// Query is a condition on the fields of lines: a conjunction of
+// disjunctions of predicates.
+type Query struct {
+	clauses    [][]predicate
+	ignoreCase bool
+}
+
+// Compile compiles a query: a line matches if every expression holds. An
+// expression is a predicate, or several ones separated by "||" of which one
+// must hold. The regexp of ~ and !~ runs to the end of the expression, so
+// it may contain "||"; values can be quoted as Go strings ("a||b" or
+// `a||b`) to end them earlier:
+//
+//	key          the field exists
+//	!key         the field doesn't exist
+//	key=value    the field equals value (as text, or as a number)
+//	key!=value   the field doesn't equal value, or doesn't exist
+//	key~regexp   the field matches the regular expression
+//	key!~regexp  the field doesn't match it, or doesn't exist
+//	key<value    the field is less than value, which must be a number or
+//	             a timestamp; as numbers if both are, else as text, which
+//	             orders timestamps; also <=, > and >=
+//
+// With ignoreCase, equality and regular expressions ignore case.
+func Compile(exprs []string, ignoreCase bool) (*Query, error) {
+	q := &Query{ignoreCase: ignoreCase}
+	for _, expr := range exprs {
+		var clause []predicate
+		for rest, more := expr, true; more; {
+			var alt string
+			alt, rest, more = cutAlternative(rest)
+			p, err := parsePredicate(strings.TrimSpace(alt), ignoreCase)
+			if err != nil {
+				return nil, fmt.Errorf("invalid field predicate %q: %w", alt, err)
+			}
+			clause = append(clause, p)
+		}
+		q.clauses = append(q.clauses, clause)
+	}
+	return q, nil
+}
+
+// cutAlternative cuts the first predicate of s at a "||" that follows it.
+// The "||" isn't looked for inside a quoted value or the regexp of ~ and
+// !~, which runs to the end of s. more reports whether a "||" was found.
+func cutAlternative(s string) (alt, rest string, more bool) {
+	start := len(s) - len(strings.TrimLeft(s, " \t"))
+	if strings.HasPrefix(s[start:], "!") && !strings.HasPrefix(s[start:], "!=") && !strings.HasPrefix(s[start:], "!~") {
+		start++ // !key
+	}
+	valueStart := start
+	if i := strings.IndexAny(s[start:], "=!<>~"); i >= 0 {
+		i += start
+		for _, o := range operators {
+			if !strings.HasPrefix(s[i:], o.text) {
+				continue
+			}
+			valueStart = i + len(o.text)
+			value := strings.TrimLeft(s[valueStart:], " \t")
+			if quoted, err := strconv.QuotedPrefix(value); err == nil {
+				valueStart = len(s) - len(value) + len(quoted)
+			} else if o.op == opMatch || o.op == opNoMatch {
+				return s, "", false
+			}
+			break
+		}
+	}
+	if j := strings.Index(s[valueStart:], "||"); j >= 0 {
+		j += valueStart
+		return s[:j], s[j+2:], true
+	}
+	return s, "", false
+}
+
+func parsePredicate(expr string, ignoreCase bool) (predicate, error) {
+	i := strings.IndexAny(expr, "=!<>~")
+	if i < 0 || i == 0 && expr[0] == '!' && !strings.ContainsAny(expr[1:], "=!<>~") {
+		p := predicate{key: expr, op: opExists}
+		if strings.HasPrefix(expr, "!") {
+			p.key, p.op = expr[1:], opAbsent
+		}
+		if p.key == "" {
+			return predicate{}, errors.New("missing field name")
+		}
+		p.text = literal(p.key, ignoreCase)
+		return p, nil
+	}
+
+	p := predicate{key: strings.TrimSpace(expr[:i])}
+	if p.key == "" {
+		return predicate{}, errors.New("missing field name")
+	}
+	found := false
+	for _, o := range operators {
+		if strings.HasPrefix(expr[i:], o.text) {
+			p.op, p.value, found = o.op, strings.TrimSpace(expr[i+len(o.text):]), true
+			break
+		}
+	}
+	if !found {
+		return predicate{}, fmt.Errorf("unknown operator at %q", expr[i:])
+	}
+	if strings.HasPrefix(p.value, `"`) || strings.HasPrefix(p.value, "`") {
+		value, err := strconv.Unquote(p.value)
+		if err != nil {
+			return predicate{}, fmt.Errorf("invalid quoted value %s", p.value)
+		}
+		p.value = value
+	}
+
+	switch p.op {
+	case opEq, opNe:
+		p.text = literal(p.value, ignoreCase)
+	case opMatch, opNoMatch:
+		pattern := p.value
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return predicate{}, err
+		}
+		p.re, p.text = re, re
+	case opLt, opLe, opGt, opGe:
+		if _, ok := Number(p.value); !ok && !isTimestamp(p.value) {
+			return predicate{}, fmt.Errorf("%q is neither a number nor a timestamp", p.value)
+		}
+	}
+	return p, nil
+}
+
+// timestampLayouts are the layouts of the timestamps that comparisons take.
+var timestampLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly, "2006-01-02T15:04:05"}
+
+// isTimestamp reports whether s is a date or a time in one of timestampLayouts.
+func isTimestamp(s string) bool {
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// literal returns a regexp matching s.
+func literal(s string, ignoreCase bool) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(s)
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// Match reports whether line satisfies the query. Lines that are neither
+// JSON nor logfmt are matched as text: a predicate holds if the line
+// contains its value (or matches its regexp, or contains the key for
+// existence); comparisons don't hold.
+func (q *Query) Match(line string) bool {
+	r := Parse(line)
+	for _, clause := range q.clauses {
+		ok := false
+		for _, p := range clause {
+			if ok = q.eval(p, r, line); ok {
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *Query) eval(p predicate, r Record, line string) bool {
+	if r.Format == Text {
+		switch p.op {
+		case opExists, opEq, opMatch:
+			return p.text.MatchString(line)
+		case opAbsent, opNe, opNoMatch:
+			return !p.text.MatchString(line)
+		}
+		return false
+	}
+
+	v, found := r.Lookup(p.key)
+	switch p.op {
+	case opExists:
+		return found
+	case opAbsent:
+		return !found
+	case opEq:
+		return found && q.equal(v, p.value)
+	case opNe:
+		return !found || !q.equal(v, p.value)
+	case opMatch:
+		return found && p.re.MatchString(String(v))
+	case opNoMatch:
+		return !found || !p.re.MatchString(String(v))
+	}
+	if !found {
+		return false
+	}
+	c := compare(v, p.value)
+	switch p.op {
+	case opLt:
+		return c < 0
+	case opLe:
+		return c <= 0
+	case opGt:
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// equal reports whether the field value v equals value as text, or as a
+// number: 200 equals "200.0".
+func (q *Query) equal(v any, value string) bool {
+	s := String(v)
+	if s == value || q.ignoreCase && strings.EqualFold(s, value) {
+		return true
+	}
+	a, aok := Number(v)
+	b, bok := Number(value)
+	return aok && bok && a == b
+}
+
+// compare compares the field value v with value: as numbers if both are
+// numbers, else as text, which orders timestamps like 2025-07-16T10:00:00Z.
+func compare(v any, value string) int {
+	a, aok := Number(v)
+	b, bok := Number(value)
+	if aok && bok {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(String(v), value)
+}
@@ -0,0 +1,113 @@
+// Package follow reads a file as it grows, like tail -F: at its end, it
+// waits for more data, starts over when the file is truncated and reopens
+// it when it is replaced by another file, as log rotation does.
+package follow
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultInterval is how often a file is checked for new data at its end.
+const DefaultInterval = 250 * time.Millisecond
+
+// Reader reads a followed file. Its reads block at the end of the file
+// until there is more data, or until its context is done.
+type Reader struct {
+	ctx      context.Context
+	path     string
+	interval time.Duration
+
+	f      *os.File
+	offset int64    // of the next read in f
+	next   *os.File // the file that replaced f, read once f is read to its end
+}
+
+// Open opens the file path to follow it until ctx is done, checking it for
+// changes every interval (DefaultInterval if zero). Reading starts at the
+// beginning of the file.
+func Open(ctx context.Context, path string, interval time.Duration) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Reader{ctx: ctx, path: path, interval: interval, f: f}, nil
+}
+
+// Read reads the next data of the file, waiting for it at the end. It
+// returns the error of the context once it's done.
+func (r *Reader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.f.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		moved, err := r.check()
+		if err != nil {
+			return 0, err
+		}
+		if moved {
+			continue
+		}
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// check looks for a truncation or a replacement of the file once it has
+// been read to its end, and reports whether to read on at once.
+func (r *Reader) check() (bool, error) {
+	if r.next != nil {
+		// the replaced file is read to its end: go on with the new one
+		r.f.Close()
+		r.f, r.offset, r.next = r.next, 0, nil
+		return true, nil
+	}
+
+	info, err := r.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < r.offset {
+		// truncated, as by copytruncate rotation
+		if _, err := r.f.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		r.offset = 0
+		return true, nil
+	}
+
+	current, err := os.Stat(r.path)
+	if err != nil || os.SameFile(info, current) {
+		// unchanged, or renamed and not recreated yet
+		return false, nil
+	}
+	next, err := os.Open(r.path)
+	if err != nil {
+		return false, nil // try again later
+	}
+	// read what was written to the old file since, before switching
+	r.next = next
+	return true, nil
+}
+
+// Close closes the file.
+func (r *Reader) Close() error {
+	if r.next != nil {
+		r.next.Close()
+	}
+	return r.f.Close()
+}
@@ -0,0 +1,105 @@
+package follow
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// lines reads the lines of r in the background.
+func lines(r *Reader) (<-chan string, <-chan error) {
+	out, done := make(chan string), make(chan error, 1)
+	go func() {
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				done <- err
+				return
+			}
+			out <- line
+		}
+	}()
+	return out, done
+}
+
+func expect(t *testing.T, got <-chan string, want string) {
+	t.Helper()
+	select {
+	case line := <-got:
+		if line != want {
+			t.Fatalf("got %q, want %q", line, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no line, want %q", want)
+	}
+}
+
+func appendTo(t *testing.T, path, s string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendTo(t, path, "first\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := Open(ctx, path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	got, done := lines(r)
+	expect(t, got, "first\n")
+
+	// growth
+	appendTo(t, path, "second\n")
+	expect(t, got, "second\n")
+
+	// rotation by rename: the rest of the old file, then the new one
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(t, path+".1", "old\n")
+	appendTo(t, path, "new\n")
+	expect(t, got, "old\n")
+	expect(t, got, "new\n")
+
+	// truncation
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond) // let the reader notice the smaller size
+	appendTo(t, path, "again\n")
+	expect(t, got, "again\n")
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reader didn't stop")
+	}
+}
+
+func TestOpen_Missing(t *testing.T) {
+	if _, err := Open(context.Background(), filepath.Join(t.TempDir(), "nope"), 0); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want %v", err, os.ErrNotExist)
+	}
+}
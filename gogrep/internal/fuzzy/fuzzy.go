@@ -0,0 +1,252 @@
+// Package fuzzy finds approximate occurrences of a string: substrings within
+// a Levenshtein distance (insertions, deletions and substitutions of runes)
+// of it, with the bit-parallel algorithm of Myers.
+package fuzzy
+
+import (
+	"math/bits"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match is an approximate occurrence: the byte range [Start, End) and its
+// edit distance to the pattern.
+type Match struct {
+	Start, End int
+	Distance   int
+}
+
+// Matcher finds the substrings within a maximum distance of a pattern. It is
+// safe for concurrent use.
+type Matcher struct {
+	fwd, rev   *bitPattern // the pattern and the reversed pattern
+	n          int         // length of the pattern in runes
+	k          int         // maximum distance
+	ignoreCase bool
+}
+
+// New returns a Matcher for the occurrences of pattern within distance k.
+// With ignoreCase, runes are compared under simple Unicode case folding.
+func New(pattern string, k int, ignoreCase bool) *Matcher {
+	runes := []rune(pattern)
+	if ignoreCase {
+		for i, r := range runes {
+			runes[i] = fold(r)
+		}
+	}
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return &Matcher{
+		fwd:        newBitPattern(runes),
+		rev:        newBitPattern(reversed),
+		n:          len(runes),
+		k:          max(k, 0),
+		ignoreCase: ignoreCase,
+	}
+}
+
+// MatchString reports whether s contains an approximate occurrence.
+func (m *Matcher) MatchString(s string) bool {
+	_, _, ok := m.end(s, 0)
+	return ok
+}
+
+// Distance returns the edit distance between s and the pattern.
+func (m *Matcher) Distance(s string) int {
+	st := m.fwd.newState()
+	dist := m.n
+	for _, r := range s {
+		dist = st.step(m.fwd.eq(m.fold(r)), 1)
+	}
+	return dist
+}
+
+// FindAll returns the successive non-overlapping approximate occurrences in
+// s. Each one ends where the distance drops within the maximum and stops
+// decreasing, and starts where the distance of the substring is the lowest,
+// the nearest such position if there are several.
+func (m *Matcher) FindAll(s string) []Match {
+	if m.n <= m.k {
+		// the empty string is close enough: every line matches
+		return []Match{{0, 0, m.n}}
+	}
+	var matches []Match
+	for from := 0; from < len(s); {
+		end, _, ok := m.end(s, from)
+		if !ok {
+			break
+		}
+		start, dist := m.start(s, from, end)
+		matches = append(matches, Match{start, end, dist})
+		from = end
+	}
+	return matches
+}
+
+// end scans s from the byte offset from for the end of the first occurrence
+// and returns it with its distance.
+func (m *Matcher) end(s string, from int) (end, dist int, ok bool) {
+	if m.n <= m.k {
+		return from, m.n, true
+	}
+	st := m.fwd.newState()
+	for i := from; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		dist := st.step(m.fwd.eq(m.fold(r)), 0)
+		if dist > m.k {
+			continue
+		}
+		// as long as the distance decreases, the occurrence grows
+		for i < len(s) {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			next := st.step(m.fwd.eq(m.fold(r)), 0)
+			if next >= dist {
+				break
+			}
+			dist = next
+			i += size
+		}
+		return i, dist, true
+	}
+	return 0, 0, false
+}
+
+// start scans s backwards from end, but not before from, for the start of
+// the occurrence ending at end: the reversed pattern is aligned with the
+// whole scanned text, which gives the distance of every substring ending at
+// end.
+func (m *Matcher) start(s string, from, end int) (start, dist int) {
+	st := m.rev.newState()
+	start, dist = end, m.n
+	for i, runes := end, 0; i > from && runes < m.n+m.k; runes++ {
+		r, size := utf8.DecodeLastRuneInString(s[from:i])
+		i -= size
+		if d := st.step(m.rev.eq(m.fold(r)), 1); d < dist {
+			start, dist = i, d
+		}
+	}
+	return start, dist
+}
+
+func (m *Matcher) fold(r rune) rune {
+	if !m.ignoreCase {
+		return r
+	}
+	return fold(r)
+}
+
+// fold returns the smallest rune of the case folding orbit of r, which is
+// the upper case letter for ASCII.
+func fold(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		return r
+	}
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		smallest = min(smallest, f)
+	}
+	return smallest
+}
+
+// bitPattern holds the match vectors of a pattern: bit i of the vector of a
+// rune is set if the pattern has that rune at index i. Patterns longer than
+// 64 runes take several words per vector.
+type bitPattern struct {
+	ascii [utf8.RuneSelf][]uint64
+	other map[rune][]uint64
+	none  []uint64 // the vector of runes not in the pattern
+	n     int
+}
+
+func newBitPattern(runes []rune) *bitPattern {
+	words := max((len(runes)+63)/64, 1)
+	p := &bitPattern{other: map[rune][]uint64{}, none: make([]uint64, words), n: len(runes)}
+	for i, r := range runes {
+		v := p.vector(r)
+		if v == nil {
+			v = make([]uint64, words)
+			if r < utf8.RuneSelf {
+				p.ascii[r] = v
+			} else {
+				p.other[r] = v
+			}
+		}
+		v[i/64] |= 1 << (i % 64)
+	}
+	return p
+}
+
+// eq returns the match vector of r.
+func (p *bitPattern) eq(r rune) []uint64 {
+	if v := p.vector(r); v != nil {
+		return v
+	}
+	return p.none
+}
+
+// vector returns the match vector of r, or nil if r isn't in the pattern.
+func (p *bitPattern) vector(r rune) []uint64 {
+	if 0 <= r && r < utf8.RuneSelf {
+		return p.ascii[r]
+	}
+	return p.other[r]
+}
+
+// state is the column of the dynamic programming matrix of the distances
+// between the prefixes of the pattern and the text, encoded as the vertical
+// differences between adjacent cells: bit i of pv (mv) is set if the
+// distance increases (decreases) from row i to row i+1.
+type state struct {
+	pv, mv []uint64
+	last   uint64 // the bit of the last row in the last word
+	score  int    // distance of the whole pattern
+}
+
+func (p *bitPattern) newState() *state {
+	st := &state{pv: make([]uint64, len(p.none)), mv: make([]uint64, len(p.none)), score: p.n}
+	for i := range st.pv {
+		st.pv[i] = ^uint64(0)
+	}
+	if p.n > 0 {
+		st.last = 1 << ((p.n - 1) % 64)
+	}
+	return st
+}
+
+// step advances the column by a text rune with match vector eq and returns
+// the distance of the pattern. carry is the difference between the top
+// cells of adjacent columns: 0 to search for the pattern anywhere in the
+// text, 1 to align it with the whole text.
+func (st *state) step(eq []uint64, carry uint64) int {
+	phIn, mhIn, addCarry := carry, uint64(0), uint64(0)
+	lastWord := len(st.pv) - 1
+	for i := range st.pv {
+		pv, mv, e := st.pv[i], st.mv[i], eq[i]
+		xv := e | mv
+		sum, c := bits.Add64(e&pv, pv, addCarry)
+		addCarry = c
+		xh := (sum ^ pv) | e
+		ph := mv | ^(xh | pv)
+		mh := pv & xh
+		if i == lastWord && st.last != 0 {
+			if ph&st.last != 0 {
+				st.score++
+			} else if mh&st.last != 0 {
+				st.score--
+			}
+		}
+		phOut, mhOut := ph>>63, mh>>63
+		ph = ph<<1 | phIn
+		mh = mh<<1 | mhIn
+		phIn, mhIn = phOut, mhOut
+		st.pv[i] = mh | ^(xv | ph)
+		st.mv[i] = ph & xv
+	}
+	return st.score
+}
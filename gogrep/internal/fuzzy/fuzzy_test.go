@@ -0,0 +1,138 @@
+package fuzzy
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		k          int
+		ignoreCase bool
+		s          string
+		want       []Match
+	}{
+		{"connection", 1, false, "lost conection to db", []Match{{5, 14, 1}}},
+		{"connection", 2, false, "lost connetcion to db", []Match{{5, 15, 2}}},
+		{"connection", 1, false, "lost connetcion to db", nil},
+		{"hello", 1, false, "hello", []Match{{0, 5, 0}}},
+		{"hello", 1, false, "xhellox", []Match{{1, 6, 0}}},
+		{"abc", 1, false, "abxc abc ab", []Match{{0, 2, 1}, {5, 8, 0}, {9, 11, 1}}},
+		{"timeout", 1, true, "request TIMEOUT", []Match{{8, 15, 0}}},
+		{"straße", 1, false, "strase", []Match{{0, 6, 1}}},
+		{"ab", 2, false, "xyz", []Match{{0, 0, 2}}},
+		{"", 0, false, "abc", []Match{{0, 0, 0}}},
+	}
+	for _, tt := range tests {
+		got := New(tt.pattern, tt.k, tt.ignoreCase).FindAll(tt.s)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q within %d in %q: got %v, want %v", tt.pattern, tt.k, tt.s, got, tt.want)
+		}
+	}
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		cur[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			cur[j+1] = min(prev[j+1]+1, cur[j]+1, prev[j]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// bestDistance is the lowest distance between a and a substring of s: the
+// distance matrix with a free start anywhere in s.
+func bestDistance(a, s []rune) int {
+	col := make([]int, len(a)+1)
+	for i := range col {
+		col[i] = i
+	}
+	best := col[len(a)]
+	for j := range s {
+		diag := col[0]
+		col[0] = 0
+		for i := range a {
+			cost := 1
+			if a[i] == s[j] {
+				cost = 0
+			}
+			diag, col[i+1] = col[i+1], min(col[i+1]+1, col[i]+1, diag+cost)
+		}
+		best = min(best, col[len(a)])
+	}
+	return best
+}
+
+// TestAgainstDynamicProgramming checks the results on random strings,
+// with patterns that take one and several words.
+func TestAgainstDynamicProgramming(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	randString := func(n int) string {
+		var b strings.Builder
+		for range n {
+			b.WriteRune([]rune("abcé")[rnd.IntN(4)])
+		}
+		return b.String()
+	}
+	for range 2000 {
+		n := 1 + rnd.IntN(8)
+		if rnd.IntN(10) == 0 {
+			n = 60 + rnd.IntN(80)
+		}
+		pattern := randString(n)
+		s := randString(rnd.IntN(2 * n))
+		if rnd.IntN(2) == 0 {
+			s += pattern[:len(pattern)/2] + randString(2) + pattern[len(pattern)/2:]
+		}
+		k := rnd.IntN(n)
+		m := New(pattern, k, false)
+
+		want := bestDistance([]rune(pattern), []rune(s)) <= k
+		if got := m.MatchString(s); got != want {
+			t.Fatalf("MatchString(%q) within %d of %q = %v, want %v", s, k, pattern, got, want)
+		}
+		matches := m.FindAll(s)
+		if len(matches) > 0 != want {
+			t.Fatalf("FindAll(%q) within %d of %q = %v, want matches: %v", s, k, pattern, matches, want)
+		}
+		if got, want := m.Distance(s), distance([]rune(pattern), []rune(s)); got != want {
+			t.Fatalf("Distance(%q, %q) = %d, want %d", s, pattern, got, want)
+		}
+		prevEnd := 0
+		for _, match := range matches {
+			d := distance([]rune(pattern), []rune(s[match.Start:match.End]))
+			if match.Start < prevEnd || d != match.Distance || d > k {
+				t.Fatalf("FindAll(%q) within %d of %q: %v has distance %d", s, k, pattern, match, d)
+			}
+			prevEnd = match.End
+		}
+	}
+}
+
+func BenchmarkMatchString(b *testing.B) {
+	line := strings.Repeat("2025-07-16T10:00:00Z INFO request id=1234 path=/api/v1/users status=200 ", 3)
+	for _, pattern := range []string{"conection refused", strings.Repeat("x", 100)} {
+		m := New(pattern, 2, true)
+		b.Run(pattern[:10], func(b *testing.B) {
+			b.SetBytes(int64(len(line)))
+			for b.Loop() {
+				m.MatchString(line)
+			}
+		})
+	}
+}
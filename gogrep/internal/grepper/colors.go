@@ -0,0 +1,76 @@
+package grepper
+
+import "strings"
+
+// Colors are the SGR sequences (e.g. "01;31") used to highlight output,
+// named as in GNU grep's GREP_COLORS. Empty values disable the element.
+type Colors struct {
+	SelectedMatch string // ms: matching text in selected lines
+	ContextMatch  string // mc: matching text in context lines
+	SelectedLine  string // sl: whole selected lines
+	ContextLine   string // cx: whole context lines
+	FileName      string // fn: file name prefixes
+	LineNumber    string // ln: line number prefixes
+	ByteOffset    string // bn: byte offset prefixes
+	Separator     string // se: separators between prefixes and the line, and between groups
+	NoErase       bool   // ne: don't append "erase in line" to the sequences
+}
+
+// DefaultColors are GNU grep's default colors.
+var DefaultColors = Colors{
+	SelectedMatch: "01;31",
+	ContextMatch:  "01;31",
+	FileName:      "35",
+	LineNumber:    "32",
+	ByteOffset:    "32",
+	Separator:     "36",
+}
+
+// ParseColors applies a GREP_COLORS specification such as
+// "ms=01;32:fn=34:ne" on top of DefaultColors. Unknown capabilities
+// are ignored, like GNU grep does.
+func ParseColors(spec string) Colors {
+	c := DefaultColors
+	for _, item := range strings.Split(spec, ":") {
+		name, value, _ := strings.Cut(item, "=")
+		switch name {
+		case "mt":
+			c.SelectedMatch, c.ContextMatch = value, value
+		case "ms":
+			c.SelectedMatch = value
+		case "mc":
+			c.ContextMatch = value
+		case "sl":
+			c.SelectedLine = value
+		case "cx":
+			c.ContextLine = value
+		case "fn":
+			c.FileName = value
+		case "ln":
+			c.LineNumber = value
+		case "bn":
+			c.ByteOffset = value
+		case "se":
+			c.Separator = value
+		case "ne":
+			c.NoErase = true
+		}
+	}
+	return c
+}
+
+// start returns the sequence switching to sgr.
+func (c *Colors) start(sgr string) string {
+	if c.NoErase {
+		return "\x1b[" + sgr + "m"
+	}
+	return "\x1b[" + sgr + "m\x1b[K"
+}
+
+// end returns the sequence resetting the attributes.
+func (c *Colors) end() string {
+	if c.NoErase {
+		return "\x1b[m"
+	}
+	return "\x1b[m\x1b[K"
+}
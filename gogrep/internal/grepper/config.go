@@ -2,6 +2,29 @@ package grepper
 
 import (
 	"errors"
+	"fmt"
+
+	"grep/search"
+	"lineio"
+)
+
+// BinaryMode selects how files that look binary (contain NUL bytes) are searched.
+type BinaryMode string
+
+const (
+	BinaryDefault      BinaryMode = "binary"        // report "Binary file NAME matches" instead of the lines
+	BinaryText         BinaryMode = "text"          // -a: search and print binary files as text
+	BinaryWithoutMatch BinaryMode = "without-match" // -I: assume binary files never match
+)
+
+// Syntax selects the syntax of regular expression patterns.
+type Syntax string
+
+const (
+	SyntaxGo       Syntax = ""         // Go's regexp (RE2) syntax, the default
+	SyntaxBasic    Syntax = "basic"    // -G: POSIX basic regular expressions with GNU extensions
+	SyntaxExtended Syntax = "extended" // -E: POSIX extended regular expressions with GNU extensions
+	SyntaxPerl     Syntax = "perl"     // -P: Perl-compatible regular expressions, with lookaround and backreferences
 )
 
 type Config struct {
@@ -9,11 +32,41 @@ type Config struct {
 	Before  int // -B N: print N lines of leading context before each matching line
 	Context int // -C N: print N lines of context around each matching line (sets both -A and -B unless explicitly overridden)
 
+	GroupSeparator   *string // --group-separator=SEP: line printed between non-adjacent groups of context; nil means "--"
+	NoGroupSeparator bool    // --no-group-separator: print nothing between groups
+
 	CountOnly  bool // -c: print only the count of matching lines instead of the lines themselves
 	IgnoreCase bool // -i: ignore case distinctions when matching
 	Invert     bool // -v: invert the match, selecting non-matching lines
 	Fixed      bool // -F: interpret the pattern as a fixed string instead of a regular expression
+	Fuzzy      int  // --fuzzy=K: match fixed strings within Levenshtein distance K; 0 means exact matching
 	WithLineNo bool // -n: prefix each output line with its line number
+	WordRegexp bool // -w: select only matches that form whole words
+	LineRegexp bool // -x: select only matches that span the whole line
+
+	Syntax Syntax // -G/-E/-P: syntax of the patterns when they are regular expressions
+
+	Query      string   // --query: a boolean combination of strings and /regexps/ that lines must satisfy, besides the patterns
+	FieldQuery []string // --jq-field: predicates on the fields of JSON or logfmt lines that must all hold, besides the patterns
+	Project    []string // --jq-project: print only these fields of JSON and logfmt lines
+
+	Quiet    bool // -q: print nothing and stop at the first selected line
+	MaxCount int  // -m NUM: stop reading after NUM selected lines, printing their trailing context; 0 means no limit
+
+	OnlyMatching bool    // -o: print only the matched parts of selected lines, each on its own line
+	ByteOffset   bool    // -b: prefix each output line with the byte offset of the line (or match, with -o)
+	Column       bool    // --column: prefix selected lines with the 1-based column of the first match
+	Colors       *Colors // --color: highlight matches and prefixes; nil disables colors
+	JSON         bool    // --json: write JSON lines records instead of text
+	Replace      *string // --replace=TEMPLATE: print selected lines with their matches replaced by TEMPLATE, expanding $1, ${name}
+
+	WithFilename      bool       // -H/-h: prefix each output line with the file name (default when searching several files)
+	FilesWithMatches  bool       // -l: print only the names of files with matching lines
+	FilesWithoutMatch bool       // -L: print only the names of files without matching lines
+	Binary            BinaryMode // --binary-files, -a, -I: how to treat binary files; empty means BinaryDefault
+
+	Encoding Encoding // --encoding: character encoding of the input; empty means UTF-8 with UTF-16 byte order mark detection
+	NullData bool     // --null-data: input and output lines are terminated by NUL instead of newline
 }
 
 // Validate checks the configuration for invalid or conflicting options.
@@ -22,5 +75,98 @@ func (c *Config) Validate() error {
 	if c.Context < 0 || c.After < 0 || c.Before < 0 {
 		return errors.New("invalid arguments: context, before, and after must be non-negative")
 	}
+	if c.MaxCount < 0 {
+		return errors.New("invalid arguments: max count must be non-negative")
+	}
+	switch c.Syntax {
+	case SyntaxGo, SyntaxBasic, SyntaxExtended, SyntaxPerl:
+	default:
+		return fmt.Errorf("invalid arguments: unknown pattern syntax %q", c.Syntax)
+	}
+	if c.Fuzzy < 0 {
+		return errors.New("invalid arguments: fuzzy distance must be non-negative")
+	}
+	if (c.Fixed || c.Fuzzy > 0) && c.Syntax != SyntaxGo {
+		return errors.New("invalid arguments: conflicting matchers specified")
+	}
+	if c.WordRegexp && c.LineRegexp {
+		return errors.New("invalid arguments: -w and -x are mutually exclusive")
+	}
+	if c.FilesWithMatches && c.FilesWithoutMatch {
+		return errors.New("invalid arguments: -l and -L are mutually exclusive")
+	}
+	switch c.Binary {
+	case "", BinaryDefault, BinaryText, BinaryWithoutMatch:
+	default:
+		return fmt.Errorf("invalid arguments: unknown binary files type %q", c.Binary)
+	}
+	switch c.Encoding {
+	case EncodingAuto, EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE, EncodingLatin1:
+	default:
+		return fmt.Errorf("invalid arguments: unknown encoding %q", c.Encoding)
+	}
 	return nil
 }
+
+// ContextLines returns the numbers of leading and trailing context lines:
+// Context applies to the sides for which Before or After isn't set, so that
+// explicit -A/-B override -C, as in grep.
+func (c *Config) ContextLines() (before, after int) {
+	before, after = c.Before, c.After
+	if before == 0 {
+		before = c.Context
+	}
+	if after == 0 {
+		after = c.Context
+	}
+	if c.CountOnly || c.FilesWithMatches || c.FilesWithoutMatch || c.Quiet {
+		return 0, 0 // no lines are printed
+	}
+	return before, after
+}
+
+// searchOptions returns the options of the search of inputs. -q, -l and
+// -L only need the first selected line, -c their count.
+func (c *Config) searchOptions() search.Options {
+	before, after := c.ContextLines()
+	opts := search.Options{
+		Invert:    c.Invert,
+		MaxCount:  c.MaxCount,
+		Before:    before,
+		After:     after,
+		CountOnly: c.CountOnly,
+		NullData:  c.NullData,
+	}
+	if c.Quiet || c.FilesWithMatches || c.FilesWithoutMatch {
+		opts.CountOnly, opts.MaxCount = true, 1
+	}
+	switch {
+	case c.Binary == BinaryText:
+		opts.Binary = search.BinaryText
+	case c.Binary == BinaryWithoutMatch:
+		opts.Binary = search.BinaryWithoutMatch
+	}
+	return opts
+}
+
+// delim returns the byte that terminates lines.
+func (c *Config) delim() byte {
+	if c.NullData {
+		return lineio.NUL
+	}
+	return lineio.Newline
+}
+
+// groupSeparator returns the separator printed between groups of context
+// lines and whether one is printed at all.
+func (c *Config) groupSeparator() (string, bool) {
+	before, after := c.ContextLines()
+	switch {
+	case before == 0 && after == 0 || c.NoGroupSeparator || c.JSON:
+		return "", false
+	case c.GroupSeparator != nil:
+		return *c.GroupSeparator, true
+	default:
+		return "--", true
+	}
+}
@@ -0,0 +1,60 @@
+package grepper
+
+import (
+	"bufio"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encoding is the character encoding of the input. Input in another
+// encoding than UTF-8 is transcoded to UTF-8 before it is searched; the
+// output, and the byte offsets of -b, are those of the transcoded text.
+type Encoding string
+
+const (
+	EncodingAuto    Encoding = ""         // UTF-8, or UTF-16 if the input starts with its byte order mark
+	EncodingUTF8    Encoding = "utf-8"    // UTF-8 (or any other bytes), searched as is
+	EncodingUTF16LE Encoding = "utf-16le" // UTF-16, little-endian unless a byte order mark says otherwise
+	EncodingUTF16BE Encoding = "utf-16be" // UTF-16, big-endian unless a byte order mark says otherwise
+	EncodingLatin1  Encoding = "latin1"   // ISO-8859-1
+)
+
+// decoder returns the decoder for input in encoding enc that starts with
+// head, or nil if the input is searched as is.
+func decoder(enc Encoding, head []byte) *encoding.Decoder {
+	if enc == EncodingAuto && len(head) >= 2 {
+		switch {
+		case head[0] == 0xFF && head[1] == 0xFE:
+			enc = EncodingUTF16LE
+		case head[0] == 0xFE && head[1] == 0xFF:
+			enc = EncodingUTF16BE
+		}
+	}
+	switch enc {
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()
+	case EncodingLatin1:
+		return charmap.ISO8859_1.NewDecoder()
+	}
+	return nil
+}
+
+// decode returns a reader of the input r transcoded to UTF-8. The byte
+// order mark of UTF-16 is dropped; that of UTF-8 is kept, as UTF-8 input
+// is searched byte for byte.
+func decode(r *bufio.Reader, enc Encoding) *bufio.Reader {
+	if enc == EncodingUTF8 {
+		return r
+	}
+	head, _ := r.Peek(2)
+	dec := decoder(enc, head)
+	if dec == nil {
+		return r
+	}
+	return bufio.NewReaderSize(transform.NewReader(r, dec), readBufferSize)
+}
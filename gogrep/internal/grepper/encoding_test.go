@@ -0,0 +1,49 @@
+package grepper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding Encoding
+		input    string
+		want     string
+	}{
+		{"utf16le_bom", EncodingAuto, "\xff\xfeS\x00t\x00r\x00a\x00\xdf\x00e\x00\n\x00x\x00\n\x00", "1:0:Straße\n"},
+		{"utf16be_bom", EncodingAuto, "\xfe\xff\x00S\x00t\x00r\x00a\x00\xdf\x00e\x00\n", "1:0:Straße\n"},
+		{"utf16le_no_bom", EncodingUTF16LE, "x\x00\n\x00S\x00t\x00r\x00a\x00\xdf\x00e\x00", "2:2:Straße\n"},
+		{"utf16_bom_overrides", EncodingUTF16LE, "\xfe\xff\x00S\x00t\x00r\x00a\x00\xdf\x00e\x00\n", "1:0:Straße\n"},
+		{"utf16_surrogates", EncodingAuto, "\xff\xfe\x3d\xd8\x00\xdeS\x00t\x00r\x00a\x00\xdf\x00e\x00", "1:0:😀Straße\n"},
+		{"utf8_bom_kept", EncodingAuto, "\xef\xbb\xbfStraße\n", "1:0:\ufeffStraße\n"},
+		{"latin1", EncodingLatin1, "x\nStra\xdfe\n", "2:2:Straße\n"},
+		{"utf8_as_is", EncodingUTF8, "\xff\xfeStraße\n", "1:0:\xff\xfeStraße\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New([]string{"STRASSE"}, Config{Fixed: true, IgnoreCase: true, WithLineNo: true, ByteOffset: true, Encoding: tt.encoding})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if _, err := g.Grep(strings.NewReader(tt.input), "app.log", &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEncoding_UTF16WithoutBOMIsBinary(t *testing.T) {
+	var out strings.Builder
+	if _, err := GrepLines(strings.NewReader("S\x00t\x00r\x00a\x00\n\x00"), &out, "t", Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Binary file (standard input) matches\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
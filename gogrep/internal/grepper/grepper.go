@@ -2,141 +2,106 @@ package grepper
 
 import (
 	"bufio"
-	"fmt"
+	"context"
 	"io"
-	"regexp"
 	"strings"
-)
-
-// GrepLines returns the number of matching lines and any error.
-func GrepLines(r io.Reader, w io.Writer, pattern string, opts Config) (int, error) {
-	lines, err := readLines(r)
-	if err != nil {
-		return 0, err
-	}
-
-	matcher, err := buildMatcher(pattern, opts)
-	if err != nil {
-		return 0, err
-	}
-
-	matched, count := matchLines(lines, matcher, opts)
 
-	if opts.CountOnly {
-		return printCount(w, count)
-	}
+	"grep/search"
+)
 
-	toPrint := applyContext(matched, opts)
+// StdinName is how standard input is named in the output.
+const StdinName = "(standard input)"
 
-	return count, printLines(w, lines, matched, toPrint, opts)
-}
+// readBufferSize is the size of the input buffer.
+const readBufferSize = 64 << 10
 
-// readLines reads all lines from the input reader into a slice of strings.
-func readLines(r io.Reader) ([]string, error) {
-	scanner := bufio.NewScanner(r)
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	return lines, scanner.Err()
+// Grepper searches inputs for a compiled pattern.
+// It is safe to search several inputs with the same Grepper.
+type Grepper struct {
+	matcher   search.Matcher
+	prefilter *prefilter // nil if lines can't be skipped without matching them
+	opts      Config
 }
 
-// printCount prints only the number of matched lines.
-func printCount(w io.Writer, count int) (int, error) {
-	_, err := fmt.Fprintln(w, count)
+// New compiles patterns according to opts. A line matches if any of the
+// patterns matches; without patterns nothing matches.
+func New(patterns []string, opts Config) (*Grepper, error) {
+	matcher, err := buildMatcher(patterns, opts)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return count, nil
+	return &Grepper{matcher: matcher, prefilter: newPrefilter(patterns, opts), opts: opts}, nil
 }
 
-// matchLines checks each line against the matcher and returns a boolean
-// slice marking matches, along with the total count.
-func matchLines(lines []string, matcher func(string) bool, opts Config) ([]bool, int) {
-	matched := make([]bool, len(lines))
-	count := 0
-	for i, line := range lines {
-		m := matcher(line)
-		if opts.Invert {
-			m = !m
-		}
-		if m {
-			matched[i] = true
-			count++
-		}
-	}
-	return matched, count
+// WithFilename returns a Grepper like g that prefixes (or doesn't prefix)
+// the output lines with the file name.
+func (g *Grepper) WithFilename(on bool) *Grepper {
+	c := *g
+	c.opts.WithFilename = on
+	return &c
 }
 
-// applyContext expands the matched lines with before/after context
-// according to the grep options.
-func applyContext(matched []bool, opts Config) []bool {
-	n := len(matched)
-	toPrint := make([]bool, n)
+// Grep streams lines from r and writes the selected lines (or the count with
+// CountOnly, or name with -l/-L) to w. name is used for the file name prefix
+// and in notices. Returns the number of matching lines and any error.
+//
+// Input is processed line by line: memory use is bounded by the longest line
+// and the size of the before-context, and output is written as soon as it is
+// known, so Grep can filter endless streams like `tail -f`.
+func (g *Grepper) Grep(r io.Reader, name string, w io.Writer) (int, error) {
+	return g.GrepContext(context.Background(), r, name, w)
+}
 
-	after, before := opts.After, opts.Before
-	if opts.Context > 0 {
-		after, before = opts.Context, opts.Context
-	}
+// GrepContext is like Grep but stops with ctx.Err() once ctx is done.
+// Cancellation is noticed between lines.
+func (g *Grepper) GrepContext(ctx context.Context, r io.Reader, name string, w io.Writer) (int, error) {
+	stats, err := g.Search(ctx, r, name, w)
+	return stats.MatchedLines, err
+}
 
-	for i := 0; i < n; i++ {
-		if !matched[i] {
-			continue
-		}
-		toPrint[i] = true
-		for k := max(0, i-before); k < i; k++ {
-			toPrint[k] = true
-		}
-		for k := i + 1; k <= min(n-1, i+after); k++ {
-			toPrint[k] = true
-		}
+// Search is like GrepContext but returns the statistics of the search.
+func (g *Grepper) Search(ctx context.Context, r io.Reader, name string, w io.Writer) (Stats, error) {
+	out := bufio.NewWriter(w)
+	sink := newSink(g.matcher, name, out, g.opts)
+	in := decode(bufio.NewReaderSize(r, readBufferSize), g.opts.Encoding)
+	stats, err := g.searcher().Search(ctx, in, name, sink)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
 	}
-	return toPrint
+	return sink.stats(stats), err
 }
 
-// printLines writes the selected lines to the writer with optional
-// line number or match prefixes.
-func printLines(w io.Writer, lines []string, matched, toPrint []bool, opts Config) error {
-	for i := 0; i < len(lines); i++ {
-		if !toPrint[i] {
-			continue
-		}
-
-		prefix := ""
-		if opts.WithLineNo {
-			if matched[i] {
-				prefix = fmt.Sprintf("%d:", i+1)
-			} else {
-				prefix = fmt.Sprintf("%d-", i+1)
-			}
-		}
-
-		if _, err := fmt.Fprintln(w, prefix+lines[i]); err != nil {
-			return err
-		}
+// searcher returns the engine of the search of inputs.
+func (g *Grepper) searcher() *search.Searcher {
+	opts := g.opts.searchOptions()
+	if g.prefilter != nil {
+		opts.Prefilter = g.prefilter
 	}
-	return nil
+	return search.New(g.matcher, opts)
 }
 
-// buildMatcher builds a function that tests whether a line matches
-// the given pattern, considering fixed/regex and case sensitivity.
-func buildMatcher(pattern string, opts Config) (func(string) bool, error) {
-	if opts.Fixed {
-		p := pattern
-		if opts.IgnoreCase {
-			p = strings.ToLower(pattern)
-			return func(s string) bool { return strings.Contains(strings.ToLower(s), p) }, nil
-		}
-		return func(s string) bool { return strings.Contains(s, p) }, nil
+// GroupSeparator returns the line (with the line break) to write between
+// the outputs of consecutive inputs, as grep separates them like groups of
+// context; it's empty if no separators are printed.
+func (g *Grepper) GroupSeparator() string {
+	sep, ok := g.opts.groupSeparator()
+	if !ok {
+		return ""
 	}
+	var b strings.Builder
+	p := &printer{out: bufio.NewWriter(&b), opts: g.opts, colors: g.opts.Colors}
+	p.separator(sep)
+	p.out.Flush()
+	return b.String()
+}
 
-	pat := pattern
-	if opts.IgnoreCase {
-		pat = "(?i)" + pattern
-	}
-	re, err := regexp.Compile(pat)
+// GrepLines searches r for pattern and writes the result to w. As in grep,
+// a pattern containing newlines is a list of patterns.
+// Returns the number of matching lines and any error.
+func GrepLines(r io.Reader, w io.Writer, pattern string, opts Config) (int, error) {
+	g, err := New(strings.Split(pattern, "\n"), opts)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	return func(s string) bool { return re.MatchString(s) }, nil
+	return g.Grep(r, StdinName, w)
 }
@@ -3,11 +3,13 @@ package grepper
 import (
 	"bytes"
 	"errors"
+	"io"
 	"os/exec"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 // runUnixGrep executes the system grep command with given input, pattern, and flags.
@@ -267,3 +269,380 @@ func TestGrepLines_Combinations(t *testing.T) {
 		})
 	}
 }
+
+// syncWriter signals every write, so a test can wait for output.
+type syncWriter struct {
+	bytes.Buffer
+	wrote chan struct{}
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	w.wrote <- struct{}{}
+	return n, err
+}
+
+func TestGrepLines_StreamsBeforeEOF(t *testing.T) {
+	pr, pw := io.Pipe()
+	out := &syncWriter{wrote: make(chan struct{}, 16)}
+	done := make(chan error, 1)
+	go func() {
+		_, err := GrepLines(pr, out, "ERROR", Config{})
+		done <- err
+	}()
+
+	if _, err := io.WriteString(pw, "INFO start\nERROR first\n"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-out.wrote:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no output before the end of input")
+	}
+	if got := out.String(); got != "ERROR first\n" {
+		t.Fatalf("got %q, want %q", got, "ERROR first\n")
+	}
+
+	pw.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGrepLines_LineTerminators(t *testing.T) {
+	minified := `{"level":"error","items":[` + strings.Repeat(`{"id":1},`, 100000) + `]}`
+	tests := []struct {
+		name    string
+		input   string
+		pattern string
+		cfg     Config
+		want    string
+	}{
+		{"long_line", "INFO x\n" + minified + "\n", "error", Config{}, minified + "\n"},
+		{"long_line_count", minified + "\n" + minified, "error", Config{CountOnly: true}, "2\n"},
+		{"crlf_kept", "a ERROR\r\nb\r\nc ERROR\n", "ERROR", Config{After: 1}, "a ERROR\r\nb\r\nc ERROR\n"},
+		{"crlf_only_matching", "a ERROR\r\n", "ERROR", Config{OnlyMatching: true}, "ERROR\n"},
+		{"no_final_newline", "a\nb ERROR", "ERROR", Config{}, "b ERROR\n"},
+		{"null_data", "a ERROR\nb\x00c\x00ERROR", "ERROR", Config{NullData: true, WithLineNo: true}, "1:a ERROR\nb\x003:ERROR\x00"},
+		{"null_data_dot", "ERROR\nretry\x00", "R.r", Config{NullData: true, OnlyMatching: true}, "R\nr\x00"},
+		{"null_data_not_binary", "a\x00b\x00", "b", Config{NullData: true, CountOnly: true}, "1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if _, err := GrepLines(strings.NewReader(tt.input), &out, tt.pattern, tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %.80q, want %.80q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepLines_FieldQuery(t *testing.T) {
+	input := `{"level":"INFO","msg":"request done","status":200}
+{"level":"ERROR","msg":"query failed","status":503}
+level=ERROR msg="disk full" status=507
+ERROR plain text
+`
+	tests := []struct {
+		name    string
+		pattern string
+		cfg     Config
+		want    string
+	}{
+		{"json_and_logfmt", "", Config{FieldQuery: []string{"status>=500"}}, `{"level":"ERROR","msg":"query failed","status":503}` + "\nlevel=ERROR msg=\"disk full\" status=507\n"},
+		{"text_fallback", "", Config{FieldQuery: []string{"level=ERROR"}, CountOnly: true}, "3\n"},
+		{"with_pattern", "disk", Config{FieldQuery: []string{"level=ERROR"}}, "level=ERROR msg=\"disk full\" status=507\n"},
+		{"invert", "", Config{FieldQuery: []string{"level=ERROR"}, Invert: true, WithLineNo: true}, `1:{"level":"INFO","msg":"request done","status":200}` + "\n"},
+		{"project", "", Config{FieldQuery: []string{"msg~fail|full"}, Project: []string{"status", "msg"}, After: 1}, `{"status":503,"msg":"query failed"}` + "\nstatus=507 msg=\"disk full\"\nERROR plain text\n"},
+		{"project_only_matching", "[0-9]+", Config{FieldQuery: []string{"status"}, Project: []string{"status"}, OnlyMatching: true}, "200\n503\n507\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pats []string
+			if tt.pattern != "" {
+				pats = []string{tt.pattern}
+			}
+			g, err := New(pats, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if _, err := g.Grep(strings.NewReader(input), StdinName, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepLines_ContextAcrossGroups(t *testing.T) {
+	input := "a\nmatch\nb\nc\nd\ne\nmatch\nf\nmatch\ng\nh\ni\nj\nmatch\n"
+	colors := DefaultColors
+	sep := "=="
+	empty := ""
+	tests := []struct {
+		name  string
+		cfg   Config
+		flags []string // equivalent system grep flags
+	}{
+		{"separators", Config{Before: 2, After: 1, WithLineNo: true}, []string{"-B", "2", "-A", "1", "-n"}},
+		{"no_group_separator", Config{Before: 2, After: 1, NoGroupSeparator: true}, []string{"--no-group-separator", "-B", "2", "-A", "1"}},
+		{"group_separator", Config{Context: 1, GroupSeparator: &sep}, []string{"--group-separator===", "-C", "1"}},
+		{"empty_group_separator", Config{After: 1, GroupSeparator: &empty}, []string{"--group-separator=", "-A", "1"}},
+		{"colored_separator", Config{After: 1, Colors: &colors}, []string{"--color=always", "-A", "1"}},
+		{"after_overrides_context", Config{Context: 3, After: 1}, []string{"-C", "3", "-A", "1"}},
+		{"before_overrides_context", Config{Context: 1, Before: 3}, []string{"-B", "3", "-C", "1"}},
+		{"only_matching", Config{OnlyMatching: true, After: 1, WithLineNo: true}, []string{"-o", "-A", "1", "-n"}},
+		{"invert", Config{Invert: true, Context: 1}, []string{"-v", "-C", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goLines, err := runGoGrep(input, "match", tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sysLines, err := runUnixGrep(input, "match", tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(goLines, sysLines) {
+				t.Errorf("got %#v\nwant %#v", goLines, sysLines)
+			}
+		})
+	}
+}
+
+func TestGrepper_GroupSeparator(t *testing.T) {
+	sep := "=="
+	tests := []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{}, ""},
+		{Config{After: 1}, "--\n"},
+		{Config{Context: 1, GroupSeparator: &sep}, "==\n"},
+		{Config{Context: 1, NoGroupSeparator: true}, ""},
+		{Config{Context: 1, CountOnly: true}, ""},
+	}
+	for _, tt := range tests {
+		g, err := New([]string{"x"}, tt.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := g.GroupSeparator(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestGrepper_Grep(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		cfg   Config
+		want  string
+	}{
+		{"with_filename", "foo\nbar\nfoo\n", Config{WithFilename: true, WithLineNo: true, After: 1},
+			"app.log:1:foo\napp.log-2-bar\napp.log:3:foo\n"},
+		{"count_with_filename", "foo\nbar\nfoo\n", Config{WithFilename: true, CountOnly: true}, "app.log:2\n"},
+		{"files_with_matches", "foo\nbar\n", Config{FilesWithMatches: true}, "app.log\n"},
+		{"files_with_matches_none", "bar\n", Config{FilesWithMatches: true}, ""},
+		{"files_without_match", "bar\n", Config{FilesWithoutMatch: true}, "app.log\n"},
+		{"binary", "bar\x00\nfoo\nfoo\n", Config{}, "Binary file app.log matches\n"},
+		{"binary_count", "bar\x00\nfoo\nfoo\n", Config{CountOnly: true}, "2\n"},
+		{"binary_as_text", "bar\x00\nfoo\n", Config{Binary: BinaryText}, "foo\n"},
+		{"binary_without_match", "foo\x00\nfoo\n", Config{Binary: BinaryWithoutMatch, CountOnly: true}, "0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New([]string{"foo"}, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if _, err := g.Grep(strings.NewReader(tt.input), "app.log", &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepLines_MatchSpans(t *testing.T) {
+	colors := DefaultColors
+	tests := []struct {
+		name    string
+		input   string
+		pattern string
+		cfg     Config
+		flags   []string // equivalent system grep flags
+	}{
+		{"only_matching", "foo bar foo\nbaz\n", "fo*", Config{OnlyMatching: true}, []string{"-o"}},
+		{"only_matching_fixed_ignore_case", "Foo bar fOO\n", "foo", Config{OnlyMatching: true, Fixed: true, IgnoreCase: true}, []string{"-o", "-F", "-i"}},
+		{"only_matching_byte_offset", "xx foo\nbar\nfoo foo\n", "foo", Config{OnlyMatching: true, ByteOffset: true, WithLineNo: true}, []string{"-o", "-b", "-n"}},
+		{"byte_offset_context", "xx foo\nbar\nfoo\n", "bar", Config{ByteOffset: true, Context: 1}, []string{"-b", "-C", "1"}},
+		{"color", "xx foo\nbar\nfoo foo\n", "foo", Config{Colors: &colors, WithLineNo: true, After: 1}, []string{"--color=always", "-n", "-A", "1"}},
+		{"color_fixed", "a.b axb a.b\n", "a.b", Config{Colors: &colors, Fixed: true}, []string{"--color=always", "-F"}},
+		{"color_invert", "foo\nbar\n", "foo", Config{Colors: &colors, Invert: true, Before: 1}, []string{"--color=always", "-v", "-B", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goLines, err := runGoGrep(tt.input, tt.pattern, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sysLines, err := runUnixGrep(tt.input, tt.pattern, tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(goLines, sysLines) {
+				t.Errorf("got %#v\nwant %#v", goLines, sysLines)
+			}
+		})
+	}
+}
+
+func TestGrepLines_Column(t *testing.T) {
+	var out bytes.Buffer
+	_, err := GrepLines(strings.NewReader("xx foo\nbar\nfoo foo\n"), &out, "foo", Config{Column: true, OnlyMatching: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "4:foo\n1:foo\n5:foo\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestGrepLines_Patterns(t *testing.T) {
+	input := "foo bar\nfoobar\nbaz foo_x\n@foo\na@foo\nFOO\nfoo\n\nab b\n"
+	tests := []struct {
+		name    string
+		pattern string
+		cfg     Config
+		flags   []string // equivalent system grep flags
+	}{
+		{"word", "foo", Config{WordRegexp: true}, []string{"-w"}},
+		{"word_non_word_pattern", "@foo", Config{WordRegexp: true}, []string{"-w"}},
+		{"word_retry", "b.*", Config{WordRegexp: true, OnlyMatching: true}, []string{"-w", "-o"}},
+		{"word_retry_extended", "b.*", Config{Syntax: SyntaxExtended, WordRegexp: true}, []string{"-w", "-E"}},
+		{"word_empty", "", Config{WordRegexp: true, WithLineNo: true}, []string{"-w", "-n"}},
+		{"line", "foo", Config{LineRegexp: true, IgnoreCase: true}, []string{"-x", "-i"}},
+		{"line_empty", "", Config{LineRegexp: true, WithLineNo: true}, []string{"-x", "-n"}},
+		{"several", "bar\nbaz", Config{OnlyMatching: true}, []string{"-o"}},
+		{"several_fixed", "foo\nbar\nba\no_", Config{Fixed: true, OnlyMatching: true}, []string{"-F", "-o"}},
+		{"several_fixed_word", "foo\nbaz", Config{Fixed: true, WordRegexp: true, WithLineNo: true}, []string{"-F", "-w", "-n"}},
+		{"several_fixed_ignore_case", "FOO\nBAR", Config{Fixed: true, IgnoreCase: true, OnlyMatching: true}, []string{"-F", "-i", "-o"}},
+		{"several_fixed_line", "foo\nfoobar", Config{Fixed: true, LineRegexp: true}, []string{"-F", "-x"}},
+		{"several_longest", "foo|foobar", Config{OnlyMatching: true}, []string{"-E", "-o"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goLines, err := runGoGrep(input, tt.pattern, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sysLines, err := runUnixGrep(input, tt.pattern, tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(goLines, sysLines) {
+				t.Errorf("got %#v\nwant %#v", goLines, sysLines)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_WordAndLine(t *testing.T) {
+	cfg := Config{WordRegexp: true, LineRegexp: true}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected -w with -x to be rejected")
+	}
+}
+
+func BenchmarkGrepLines_ManyFixedPatterns(b *testing.B) {
+	patterns := make([]string, 2000)
+	for i := range patterns {
+		patterns[i] = "session-" + strconv.Itoa(i*7919)
+	}
+	g, err := New(patterns, Config{Fixed: true, CountOnly: true})
+	if err != nil {
+		b.Fatal(err)
+	}
+	input := strings.Repeat("2025-07-16T10:00:00Z level=INFO msg=request session=session-42\n", 10000)
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := g.Grep(strings.NewReader(input), StdinName, io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGrepLines_MaxCountAndQuiet(t *testing.T) {
+	input := "a1\nb\na2\na3\nc\nd\na4\n"
+	tests := []struct {
+		name  string
+		cfg   Config
+		flags []string // equivalent system grep flags
+	}{
+		{"max_count", Config{MaxCount: 2}, []string{"-m", "2"}},
+		{"max_count_trailing_context", Config{MaxCount: 1, After: 2, WithLineNo: true}, []string{"-m", "1", "-A", "2", "-n"}},
+		{"max_count_context", Config{MaxCount: 2, Context: 1}, []string{"-m", "2", "-C", "1"}},
+		{"max_count_count", Config{MaxCount: 3, CountOnly: true}, []string{"-m", "3", "-c"}},
+		{"max_count_invert", Config{MaxCount: 1, Invert: true}, []string{"-m", "1", "-v"}},
+		{"quiet", Config{Quiet: true}, []string{"-q"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goLines, err := runGoGrep(input, "a", tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sysLines, err := runUnixGrep(input, "a", tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(sysLines) == 0 {
+				sysLines = []string{""} // runGoGrep splits empty output into one empty line
+			}
+			if !reflect.DeepEqual(goLines, sysLines) {
+				t.Errorf("got %#v\nwant %#v", goLines, sysLines)
+			}
+		})
+	}
+}
+
+func TestGrepper_QuietStopsAtFirstMatch(t *testing.T) {
+	g, err := New([]string{"a"}, Config{Quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an endless input must not be read to the end
+	r := io.MultiReader(strings.NewReader("x\na\n"), endless{})
+	count, err := g.Grep(r, StdinName, io.Discard)
+	if err != nil || count != 1 {
+		t.Errorf("got %d, %v; want 1, nil", count, err)
+	}
+}
+
+// endless is a reader that never ends.
+type endless struct{}
+
+func (endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = '\n'
+	}
+	return len(p), nil
+}
@@ -0,0 +1,212 @@
+package grepper
+
+import (
+	"bufio"
+	"encoding/json"
+	"grep/search"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// newOutput returns the output for opts.
+func newOutput(out *bufio.Writer, name string, opts Config) output {
+	if opts.JSON {
+		return &jsonPrinter{enc: newJSONEncoder(out), path: newJSONData(name)}
+	}
+	return &printer{out: out, name: name, opts: opts, colors: opts.Colors}
+}
+
+func newJSONEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc
+}
+
+// jsonData is text, or base64-encoded bytes if it isn't valid UTF-8.
+type jsonData struct {
+	Text  *string `json:"text,omitempty"`
+	Bytes []byte  `json:"bytes,omitempty"`
+}
+
+func newJSONData(s string) jsonData {
+	if utf8.ValidString(s) {
+		return jsonData{Text: &s}
+	}
+	return jsonData{Bytes: []byte(s)}
+}
+
+type jsonMessage struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type jsonBegin struct {
+	Path jsonData `json:"path"`
+}
+
+type jsonLine struct {
+	Path           jsonData       `json:"path"`
+	Lines          jsonData       `json:"lines"`
+	LineNumber     int            `json:"line_number"`
+	AbsoluteOffset int64          `json:"absolute_offset"`
+	Submatches     []jsonSubmatch `json:"submatches"`
+}
+
+type jsonSubmatch struct {
+	Match    jsonData `json:"match"`
+	Start    int      `json:"start"`
+	End      int      `json:"end"`
+	Distance *int     `json:"distance,omitempty"` // edit distance with --fuzzy
+}
+
+type jsonEnd struct {
+	Path         jsonData  `json:"path"`
+	BinaryOffset *int64    `json:"binary_offset"`
+	Stats        jsonStats `json:"stats"`
+}
+
+type jsonStats struct {
+	MatchedLines  int   `json:"matched_lines"`
+	Matches       int   `json:"matches"`
+	BytesSearched int64 `json:"bytes_searched"`
+}
+
+type jsonSummary struct {
+	ElapsedTotal jsonDuration     `json:"elapsed_total"`
+	Stats        jsonSummaryStats `json:"stats"`
+}
+
+type jsonSummaryStats struct {
+	Searches          int `json:"searches"`
+	SearchesWithMatch int `json:"searches_with_match"`
+	jsonStats
+}
+
+type jsonDuration struct {
+	Secs  int64  `json:"secs"`
+	Nanos int    `json:"nanos"`
+	Human string `json:"human"`
+}
+
+// jsonPrinter writes one JSON object per line (--json), like ripgrep:
+// "begin" and "end" enclose the "match" and "context" records of an input
+// that has output, and the caller writes a final "summary" record.
+type jsonPrinter struct {
+	enc          *json.Encoder
+	path         jsonData
+	begun        bool
+	binaryOffset *int64
+}
+
+func (p *jsonPrinter) begin() error {
+	if p.begun {
+		return nil
+	}
+	p.begun = true
+	return p.enc.Encode(jsonMessage{Type: "begin", Data: jsonBegin{Path: p.path}})
+}
+
+func (p *jsonPrinter) line(l line, sep byte, spans []search.Span) error {
+	if err := p.begin(); err != nil {
+		return err
+	}
+	typ := "match"
+	if sep == '-' {
+		typ = "context"
+	}
+
+	submatches := make([]jsonSubmatch, 0, len(spans))
+	for i, sp := range spans {
+		if sp.Start == sp.End {
+			continue
+		}
+		sub := jsonSubmatch{
+			Match: newJSONData(l.text[sp.Start:sp.End]),
+			Start: sp.Start,
+			End:   sp.End,
+		}
+		if l.distances != nil {
+			sub.Distance = &l.distances[i]
+		}
+		submatches = append(submatches, sub)
+	}
+	return p.enc.Encode(jsonMessage{Type: typ, Data: jsonLine{
+		Path:           p.path,
+		Lines:          newJSONData(l.text + l.eol),
+		LineNumber:     l.no,
+		AbsoluteOffset: l.offset,
+		Submatches:     submatches,
+	}})
+}
+
+// onlyMatching writes the whole line: the records carry the match positions anyway.
+func (p *jsonPrinter) onlyMatching(l line, spans []search.Span) error {
+	return p.line(l, ':', spans)
+}
+
+func (p *jsonPrinter) separator(string) error {
+	return nil
+}
+
+// binaryMatches records where binary data was found, for the "end" record.
+func (p *jsonPrinter) binaryMatches(l line) error {
+	offset := l.offset
+	if i := strings.IndexByte(l.text, 0); i >= 0 {
+		offset += int64(i)
+	}
+	p.binaryOffset = &offset
+	return p.begin()
+}
+
+func (p *jsonPrinter) fileName() error {
+	return p.begin()
+}
+
+func (p *jsonPrinter) count(int) error {
+	return p.begin()
+}
+
+func (p *jsonPrinter) end(stats Stats) error {
+	if !p.begun {
+		return nil
+	}
+	return p.enc.Encode(jsonMessage{Type: "end", Data: jsonEnd{
+		Path:         p.path,
+		BinaryOffset: p.binaryOffset,
+		Stats: jsonStats{
+			MatchedLines:  stats.MatchedLines,
+			Matches:       stats.Matches,
+			BytesSearched: stats.BytesSearched,
+		},
+	}})
+}
+
+// Summary describes a whole run, for the final --json record.
+type Summary struct {
+	Stats
+	Searches          int // inputs searched
+	SearchesWithMatch int // inputs with selected lines
+	Elapsed           time.Duration
+}
+
+// WriteJSONSummary writes the final "summary" record of --json output.
+func WriteJSONSummary(w io.Writer, s Summary) error {
+	return newJSONEncoder(w).Encode(jsonMessage{Type: "summary", Data: jsonSummary{
+		ElapsedTotal: jsonDuration{
+			Secs:  int64(s.Elapsed / time.Second),
+			Nanos: int(s.Elapsed % time.Second),
+			Human: s.Elapsed.String(),
+		},
+		Stats: jsonSummaryStats{
+			Searches:          s.Searches,
+			SearchesWithMatch: s.SearchesWithMatch,
+			jsonStats: jsonStats{
+				MatchedLines:  s.MatchedLines,
+				Matches:       s.Matches,
+				BytesSearched: s.BytesSearched,
+			},
+		},
+	}})
+}
@@ -0,0 +1,155 @@
+package grepper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// record is a decoded --json record.
+type record struct {
+	Type string `json:"type"`
+	Data struct {
+		Path           jsonData       `json:"path"`
+		Lines          jsonData       `json:"lines"`
+		LineNumber     int            `json:"line_number"`
+		AbsoluteOffset int64          `json:"absolute_offset"`
+		Submatches     []jsonSubmatch `json:"submatches"`
+		BinaryOffset   *int64         `json:"binary_offset"`
+		Stats          map[string]int `json:"stats"`
+	} `json:"data"`
+}
+
+func grepJSON(t *testing.T, input string, cfg Config) []record {
+	t.Helper()
+	cfg.JSON = true
+	g, err := New([]string{"ERROR"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if _, err := g.Grep(strings.NewReader(input), "app.log", &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []record
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func types(records []record) string {
+	var ts []string
+	for _, r := range records {
+		ts = append(ts, r.Type)
+	}
+	return strings.Join(ts, ",")
+}
+
+func TestJSON_MatchAndContext(t *testing.T) {
+	records := grepJSON(t, "ok\nERROR <db> & ERROR\r\nbad \xff\n", Config{After: 1, Before: 1})
+
+	if got, want := types(records), "begin,context,match,context,end"; got != want {
+		t.Fatalf("got records %s, want %s", got, want)
+	}
+	m := records[2].Data
+	if *m.Path.Text != "app.log" || *m.Lines.Text != "ERROR <db> & ERROR\r\n" || m.LineNumber != 2 || m.AbsoluteOffset != 3 {
+		t.Errorf("unexpected match record %+v", m)
+	}
+	if len(m.Submatches) != 2 || *m.Submatches[1].Match.Text != "ERROR" || m.Submatches[1].Start != 13 || m.Submatches[1].End != 18 {
+		t.Errorf("unexpected submatches %+v", m.Submatches)
+	}
+	if c := records[3].Data; c.Lines.Text != nil || string(c.Lines.Bytes) != "bad \xff\n" {
+		t.Errorf("invalid UTF-8 should be base64 bytes, got %+v", c.Lines)
+	}
+	if s := records[4].Data.Stats; s["matched_lines"] != 1 || s["matches"] != 2 || s["bytes_searched"] != 29 {
+		t.Errorf("unexpected stats %v", s)
+	}
+}
+
+func TestJSON_FuzzyDistance(t *testing.T) {
+	records := grepJSON(t, "EROR then ERROR\nERR\n", Config{Fuzzy: 2})
+
+	if got, want := types(records), "begin,match,match,end"; got != want {
+		t.Fatalf("got records %s, want %s", got, want)
+	}
+	var got []string
+	for _, r := range records[1:3] {
+		for _, sm := range r.Data.Submatches {
+			if sm.Distance == nil {
+				t.Fatalf("submatch %+v has no distance", sm)
+			}
+			got = append(got, fmt.Sprintf("%s:%d", *sm.Match.Text, *sm.Distance))
+		}
+	}
+	if want := []string{"EROR:1", "ERROR:0", "ERR:2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got submatches %v, want %v", got, want)
+	}
+
+	if sm := grepJSON(t, "ERROR\n", Config{})[1].Data.Submatches; sm[0].Distance != nil {
+		t.Errorf("exact matches should have no distance, got %d", *sm[0].Distance)
+	}
+}
+
+func TestJSON_Modes(t *testing.T) {
+	input := "a\nERROR\nb\n"
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"invert", Config{Invert: true}, "begin,match,match,end"},
+		{"count", Config{CountOnly: true}, "begin,end"},
+		{"files_with_matches", Config{FilesWithMatches: true}, "begin,end"},
+		{"quiet", Config{Quiet: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := types(grepJSON(t, input, tt.cfg)); got != tt.want {
+				t.Errorf("got records %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := types(grepJSON(t, "a\nb\n", Config{})); got != "" {
+		t.Errorf("an input without matches should have no records, got %q", got)
+	}
+}
+
+func TestJSON_Binary(t *testing.T) {
+	records := grepJSON(t, "a\nx\x00 ERROR\n", Config{})
+	if got, want := types(records), "begin,end"; got != want {
+		t.Fatalf("got records %s, want %s", got, want)
+	}
+	if off := records[1].Data.BinaryOffset; off == nil || *off != 3 {
+		t.Errorf("got binary offset %v, want 3", off)
+	}
+}
+
+func TestWriteJSONSummary(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteJSONSummary(&out, Summary{
+		Stats:             Stats{MatchedLines: 3, Matches: 4, BytesSearched: 100},
+		Searches:          2,
+		SearchesWithMatch: 1,
+		Elapsed:           1500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"summary","data":{"elapsed_total":{"secs":1,"nanos":500000000,"human":"1.5s"},` +
+		`"stats":{"searches":2,"searches_with_match":1,"matched_lines":3,"matches":4,"bytes_searched":100}}}` + "\n"
+	if out.String() != want {
+		t.Errorf("got %s\nwant %s", out.String(), want)
+	}
+}
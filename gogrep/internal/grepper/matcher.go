@@ -0,0 +1,566 @@
+package grepper
+
+import (
+	"grep/internal/ahocorasick"
+	"grep/internal/backtrack"
+	"grep/internal/fields"
+	"grep/internal/fuzzy"
+	"grep/search"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// buildMatcher builds the Matcher for the given patterns; a line matches
+// if any of the patterns matches. It considers fixed/regex, the syntax and
+// engine of regular expressions, case sensitivity and word/line matching.
+func buildMatcher(patterns []string, opts Config) (search.Matcher, error) {
+	if len(opts.FieldQuery) > 0 {
+		return newFieldMatcher(patterns, opts)
+	}
+	if opts.Query != "" {
+		return newQueryMatcher(patterns, opts)
+	}
+	if len(patterns) == 0 {
+		return noMatcher{}, nil
+	}
+
+	var m search.Matcher
+	if opts.Fuzzy > 0 {
+		m = newFuzzyMatcher(patterns, opts)
+		if opts.LineRegexp {
+			return m, nil // the whole line is compared
+		}
+	} else if opts.Fixed {
+		m = newFixedMatcher(patterns, opts.IgnoreCase)
+	} else {
+		patterns, backtracking, err := translatePatterns(patterns, opts.Syntax)
+		if err != nil {
+			return nil, err
+		}
+		if opts.NullData && opts.Syntax != SyntaxPerl {
+			// lines may contain newlines, which '.' matches, as in grep
+			patterns = dotAll(patterns)
+		}
+		if backtracking {
+			return newBacktrackMatcher(patterns, opts)
+		}
+		re, err := compileRegexp(patterns, opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.LineRegexp {
+			return &regexMatcher{re: re}, nil // anchored by compileRegexp
+		}
+		m = &regexMatcher{re: re}
+	}
+
+	switch {
+	case opts.LineRegexp:
+		return &lineMatcher{m}, nil
+	case opts.WordRegexp:
+		return &wordMatcher{m}, nil
+	}
+	return m, nil
+}
+
+// translatePatterns translates POSIX patterns into the syntax of Go's
+// regexp. It reports whether the patterns need the backtracking engine:
+// Perl-compatible patterns always do, POSIX ones with backreferences too.
+func translatePatterns(patterns []string, syntax Syntax) ([]string, bool, error) {
+	switch syntax {
+	case SyntaxPerl:
+		return patterns, true, nil
+	case SyntaxBasic, SyntaxExtended:
+		translated := make([]string, len(patterns))
+		backtracking := false
+		for i, p := range patterns {
+			t, bt, err := translatePOSIX(p, syntax == SyntaxExtended)
+			if err != nil {
+				return nil, false, err
+			}
+			translated[i] = t
+			backtracking = backtracking || bt
+		}
+		return translated, backtracking, nil
+	default:
+		return patterns, false, nil
+	}
+}
+
+func dotAll(patterns []string) []string {
+	all := make([]string, len(patterns))
+	for i, p := range patterns {
+		all[i] = "(?s)" + p
+	}
+	return all
+}
+
+// compileRegexp compiles the alternation of patterns with POSIX
+// leftmost-longest match semantics.
+func compileRegexp(patterns []string, opts Config) (*regexp.Regexp, error) {
+	alts := make([]string, len(patterns))
+	for i, p := range patterns {
+		// compile separately so that a pattern can't break out of its group
+		if _, err := regexp.Compile(p); err != nil {
+			return nil, err
+		}
+		alts[i] = "(?:" + p + ")"
+	}
+
+	pat := strings.Join(alts, "|")
+	if opts.LineRegexp {
+		pat = "^(?:" + pat + ")$"
+	}
+	if opts.IgnoreCase {
+		pat = "(?i)" + pat
+	}
+	re, err := regexp.Compile(pat)
+	if err != nil {
+		return nil, err
+	}
+	re.Longest()
+	return re, nil
+}
+
+// newBacktrackMatcher returns a Matcher for patterns in the syntax of the
+// backtracking engine. -w and -x are expressed with lookaround and anchors,
+// so the engine looks for the matches that satisfy them.
+func newBacktrackMatcher(patterns []string, opts Config) (search.Matcher, error) {
+	ms := make(anyMatcher, len(patterns))
+	for i, p := range patterns {
+		// compile separately so that a pattern can't break out of its group
+		if _, err := backtrack.Compile(p, backtrack.Options{}); err != nil {
+			return nil, err
+		}
+		switch {
+		case opts.LineRegexp:
+			p = `\A(?:` + p + `)\z`
+		case opts.WordRegexp:
+			p = `(?<!\w)(?:` + p + `)(?!\w)`
+		}
+		re, err := backtrack.Compile(p, backtrack.Options{IgnoreCase: opts.IgnoreCase})
+		if err != nil {
+			return nil, err
+		}
+		ms[i] = &backtrackMatcher{re: re}
+	}
+	if len(ms) == 1 {
+		return ms[0], nil
+	}
+	return ms, nil
+}
+
+// newFixedMatcher returns a Matcher for fixed strings (-F). Many patterns
+// are searched for at once with an Aho–Corasick automaton.
+func newFixedMatcher(patterns []string, ignoreCase bool) search.Matcher {
+	if len(patterns) == 1 {
+		return newSingleFixedMatcher(patterns[0], ignoreCase)
+	}
+	if ignoreCase && !foldsLikeASCII(patterns) {
+		// the automaton folds ASCII only
+		ms := make(anyMatcher, len(patterns))
+		for i, p := range patterns {
+			ms[i] = newSingleFixedMatcher(p, true)
+		}
+		return ms
+	}
+	return &multiFixedMatcher{
+		ac:       ahocorasick.New(patterns, ignoreCase),
+		hasEmpty: slices.Contains(patterns, ""),
+	}
+}
+
+func isASCII(patterns []string) bool {
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			if p[i] >= utf8.RuneSelf {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fieldMatcher selects the lines of structured logs whose fields satisfy
+// a query (--jq-field) and that match the patterns, if any.
+type fieldMatcher struct {
+	query *fields.Query
+	text  search.Matcher // nil without patterns
+}
+
+func newFieldMatcher(patterns []string, opts Config) (search.Matcher, error) {
+	query, err := fields.Compile(opts.FieldQuery, opts.IgnoreCase)
+	if err != nil {
+		return nil, err
+	}
+	m := &fieldMatcher{query: query}
+	if len(patterns) > 0 || opts.Query != "" {
+		opts.FieldQuery = nil
+		if m.text, err = buildMatcher(patterns, opts); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *fieldMatcher) Match(line string) (bool, error) {
+	if !m.query.Match(line) {
+		return false, nil
+	}
+	if m.text == nil {
+		return true, nil
+	}
+	return m.text.Match(line)
+}
+
+// FindAll returns the matches of the patterns, or the whole line if there
+// are none: fields aren't located in the line.
+func (m *fieldMatcher) FindAll(line string) ([]search.Span, error) {
+	if m.text != nil {
+		return m.text.FindAll(line)
+	}
+	if line == "" {
+		return nil, nil
+	}
+	return []search.Span{{Start: 0, End: len(line)}}, nil
+}
+
+// fuzzyMatcher finds the approximate occurrences of a string (--fuzzy), or
+// with -x matches the lines within the distance of it.
+type fuzzyMatcher struct {
+	m    *fuzzy.Matcher
+	k    int
+	line bool
+}
+
+// newFuzzyMatcher returns a Matcher for the strings within opts.Fuzzy edits
+// of any of the patterns.
+func newFuzzyMatcher(patterns []string, opts Config) search.Matcher {
+	ms := make(anyMatcher, len(patterns))
+	for i, p := range patterns {
+		ms[i] = &fuzzyMatcher{m: fuzzy.New(p, opts.Fuzzy, opts.IgnoreCase), k: opts.Fuzzy, line: opts.LineRegexp}
+	}
+	if len(ms) == 1 {
+		return ms[0]
+	}
+	return ms
+}
+
+func (m *fuzzyMatcher) Match(line string) (bool, error) {
+	if m.line {
+		return m.m.Distance(line) <= m.k, nil
+	}
+	return m.m.MatchString(line), nil
+}
+
+func (m *fuzzyMatcher) FindAll(line string) ([]search.Span, error) {
+	if m.line {
+		if m.m.Distance(line) <= m.k {
+			return []search.Span{{Start: 0, End: len(line)}}, nil
+		}
+		return nil, nil
+	}
+	var spans []search.Span
+	for _, match := range m.m.FindAll(line) {
+		spans = append(spans, search.Span{Start: match.Start, End: match.End})
+	}
+	return spans, nil
+}
+
+// noMatcher matches nothing, e.g. when -f names an empty file.
+type noMatcher struct{}
+
+func (noMatcher) Match(string) (bool, error)            { return false, nil }
+func (noMatcher) FindAll(string) ([]search.Span, error) { return nil, nil }
+
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m *regexMatcher) Match(line string) (bool, error) {
+	return m.re.MatchString(line), nil
+}
+
+func (m *regexMatcher) FindAll(line string) ([]search.Span, error) {
+	var spans []search.Span
+	for _, loc := range m.re.FindAllStringIndex(line, -1) {
+		spans = append(spans, search.Span{Start: loc[0], End: loc[1]})
+	}
+	return spans, nil
+}
+
+// backtrackMatcher matches with the backtracking engine (-P, and POSIX
+// patterns with backreferences). A line on which the engine runs out of
+// its budget fails the search with backtrack.ErrStepLimit.
+type backtrackMatcher struct {
+	re *backtrack.Regexp
+}
+
+func (m *backtrackMatcher) Match(line string) (bool, error) {
+	return m.re.MatchString(line)
+}
+
+func (m *backtrackMatcher) FindAll(line string) ([]search.Span, error) {
+	found, err := m.re.FindAllStringIndex(line)
+	if err != nil {
+		return nil, err
+	}
+	spans := make([]search.Span, len(found))
+	for i, f := range found {
+		spans[i] = search.Span{Start: f[0], End: f[1]}
+	}
+	return spans, nil
+}
+
+// fixedMatcher looks for a literal substring (-F).
+type fixedMatcher struct {
+	pattern    string
+	ignoreCase bool
+	folded     []rune // the case folding of pattern, with ignoreCase
+}
+
+func newSingleFixedMatcher(pattern string, ignoreCase bool) *fixedMatcher {
+	m := &fixedMatcher{pattern: pattern, ignoreCase: ignoreCase}
+	if ignoreCase {
+		m.folded = foldString(pattern)
+	}
+	return m
+}
+
+func (m *fixedMatcher) Match(line string) (bool, error) {
+	start, _ := m.index(line)
+	return start >= 0, nil
+}
+
+func (m *fixedMatcher) FindAll(line string) ([]search.Span, error) {
+	var spans []search.Span
+	for offset := 0; offset <= len(line); {
+		start, end := m.index(line[offset:])
+		if start < 0 {
+			break
+		}
+		spans = append(spans, search.Span{Start: offset + start, End: offset + end})
+		if end == start {
+			// empty pattern: step over a rune to make progress
+			_, size := utf8.DecodeRuneInString(line[offset+end:])
+			end += max(size, 1)
+		}
+		offset += end
+	}
+	return spans, nil
+}
+
+// index returns the span of the first occurrence of the pattern in s,
+// or -1, -1 if there is none.
+func (m *fixedMatcher) index(s string) (int, int) {
+	if !m.ignoreCase {
+		i := strings.Index(s, m.pattern)
+		if i < 0 {
+			return -1, -1
+		}
+		return i, i + len(m.pattern)
+	}
+	for i := range s {
+		if n, ok := hasPrefixFold(s[i:], m.folded); ok {
+			return i, i + n
+		}
+	}
+	if m.pattern == "" {
+		return len(s), len(s)
+	}
+	return -1, -1
+}
+
+// multiFixedMatcher looks for several literal substrings at once (-F with
+// several patterns).
+type multiFixedMatcher struct {
+	ac       *ahocorasick.Matcher
+	hasEmpty bool // an empty pattern matches every line
+}
+
+func (m *multiFixedMatcher) Match(line string) (bool, error) {
+	return m.hasEmpty || m.ac.Match(line), nil
+}
+
+func (m *multiFixedMatcher) FindAll(line string) ([]search.Span, error) {
+	found := m.ac.FindAll(line)
+	if len(found) == 0 && m.hasEmpty {
+		return []search.Span{{Start: 0, End: 0}}, nil
+	}
+	spans := make([]search.Span, len(found))
+	for i, f := range found {
+		spans[i] = search.Span{Start: f[0], End: f[1]}
+	}
+	return spans, nil
+}
+
+// anyMatcher matches if any of its matchers does; spans are leftmost-longest.
+type anyMatcher []search.Matcher
+
+func (ms anyMatcher) Match(line string) (bool, error) {
+	for _, m := range ms {
+		if ok, err := m.Match(line); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (ms anyMatcher) FindAll(line string) ([]search.Span, error) {
+	var all []search.Span
+	for _, m := range ms {
+		spans, err := m.FindAll(line)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, spans...)
+	}
+	slices.SortFunc(all, func(a, b search.Span) int {
+		if a.Start != b.Start {
+			return a.Start - b.Start
+		}
+		return b.End - a.End
+	})
+
+	var spans []search.Span
+	for _, sp := range all {
+		if len(spans) == 0 || sp.Start >= spans[len(spans)-1].End && sp.Start > spans[len(spans)-1].Start {
+			spans = append(spans, sp)
+		}
+	}
+	return spans, nil
+}
+
+// leaves returns the matchers that m wraps or combines and that find its
+// matches, or m itself.
+func leaves(m search.Matcher) []search.Matcher {
+	switch m := m.(type) {
+	case *wordMatcher:
+		return leaves(m.Matcher)
+	case *lineMatcher:
+		return leaves(m.Matcher)
+	case *fieldMatcher:
+		if m.text != nil {
+			return leaves(m.text)
+		}
+		return nil
+	case *queryMatcher:
+		return leaves(m.highlight)
+	case anyMatcher:
+		var all []search.Matcher
+		for _, sub := range m {
+			all = append(all, leaves(sub)...)
+		}
+		return all
+	}
+	return []search.Matcher{m}
+}
+
+// wordMatcher keeps only matches that form whole words (-w): a match must
+// be preceded and followed by a non-word character or the line boundary.
+// Like grep, when a match isn't a word, the shorter matches starting at the
+// same position are tried, then the search resumes one character further.
+type wordMatcher struct {
+	search.Matcher
+}
+
+func (m *wordMatcher) Match(line string) (bool, error) {
+	spans, err := m.FindAll(line)
+	return len(spans) > 0, err
+}
+
+func (m *wordMatcher) FindAll(line string) ([]search.Span, error) {
+	found, err := m.Matcher.FindAll(line)
+	offset := 0 // found are the matches of line[offset:]
+	var spans []search.Span
+	for err == nil && len(found) > 0 {
+		sp := search.Span{Start: offset + found[0].Start, End: offset + found[0].End}
+		found = found[1:]
+		if n := len(spans); n > 0 && sp.Start == sp.End && sp.Start == spans[n-1].End {
+			continue // an empty match right after a match isn't one
+		}
+
+		before, _ := utf8.DecodeLastRuneInString(line[:sp.Start])
+		end, ok := -1, !isWordRune(before)
+		if ok {
+			if end, ok, err = m.wordEnd(line, sp); err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			spans = append(spans, search.Span{Start: sp.Start, End: end})
+			if end == sp.End {
+				continue
+			}
+		}
+
+		// search again after the word, or one character after the start
+		next := end
+		if !ok || end == sp.Start {
+			_, size := utf8.DecodeRuneInString(line[sp.Start:])
+			next = sp.Start + max(size, 1)
+		}
+		if next > len(line) {
+			break
+		}
+		found, err = m.Matcher.FindAll(line[next:])
+		offset = next
+	}
+	if err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+// wordEnd returns the end of the longest match starting at sp.Start, and
+// ending at or before sp.End, that is followed by a non-word character or
+// the end of line. sp is a leftmost-longest match.
+func (m *wordMatcher) wordEnd(line string, sp search.Span) (int, bool, error) {
+	for end := sp.End; ; {
+		after, _ := utf8.DecodeRuneInString(line[end:])
+		if !isWordRune(after) {
+			if end == sp.End {
+				return end, true, nil
+			}
+			found, err := m.Matcher.FindAll(line[sp.Start:end])
+			if err != nil {
+				return 0, false, err
+			}
+			if len(found) > 0 && found[0] == (search.Span{Start: 0, End: end - sp.Start}) {
+				return end, true, nil
+			}
+		}
+		if end == sp.Start {
+			return 0, false, nil
+		}
+		_, size := utf8.DecodeLastRuneInString(line[sp.Start:end])
+		end -= size
+	}
+}
+
+// isWordRune reports whether r is a word constituent: a letter, digit or underscore.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lineMatcher keeps only matches spanning the whole line (-x). The wrapped
+// matcher must report leftmost-longest spans.
+type lineMatcher struct {
+	search.Matcher
+}
+
+func (m *lineMatcher) Match(line string) (bool, error) {
+	spans, err := m.FindAll(line)
+	return len(spans) > 0, err
+}
+
+func (m *lineMatcher) FindAll(line string) ([]search.Span, error) {
+	spans, err := m.Matcher.FindAll(line)
+	if err != nil || len(spans) == 0 || spans[0] != (search.Span{Start: 0, End: len(line)}) {
+		return nil, err
+	}
+	return spans[:1], nil
+}
@@ -0,0 +1,138 @@
+package grepper
+
+import (
+	"errors"
+	"grep/internal/backtrack"
+	"grep/search"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatcher_FindAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		cfg     Config
+		line    string
+		want    []search.Span
+	}{
+		{"regex", "o+", Config{}, "foo boo", []search.Span{{Start: 1, End: 3}, {Start: 5, End: 7}}},
+		{"regex_ignore_case", "b.", Config{IgnoreCase: true}, "aBc bd", []search.Span{{Start: 1, End: 3}, {Start: 4, End: 6}}},
+		{"regex_no_match", "z", Config{}, "foo", nil},
+		{"fixed", "a.", Config{Fixed: true}, "a.a.xa.", []search.Span{{Start: 0, End: 2}, {Start: 2, End: 4}, {Start: 5, End: 7}}},
+		{"fixed_ignore_case", "straße", Config{Fixed: true, IgnoreCase: true}, "STRAßE and Straße", []search.Span{{Start: 0, End: 7}, {Start: 12, End: 19}}},
+		{"fixed_ignore_case_kelvin", "k", Config{Fixed: true, IgnoreCase: true}, "K", []search.Span{{Start: 0, End: 3}}},
+		{"fixed_full_fold", "ss", Config{Fixed: true, IgnoreCase: true}, "Straße STRASSE", []search.Span{{Start: 4, End: 6}, {Start: 12, End: 14}}},
+		{"fixed_full_fold_pattern", "MASSE", Config{Fixed: true, IgnoreCase: true}, "maße", []search.Span{{Start: 0, End: 5}}},
+		{"fixed_full_fold_partial", "s", Config{Fixed: true, IgnoreCase: true}, "ß", nil},
+		{"fixed_full_fold_ligature", "FILE", Config{Fixed: true, IgnoreCase: true}, "ﬁle", []search.Span{{Start: 0, End: 5}}},
+		{"fixed_full_fold_dotted_i", "İ", Config{Fixed: true, IgnoreCase: true}, "i i\u0307", []search.Span{{Start: 2, End: 5}}},
+		{"fixed_ignore_case_invalid_utf8", "\ufffd", Config{Fixed: true, IgnoreCase: true}, "\xff \ufffd", []search.Span{{Start: 2, End: 5}}},
+		{"fixed_ignore_case_invalid_pattern", "a\xff", Config{Fixed: true, IgnoreCase: true}, "A\xfe A\xff", []search.Span{{Start: 3, End: 5}}},
+		{"fixed_empty", "", Config{Fixed: true}, "ab", []search.Span{{Start: 0, End: 0}, {Start: 1, End: 1}, {Start: 2, End: 2}}},
+		{"basic", `\(ab\)\{2\}`, Config{Syntax: SyntaxBasic}, "ab abab", []search.Span{{Start: 3, End: 7}}},
+		{"basic_literals", `a+(b)`, Config{Syntax: SyntaxBasic}, "aab a+(b)", []search.Span{{Start: 4, End: 9}}},
+		{"basic_backref", `\(.\)\1`, Config{Syntax: SyntaxBasic}, "abccdd", []search.Span{{Start: 2, End: 4}, {Start: 4, End: 6}}},
+		{"extended", `(ab){2}|x+`, Config{Syntax: SyntaxExtended}, "abab xx", []search.Span{{Start: 0, End: 4}, {Start: 5, End: 7}}},
+		{"extended_word_anchors", `\<th`, Config{Syntax: SyntaxExtended}, "the other", []search.Span{{Start: 0, End: 2}}},
+		{"perl_lookahead", `foo(?=bar)`, Config{Syntax: SyntaxPerl}, "foobaz foobar", []search.Span{{Start: 7, End: 10}}},
+		{"perl_lookbehind_ignore_case", `(?<=\$)[a-z]+`, Config{Syntax: SyntaxPerl, IgnoreCase: true}, "A $USD", []search.Span{{Start: 3, End: 6}}},
+		{"word", "foo", Config{WordRegexp: true}, "foo_ foo", []search.Span{{Start: 5, End: 8}}},
+		{"word_retry_shorter", "b|b c", Config{Syntax: SyntaxExtended, WordRegexp: true}, "b cd", []search.Span{{Start: 0, End: 1}}},
+		{"word_retry_next", "b.*", Config{WordRegexp: true}, "ab b", []search.Span{{Start: 3, End: 4}}},
+		{"word_retry_extended", "ab|b", Config{Syntax: SyntaxExtended, WordRegexp: true}, "xab ab", []search.Span{{Start: 4, End: 6}}},
+		{"word_empty", "", Config{WordRegexp: true}, "", []search.Span{{Start: 0, End: 0}}},
+		{"word_empty_after_space", "", Config{WordRegexp: true}, "a ", []search.Span{{Start: 2, End: 2}}},
+		{"word_empty_between_words", "", Config{WordRegexp: true}, "a b", nil},
+		{"perl_word", `a\w*`, Config{Syntax: SyntaxPerl, WordRegexp: true}, "ba abc a", []search.Span{{Start: 3, End: 6}, {Start: 7, End: 8}}},
+		{"perl_line", `a|ab`, Config{Syntax: SyntaxPerl, LineRegexp: true}, "ab", []search.Span{{Start: 0, End: 2}}},
+		{"fuzzy", "connection", Config{Fuzzy: 1}, "conection lost, connecton", []search.Span{{Start: 0, End: 9}, {Start: 16, End: 25}}},
+		{"fuzzy_too_far", "connection", Config{Fuzzy: 1}, "cnnexion", nil},
+		{"fuzzy_ignore_case", "straße", Config{Fuzzy: 1, IgnoreCase: true}, "STRASE", []search.Span{{Start: 0, End: 6}}},
+		{"fuzzy_word", "cat", Config{Fuzzy: 1, WordRegexp: true}, "concat ca", []search.Span{{Start: 7, End: 9}}},
+		{"fuzzy_line", "connection", Config{Fuzzy: 2, LineRegexp: true}, "conection", []search.Span{{Start: 0, End: 9}}},
+		{"fuzzy_line_too_far", "connection", Config{Fuzzy: 2, LineRegexp: true}, "connection ok", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := buildMatcher([]string{tt.pattern}, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := m.FindAll(tt.line); err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindAll(%q) = %v, %v, want %v", tt.line, got, err, tt.want)
+			}
+			if got, err := m.Match(tt.line); err != nil || got != (tt.want != nil) {
+				t.Errorf("Match(%q) = %v, %v, want %v", tt.line, got, err, tt.want != nil)
+			}
+		})
+	}
+}
+
+func TestMatcher_BacktrackBudget(t *testing.T) {
+	g, err := New([]string{`(a|a)+b`}, Config{Syntax: SyntaxPerl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	n, err := g.Grep(strings.NewReader("ab\n"+strings.Repeat("a", 40)+"cb\nab\n"), "app.log", &out)
+	if !errors.Is(err, backtrack.ErrStepLimit) {
+		t.Errorf("got error %v, want %v", err, backtrack.ErrStepLimit)
+	}
+	if n != 1 || out.String() != "ab\n" {
+		t.Errorf("got %d lines %q before the error, want 1 line", n, out.String())
+	}
+}
+
+func TestTranslatePOSIX(t *testing.T) {
+	tests := []struct {
+		pattern      string
+		extended     bool
+		want         string
+		backtracking bool
+	}{
+		{`a\(b\|c\)*\{1,\}`, false, `a(b|c)*{1,}`, false},
+		{`a+?(b){|}`, false, `a\+\?\(b\)\{\|\}`, false},
+		{`*a\(*b\)`, false, `\*a(\*b)`, false},
+		{`^^a$$`, false, `^\^a\$$`, false},
+		{`a^b$c`, true, `a^b$c`, false},
+		{`\(a\)\1`, false, `(a)\1`, true},
+		{`\<a\>`, true, `\b(?=\w)a\b(?<=\w)`, true},
+		{`[]a\[:digit:]-]`, false, `[\]a\\[:digit:]-]`, false},
+		{`[^[=a=][.-.]]`, true, `[^a-]`, false},
+		{`x{,3}a{2`, true, `x{0,3}a\{2`, false},
+		{`*a|+b`, true, `\*a|\+b`, false},
+		{`a)`, true, `a\)`, false},
+		{`\w\.\\`, true, `\w\.\\`, false},
+	}
+	for _, tt := range tests {
+		got, backtracking, err := translatePOSIX(tt.pattern, tt.extended)
+		if err != nil {
+			t.Errorf("translatePOSIX(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got != tt.want || backtracking != tt.backtracking {
+			t.Errorf("translatePOSIX(%q) = %q, %v, want %q, %v", tt.pattern, got, backtracking, tt.want, tt.backtracking)
+		}
+	}
+
+	for _, p := range []string{`a\`, `[a`, `[[:nope:]]`, `\(a\)\2`, `a\{1`, `a\{x\}`} {
+		if _, _, err := translatePOSIX(p, false); err == nil {
+			t.Errorf("translatePOSIX(%q) succeeded, want an error", p)
+		}
+	}
+}
+
+func TestParseColors(t *testing.T) {
+	c := ParseColors("ms=01;32:fn=34:ne:xx=1")
+	want := DefaultColors
+	want.SelectedMatch, want.FileName, want.NoErase = "01;32", "34", true
+	if c != want {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+	if c := ParseColors("mt=07"); c.SelectedMatch != "07" || c.ContextMatch != "07" {
+		t.Errorf("mt should set both ms and mc, got %+v", c)
+	}
+}
@@ -0,0 +1,241 @@
+package grepper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	errTrailingBackslash = errors.New("trailing backslash (\\)")
+	errUnmatchedBracket  = errors.New("unmatched [, [^, [:, [., or [=")
+	errInvalidBackref    = errors.New("invalid back reference")
+	errInvalidInterval   = errors.New("invalid content of \\{\\}")
+)
+
+// posixClasses are the names allowed in [:name:].
+var posixClasses = map[string]bool{
+	"alnum": true, "alpha": true, "blank": true, "cntrl": true, "digit": true, "graph": true,
+	"lower": true, "print": true, "punct": true, "space": true, "upper": true, "xdigit": true,
+}
+
+// translatePOSIX rewrites a POSIX basic (-G) or extended (-E) regular
+// expression, with the GNU extensions, into the Perl-like syntax shared by
+// Go's regexp and the backtracking engine. It reports whether the result
+// needs the backtracking engine: for backreferences and the word anchors
+// \< and \>, which Go's regexp lacks.
+//
+// In basic expressions, \( \) \{ \} \| \+ and \? are operators and their
+// unescaped forms are literals; a '*' at the start of an expression or
+// group is a literal too, and ^ and $ are anchors only at the start and
+// end of one. In extended expressions, a '{' that doesn't start an
+// interval and an unmatched ')' are literals.
+func translatePOSIX(pattern string, extended bool) (string, bool, error) {
+	var b strings.Builder
+	backtracking := false
+	groups, depth := 0, 0
+	atStart := true // at the start of the expression, a group or an alternative
+	caret := false  // right after a leading ^ in a basic expression, where only '*' is literal
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		start, afterCaret := atStart, caret
+		atStart, caret = false, false
+
+		switch {
+		case c == '\\':
+			if i+1 == len(pattern) {
+				return "", false, errTrailingBackslash
+			}
+			d := pattern[i+1]
+			i += 2
+			switch {
+			case !extended && d == '(':
+				b.WriteByte('(')
+				groups++
+				depth++
+				atStart = true
+			case !extended && d == ')':
+				b.WriteByte(')')
+				depth--
+			case !extended && d == '|':
+				b.WriteByte('|')
+				atStart = true
+			case !extended && d == '{' && !start:
+				n, interval, err := translateInterval(pattern[i:], `\}`)
+				if err != nil {
+					return "", false, err
+				}
+				b.WriteString(interval)
+				i += n
+			case !extended && (d == '+' || d == '?') && !start:
+				b.WriteByte(d)
+			case '1' <= d && d <= '9':
+				if int(d-'0') > groups {
+					return "", false, errInvalidBackref
+				}
+				b.WriteString(pattern[i-2 : i])
+				backtracking = true
+			case d == '<':
+				b.WriteString(`\b(?=\w)`)
+				backtracking = true
+			case d == '>':
+				b.WriteString(`\b(?<=\w)`)
+				backtracking = true
+			case strings.IndexByte("bBwWsS", d) >= 0:
+				b.WriteString(pattern[i-2 : i])
+			case d == '`':
+				b.WriteString(`\A`)
+			case d == '\'':
+				b.WriteString(`\z`)
+			default:
+				writeLiteral(&b, d)
+			}
+			continue
+
+		case c == '[':
+			n, class, err := translateBracket(pattern[i:])
+			if err != nil {
+				return "", false, err
+			}
+			b.WriteString(class)
+			i += n
+			continue
+
+		case c == '*' && start, c == '+' && start, c == '?' && start:
+			writeLiteral(&b, c)
+		case c == '*', c == '.':
+			b.WriteByte(c)
+		case c == '^':
+			if extended || start && !afterCaret {
+				b.WriteByte(c)
+				atStart, caret = !extended, !extended
+			} else {
+				writeLiteral(&b, c)
+			}
+		case c == '$':
+			rest := pattern[i+1:]
+			if extended || rest == "" || strings.HasPrefix(rest, `\)`) || strings.HasPrefix(rest, `\|`) {
+				b.WriteByte(c)
+			} else {
+				writeLiteral(&b, c)
+			}
+
+		case extended && (c == '+' || c == '?'):
+			b.WriteByte(c)
+		case extended && c == '(':
+			b.WriteByte(c)
+			groups++
+			depth++
+			atStart = true
+		case extended && c == ')' && depth > 0:
+			b.WriteByte(c)
+			depth--
+		case extended && c == '|':
+			b.WriteByte(c)
+			atStart = true
+		case extended && c == '{' && !start:
+			if n, interval, err := translateInterval(pattern[i+1:], "}"); err == nil {
+				b.WriteString(interval)
+				i += 1 + n
+				continue
+			}
+			writeLiteral(&b, c)
+
+		default:
+			writeLiteral(&b, c)
+		}
+		i++
+	}
+	return b.String(), backtracking, nil
+}
+
+// translateInterval translates the rest of an interval, after its opening
+// brace, up to the closing brace end. It returns the number of bytes read.
+func translateInterval(s, end string) (int, string, error) {
+	n := strings.Index(s, end)
+	if n < 0 {
+		return 0, "", errInvalidInterval
+	}
+	lo, hi, isRange := strings.Cut(s[:n], ",")
+	if !isDigits(lo) || !isDigits(hi) || lo == "" && !isRange || lo == "" && hi == "" {
+		return 0, "", errInvalidInterval
+	}
+	if lo == "" {
+		lo = "0" // {,m}, a GNU extension
+	}
+	if !isRange {
+		return n + len(end), "{" + lo + "}", nil
+	}
+	return n + len(end), "{" + lo + "," + hi + "}", nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// translateBracket translates the bracket expression at the start of s and
+// returns its length. Backslashes are literal in bracket expressions.
+func translateBracket(s string) (int, string, error) {
+	var b strings.Builder
+	b.WriteByte('[')
+	i := 1
+	if strings.HasPrefix(s[i:], "^") {
+		b.WriteByte('^')
+		i++
+	}
+	if strings.HasPrefix(s[i:], "]") {
+		b.WriteString(`\]`)
+		i++
+	}
+	for ; i < len(s) && s[i] != ']'; i++ {
+		c := s[i]
+		if c == '[' && i+1 < len(s) && strings.IndexByte(":=.", s[i+1]) >= 0 {
+			kind := s[i+1]
+			n := strings.Index(s[i+2:], string(kind)+"]")
+			if n < 0 {
+				return 0, "", errUnmatchedBracket
+			}
+			name := s[i+2 : i+2+n]
+			switch {
+			case kind == ':' && !posixClasses[name]:
+				return 0, "", fmt.Errorf("invalid character class %q", name)
+			case kind == ':':
+				b.WriteString("[:" + name + ":]")
+			default:
+				// equivalence classes and collating symbols of single characters
+				for j := 0; j < len(name); j++ {
+					writeClassByte(&b, name[j])
+				}
+			}
+			i += n + 3
+			continue
+		}
+		writeClassByte(&b, c)
+	}
+	if i >= len(s) {
+		return 0, "", errUnmatchedBracket
+	}
+	b.WriteByte(']')
+	return i + 1, b.String(), nil
+}
+
+func writeClassByte(b *strings.Builder, c byte) {
+	if c == '\\' || c == '[' || c == ']' {
+		b.WriteByte('\\')
+	}
+	b.WriteByte(c)
+}
+
+// writeLiteral writes c so that it matches itself.
+func writeLiteral(b *strings.Builder, c byte) {
+	if strings.IndexByte(`\.+*?()|[]{}^$`, c) >= 0 {
+		b.WriteByte('\\')
+	}
+	b.WriteByte(c)
+}
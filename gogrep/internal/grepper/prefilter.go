@@ -0,0 +1,159 @@
+package grepper
+
+import (
+	"bytes"
+	"regexp/syntax"
+	"slices"
+
+	"grep/internal/ahocorasick"
+)
+
+// maxPrefilterLiterals bounds the number of alternative literals a
+// prefilter looks for; beyond that, matching lines one by one is as fast.
+const maxPrefilterLiterals = 64
+
+// prefilter finds the lines that may match in a buffer holding many lines:
+// every match contains one of its literals, so the lines without any of
+// them can be skipped without being split and matched one by one.
+type prefilter struct {
+	literal []byte               // a single literal, searched for with bytes.Index
+	ac      *ahocorasick.Matcher // several literals, or literals that ignore case
+}
+
+// newPrefilter returns the prefilter for patterns, or nil if no required
+// literals are known. Perl-compatible patterns and patterns needing the
+// backtracking engine aren't analyzed.
+func newPrefilter(patterns []string, opts Config) *prefilter {
+	if len(patterns) == 0 || opts.Fuzzy > 0 {
+		return nil
+	}
+
+	var lits []string
+	fold := opts.IgnoreCase
+	if opts.Fixed {
+		lits = patterns
+		if fold && !foldsLikeASCII(patterns) {
+			return nil
+		}
+	} else {
+		translated, backtracking, err := translatePatterns(patterns, opts.Syntax)
+		if err != nil || backtracking {
+			return nil
+		}
+		flags := syntax.Perl
+		if opts.IgnoreCase {
+			flags |= syntax.FoldCase
+		}
+		for _, p := range translated {
+			re, err := syntax.Parse(p, flags)
+			if err != nil {
+				return nil
+			}
+			req, ok := requiredLiterals(re.Simplify())
+			if !ok {
+				return nil
+			}
+			lits = append(lits, req.lits...)
+			fold = fold || req.fold
+		}
+	}
+
+	lits = slices.Compact(slices.Sorted(slices.Values(lits)))
+	if len(lits) > maxPrefilterLiterals || slices.Contains(lits, "") {
+		return nil
+	}
+	if len(lits) == 1 && !fold {
+		return &prefilter{literal: []byte(lits[0])}
+	}
+	return &prefilter{ac: ahocorasick.New(lits, fold)}
+}
+
+// Index returns the offset of an occurrence of a literal in b, such that
+// there is none before the line holding it, or -1 if there is none at all.
+func (p *prefilter) Index(b []byte) int {
+	if p.ac != nil {
+		return p.ac.IndexBytes(b)
+	}
+	return bytes.Index(b, p.literal)
+}
+
+// literalSet is a set of strings, one of which occurs in every match of a
+// regular expression.
+type literalSet struct {
+	lits []string
+	fold bool // the literals match regardless of ASCII case
+}
+
+// better reports whether s narrows down the candidates more than o: its
+// shortest literal is longer, or it has fewer literals.
+func (s literalSet) better(o literalSet) bool {
+	if a, b := shortest(s.lits), shortest(o.lits); a != b {
+		return a > b
+	}
+	return len(s.lits) < len(o.lits)
+}
+
+func shortest(lits []string) int {
+	n := -1
+	for _, l := range lits {
+		if n < 0 || len(l) < n {
+			n = len(l)
+		}
+	}
+	return n
+}
+
+// requiredLiterals returns literals one of which occurs in every match of
+// re; ok is false if there are none that are easy to find.
+func requiredLiterals(re *syntax.Regexp) (set literalSet, ok bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		s := string(re.Rune)
+		fold := re.Flags&syntax.FoldCase != 0
+		if fold && !foldsLikeASCII([]string{s}) {
+			return literalSet{}, false
+		}
+		return literalSet{lits: []string{s}, fold: fold}, true
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return literalSet{}, false
+		}
+		return requiredLiterals(re.Sub[0])
+
+	case syntax.OpConcat:
+		// adjacent literals are required together
+		var subs []*syntax.Regexp
+		for _, sub := range re.Sub {
+			if n := len(subs); n > 0 && sub.Op == syntax.OpLiteral && subs[n-1].Op == syntax.OpLiteral &&
+				sub.Flags&syntax.FoldCase == subs[n-1].Flags&syntax.FoldCase {
+				merged := *subs[n-1]
+				merged.Rune = append(slices.Clip(merged.Rune), sub.Rune...)
+				subs[n-1] = &merged
+				continue
+			}
+			subs = append(subs, sub)
+		}
+		for _, sub := range subs {
+			if s, subOK := requiredLiterals(sub); subOK && (!ok || s.better(set)) {
+				set, ok = s, true
+			}
+		}
+		return set, ok
+
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			s, subOK := requiredLiterals(sub)
+			if !subOK {
+				return literalSet{}, false
+			}
+			set.lits = append(set.lits, s.lits...)
+			set.fold = set.fold || s.fold
+		}
+		return set, len(set.lits) <= maxPrefilterLiterals
+	}
+	return literalSet{}, false
+}
@@ -0,0 +1,180 @@
+package grepper
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"reflect"
+	"regexp/syntax"
+	"strings"
+	"testing"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string // nil: none
+		fold    bool
+	}{
+		{`ERROR`, []string{"ERROR"}, false},
+		{`\d+ ERROR: .*`, []string{" ERROR: "}, false},
+		{`(?i)error`, []string{"ERROR"}, true},
+		{`(?i)disk`, nil, false}, // k also folds to the Kelvin sign
+		{`(WARN|ERROR)+ x`, []string{"WARN", "ERROR"}, false},
+		{`id=(\d+|none)`, []string{"id="}, false},
+		{`a(bc)?d`, []string{"a"}, false},
+		{`(foo)*`, nil, false},
+		{`[a-z]+`, nil, false},
+		{`timeout|.*`, nil, false},
+		{`x{2,}yz`, []string{"yz"}, false},
+	}
+	for _, tt := range tests {
+		re, err := syntax.Parse(tt.pattern, syntax.Perl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set, ok := requiredLiterals(re.Simplify())
+		if !ok {
+			set.lits = nil
+		}
+		if !reflect.DeepEqual(set.lits, tt.want) || set.fold != tt.fold {
+			t.Errorf("requiredLiterals(%q) = %q (fold %v), want %q (fold %v)", tt.pattern, set.lits, set.fold, tt.want, tt.fold)
+		}
+	}
+}
+
+// syntheticLog returns n lines of log, about one in rate of which has an
+// error; some lines have CRLF, the last one may lack its line break.
+func syntheticLog(n, rate int, seed uint64) string {
+	rnd := rand.New(rand.NewPCG(seed, 0))
+	levels := []string{"INFO", "DEBUG", "INFO", "WARN"}
+	var b strings.Builder
+	for i := range n {
+		level := levels[rnd.IntN(len(levels))]
+		if rnd.IntN(rate) == 0 {
+			level = []string{"ERROR", "error", "Error"}[rnd.IntN(3)]
+		}
+		fmt.Fprintf(&b, "2025-07-16T10:%02d:%02dZ %s request id=%d path=/api/v%d/users status=%d took %dms",
+			i/60%60, i%60, level, i, rnd.IntN(3), []int{200, 404, 500}[rnd.IntN(3)], rnd.IntN(900))
+		switch {
+		case i == n-1 && rnd.IntN(2) == 0:
+		case rnd.IntN(10) == 0:
+			b.WriteString("\r\n")
+		default:
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// TestPrefilter_SameResults checks that skipping lines with the prefilter
+// doesn't change the output.
+func TestPrefilter_SameResults(t *testing.T) {
+	input := syntheticLog(20000, 50, 1)
+	configs := []Config{
+		{},
+		{WithLineNo: true, ByteOffset: true},
+		{CountOnly: true},
+		{Before: 3, After: 2, WithLineNo: true},
+		{Context: 1, MaxCount: 5},
+		{IgnoreCase: true, OnlyMatching: true},
+		{Invert: true, CountOnly: true},
+		{FilesWithMatches: true},
+		{JSON: true, Before: 1},
+		{Syntax: SyntaxExtended, WordRegexp: true, WithLineNo: true},
+	}
+	patterns := [][]string{{"ERROR"}, {"ERROR took [0-9]+"}, {"status=500 took 8"}, {"WARN", "Error"}, {"id=1234[0-9]"}, {"(ERROR|error) request"}}
+
+	// the same lines terminated by NUL, some with newlines
+	records := strings.NewReplacer("\r\n", "\n", "\n", "\x00").Replace(input)
+	for _, cfg := range configs {
+		nullCfg := cfg
+		nullCfg.NullData = true
+		configs = append(configs, nullCfg)
+	}
+
+	for _, cfg := range configs {
+		input := input
+		if cfg.NullData {
+			input = records
+		}
+		for _, pats := range patterns {
+			g, err := New(pats, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.prefilter == nil {
+				continue
+			}
+			var want, got strings.Builder
+			plain := *g
+			plain.prefilter = nil
+			wantN, wantErr := plain.Grep(strings.NewReader(input), "app.log", &want)
+			gotN, gotErr := g.Grep(strings.NewReader(input), "app.log", &got)
+			if gotN != wantN || gotErr != wantErr || got.String() != want.String() {
+				t.Errorf("%q with %+v: got %d lines (%v), want %d (%v)", pats, cfg, gotN, gotErr, wantN, wantErr)
+			}
+		}
+	}
+}
+
+func TestPrefilter_BinaryInSkippedLines(t *testing.T) {
+	input := strings.Repeat("line\n", 100) + "nul\x00\n" + strings.Repeat("line\n", 100) + "ERROR\n"
+	for _, mode := range []BinaryMode{BinaryDefault, BinaryWithoutMatch} {
+		var out strings.Builder
+		g, err := New([]string{"ERROR"}, Config{Binary: mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := g.Grep(strings.NewReader(input), "app.log", &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[BinaryMode]string{BinaryDefault: "Binary file app.log matches\n", BinaryWithoutMatch: ""}[mode]
+		if out.String() != want || n != map[BinaryMode]int{BinaryDefault: 1}[mode] {
+			t.Errorf("%s: got %d lines, %q, want %q", mode, n, out.String(), want)
+		}
+	}
+}
+
+// BenchmarkSearch compares the search of a large log with and without the
+// literal prefilter.
+func BenchmarkSearch(b *testing.B) {
+	input := syntheticLog(200000, 1000, 2)
+	benchmarks := []struct {
+		name     string
+		patterns []string
+		cfg      Config
+	}{
+		{"literal", []string{"ERROR"}, Config{}},
+		{"literal_count", []string{"ERROR"}, Config{CountOnly: true}},
+		{"regex_literal", []string{`ERROR request id=[0-9]+`}, Config{}},
+		{"regex_inner_literal", []string{`[0-9]+Z ERROR`}, Config{WithLineNo: true}},
+		{"alternation", []string{`(ERROR|FATAL|PANIC) request`}, Config{}},
+		{"ignore_case", []string{"error"}, Config{IgnoreCase: true}},
+		{"fixed_patterns", []string{"ERROR", "FATAL", "PANIC"}, Config{Fixed: true}},
+		{"context", []string{"ERROR"}, Config{Before: 2, After: 2}},
+		{"no_match", []string{"zzz[0-9]"}, Config{}},
+	}
+	for _, bm := range benchmarks {
+		g, err := New(bm.patterns, bm.cfg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		plain := *g
+		plain.prefilter = nil
+		for _, v := range []struct {
+			name string
+			g    *Grepper
+		}{{"prefilter", g}, {"line_by_line", &plain}} {
+			b.Run(bm.name+"/"+v.name, func(b *testing.B) {
+				b.SetBytes(int64(len(input)))
+				for b.Loop() {
+					if _, err := v.g.Grep(strings.NewReader(input), "app.log", io.Discard); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package grepper
+
+import (
+	"bufio"
+	"fmt"
+	"grep/search"
+	"strconv"
+)
+
+// output formats the results of searching one input. The searcher decides
+// what is printed; outputs decide how.
+type output interface {
+	// line writes a selected (sep ':') or context (sep '-') line; spans
+	// are the matches to highlight.
+	line(l line, sep byte, spans []search.Span) error
+	// onlyMatching writes the matches of a selected line (-o).
+	onlyMatching(l line, spans []search.Span) error
+	// separator writes the line between non-adjacent groups of context.
+	separator(sep string) error
+	// binaryMatches reports a match in a binary input instead of the line.
+	binaryMatches(l line) error
+	// fileName reports the input for -l and -L.
+	fileName() error
+	// count reports the number of selected lines (-c).
+	count(n int) error
+	// end is called after the input is searched.
+	end(stats Stats) error
+}
+
+// printer writes grep's text output.
+type printer struct {
+	out    *bufio.Writer
+	name   string
+	opts   Config
+	colors *Colors // nil disables highlighting
+}
+
+// line writes l, highlighting spans, with its terminator as read (one is
+// added to a last line without). sep is ':' for selected lines and '-' for
+// context lines.
+func (p *printer) line(l line, sep byte, spans []search.Span) error {
+	column := 0
+	if len(spans) > 0 {
+		column = spans[0].Start + 1
+	}
+	p.prefix(l, sep, column, l.offset)
+
+	lineColor, matchColor := p.lineColors(sep)
+	pos := 0
+	for _, sp := range spans {
+		if sp.Start == sp.End {
+			continue
+		}
+		p.colored(lineColor, l.text[pos:sp.Start])
+		p.colored(matchColor, l.text[sp.Start:sp.End])
+		pos = sp.End
+	}
+	p.colored(lineColor, l.text[pos:])
+	if l.eol == "" {
+		return p.out.WriteByte(p.opts.delim())
+	}
+	_, err := p.out.WriteString(l.eol)
+	return err
+}
+
+// onlyMatching writes every non-empty match of l on its own line (-o).
+func (p *printer) onlyMatching(l line, spans []search.Span) error {
+	_, matchColor := p.lineColors(':')
+	for _, sp := range spans {
+		if sp.Start == sp.End {
+			continue
+		}
+		p.prefix(l, ':', sp.Start+1, l.offset+int64(sp.Start))
+		p.colored(matchColor, l.text[sp.Start:sp.End])
+		if err := p.out.WriteByte(p.opts.delim()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// binaryMatches writes grep's notice instead of the matching line.
+func (p *printer) binaryMatches(line) error {
+	_, err := fmt.Fprintf(p.out, "Binary file %s matches\n", p.name)
+	return err
+}
+
+func (p *printer) end(Stats) error {
+	return nil
+}
+
+// fileName writes the name of the input on its own line (-l, -L).
+func (p *printer) fileName() error {
+	p.colored(p.color(func(c *Colors) string { return c.FileName }), p.name)
+	return p.out.WriteByte('\n')
+}
+
+// count writes the number of selected lines (-c), prefixed with the
+// file name if enabled.
+func (p *printer) count(n int) error {
+	if p.opts.WithFilename {
+		p.field(p.color(func(c *Colors) string { return c.FileName }), p.name, ':')
+	}
+	p.out.WriteString(strconv.Itoa(n))
+	return p.out.WriteByte('\n')
+}
+
+// separator writes the line between groups of context.
+func (p *printer) separator(sep string) error {
+	p.colored(p.color(func(c *Colors) string { return c.Separator }), sep)
+	return p.out.WriteByte('\n')
+}
+
+// prefix writes the file name, line number, column and byte offset
+// prefixes that are turned on. A zero column isn't printed.
+func (p *printer) prefix(l line, sep byte, column int, offset int64) {
+	if p.opts.WithFilename {
+		p.field(p.color(func(c *Colors) string { return c.FileName }), p.name, sep)
+	}
+	if p.opts.WithLineNo {
+		p.field(p.color(func(c *Colors) string { return c.LineNumber }), strconv.Itoa(l.no), sep)
+	}
+	if p.opts.Column && column > 0 {
+		p.field(p.color(func(c *Colors) string { return c.LineNumber }), strconv.Itoa(column), sep)
+	}
+	if p.opts.ByteOffset {
+		p.field(p.color(func(c *Colors) string { return c.ByteOffset }), strconv.FormatInt(offset, 10), sep)
+	}
+}
+
+// field writes a prefix value followed by sep.
+func (p *printer) field(sgr, value string, sep byte) {
+	p.colored(sgr, value)
+	p.colored(p.color(func(c *Colors) string { return c.Separator }), string(sep))
+}
+
+// lineColors returns the colors of the whole line and of the matches
+// for selected (':') or context ('-') lines.
+func (p *printer) lineColors(sep byte) (string, string) {
+	if p.colors == nil {
+		return "", ""
+	}
+	if sep == ':' {
+		return p.colors.SelectedLine, p.colors.SelectedMatch
+	}
+	return p.colors.ContextLine, p.colors.ContextMatch
+}
+
+func (p *printer) color(get func(*Colors) string) string {
+	if p.colors == nil {
+		return ""
+	}
+	return get(p.colors)
+}
+
+// colored writes s wrapped in the sgr color sequences; "" means no color.
+func (p *printer) colored(sgr, s string) {
+	if s == "" {
+		return
+	}
+	if sgr == "" {
+		p.out.WriteString(s)
+		return
+	}
+	p.out.WriteString(p.colors.start(sgr))
+	p.out.WriteString(s)
+	p.out.WriteString(p.colors.end())
+}
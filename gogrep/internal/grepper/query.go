@@ -0,0 +1,354 @@
+package grepper
+
+import (
+	"errors"
+	"fmt"
+	"grep/search"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A query (--query) combines strings and regular expressions with AND, OR,
+// NOT and parentheses:
+//
+//	query := or
+//	or    := and {"OR" and}
+//	and   := not {"AND" not}
+//	not   := "NOT" not | "(" query ")" | leaf
+//	leaf  := word | '"' string '"' | '/' regexp '/'
+//
+// NOT binds tighter than AND, which binds tighter than OR. Words and quoted
+// strings are fixed strings, matched approximately with --fuzzy; in quoted
+// strings, \" and \\ are a quote and a backslash. Regular expressions are
+// in the syntax selected by -G, -E or -P, and \/ is a slash. Keywords are
+// upper case: quote "AND" to look for it.
+
+// queryNode is a node of the matcher tree of a query.
+type queryNode interface {
+	match(line string) (bool, error)
+	// positives appends the matchers of the leaves that aren't negated,
+	// whose matches are highlighted.
+	positives(dst []search.Matcher, negated bool) []search.Matcher
+}
+
+type (
+	queryLeaf struct{ search.Matcher }
+	queryAnd  []queryNode
+	queryOr   []queryNode
+	queryNot  struct{ queryNode }
+)
+
+func (n queryLeaf) match(line string) (bool, error) { return n.Match(line) }
+
+func (n queryLeaf) positives(dst []search.Matcher, negated bool) []search.Matcher {
+	if negated {
+		return dst
+	}
+	return append(dst, n.Matcher)
+}
+
+func (n queryAnd) match(line string) (bool, error) {
+	for _, sub := range n {
+		if ok, err := sub.match(line); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (n queryAnd) positives(dst []search.Matcher, negated bool) []search.Matcher {
+	for _, sub := range n {
+		dst = sub.positives(dst, negated)
+	}
+	return dst
+}
+
+func (n queryOr) match(line string) (bool, error) {
+	for _, sub := range n {
+		if ok, err := sub.match(line); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (n queryOr) positives(dst []search.Matcher, negated bool) []search.Matcher {
+	for _, sub := range n {
+		dst = sub.positives(dst, negated)
+	}
+	return dst
+}
+
+func (n queryNot) match(line string) (bool, error) {
+	ok, err := n.queryNode.match(line)
+	return !ok && err == nil, err
+}
+
+func (n queryNot) positives(dst []search.Matcher, negated bool) []search.Matcher {
+	return n.queryNode.positives(dst, !negated)
+}
+
+// queryMatcher selects the lines that satisfy a query and match the
+// patterns, if any. Its matches are those of the leaves that aren't negated
+// and of the patterns.
+type queryMatcher struct {
+	root      queryNode
+	highlight anyMatcher
+	text      search.Matcher // nil without patterns
+}
+
+func newQueryMatcher(patterns []string, opts Config) (search.Matcher, error) {
+	leafOpts := opts
+	leafOpts.Query = ""
+	root, err := parseQuery(opts.Query, leafOpts)
+	if err != nil {
+		return nil, err
+	}
+	m := &queryMatcher{root: root, highlight: root.positives(nil, false)}
+	if len(patterns) > 0 {
+		if m.text, err = buildMatcher(patterns, leafOpts); err != nil {
+			return nil, err
+		}
+		m.highlight = append(m.highlight, m.text)
+	}
+	return m, nil
+}
+
+func (m *queryMatcher) Match(line string) (bool, error) {
+	if ok, err := m.root.match(line); !ok || err != nil {
+		return false, err
+	}
+	if m.text == nil {
+		return true, nil
+	}
+	return m.text.Match(line)
+}
+
+func (m *queryMatcher) FindAll(line string) ([]search.Span, error) {
+	return m.highlight.FindAll(line)
+}
+
+// parseQuery compiles a query into a matcher tree whose leaves are built
+// with opts.
+func parseQuery(query string, opts Config) (queryNode, error) {
+	p := &queryParser{src: query, opts: opts}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, errors.New("invalid query: empty")
+	}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected()
+	}
+	return n, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokString // a word or a quoted string
+	tokRegexp
+)
+
+var queryKeywords = map[string]tokenKind{"AND": tokAnd, "OR": tokOr, "NOT": tokNot}
+
+type token struct {
+	kind tokenKind
+	text string // the string or regular expression, or the keyword
+	pos  int    // byte offset in the query
+}
+
+// queryParser is a recursive descent parser of queries, with one token of
+// lookahead.
+type queryParser struct {
+	src  string
+	pos  int
+	tok  token
+	opts Config
+}
+
+func (p *queryParser) or() (queryNode, error) {
+	n, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	alts := queryOr{n}
+	for p.tok.kind == tokOr {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, n)
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return alts, nil
+}
+
+func (p *queryParser) and() (queryNode, error) {
+	n, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	all := queryAnd{n}
+	for p.tok.kind == tokAnd {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, n)
+	}
+	if len(all) == 1 {
+		return all[0], nil
+	}
+	return all, nil
+}
+
+func (p *queryParser) not() (queryNode, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNot:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{n}, nil
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			if p.tok.kind == tokEOF {
+				return nil, fmt.Errorf("invalid query: missing ) for ( at offset %d", tok.pos)
+			}
+			return nil, p.unexpected()
+		}
+		return n, p.next()
+	case tokString, tokRegexp:
+		leafOpts := p.opts
+		leafOpts.Fixed = tok.kind == tokString
+		if tok.kind == tokRegexp {
+			leafOpts.Fuzzy = 0 // --fuzzy applies to strings
+		}
+		m, err := buildMatcher([]string{tok.text}, leafOpts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %s: %w", tok.text, err)
+		}
+		return queryLeaf{m}, p.next()
+	default:
+		return nil, p.unexpected()
+	}
+}
+
+// unexpected returns the error for the current token.
+func (p *queryParser) unexpected() error {
+	switch p.tok.kind {
+	case tokEOF:
+		return errors.New("invalid query: unexpected end")
+	case tokString, tokRegexp:
+		return fmt.Errorf("invalid query: expected AND or OR before %q at offset %d", p.tok.text, p.tok.pos)
+	default:
+		return fmt.Errorf("invalid query: unexpected %s at offset %d", p.tok.text, p.tok.pos)
+	}
+}
+
+// next reads the next token into p.tok.
+func (p *queryParser) next() error {
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		p.pos += size
+	}
+	start := p.pos
+	if start == len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return nil
+	}
+
+	switch c := p.src[start]; c {
+	case '(', ')':
+		kind := tokLParen
+		if c == ')' {
+			kind = tokRParen
+		}
+		p.pos++
+		p.tok = token{kind: kind, text: string(c), pos: start}
+		return nil
+	case '"', '/':
+		text, err := p.delimited(c)
+		if err != nil {
+			return err
+		}
+		kind := tokString
+		if c == '/' {
+			kind = tokRegexp
+		}
+		p.tok = token{kind: kind, text: text, pos: start}
+		return nil
+	}
+
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if unicode.IsSpace(r) || r == '(' || r == ')' {
+			break
+		}
+		p.pos += size
+	}
+	word := p.src[start:p.pos]
+	kind, ok := queryKeywords[word]
+	if !ok {
+		kind = tokString
+	}
+	p.tok = token{kind: kind, text: word, pos: start}
+	return nil
+}
+
+// delimited reads a quoted string or a regular expression, which starts
+// at p.pos with the delimiter delim, and returns its text. A backslash
+// before the delimiter escapes it; in strings, a backslash also escapes a
+// backslash, while regular expressions keep their other escapes as is.
+func (p *queryParser) delimited(delim byte) (string, error) {
+	start := p.pos
+	var b strings.Builder
+	for i := start + 1; i < len(p.src); i++ {
+		switch c := p.src[i]; {
+		case c == delim:
+			p.pos = i + 1
+			return b.String(), nil
+		case c == '\\' && i+1 < len(p.src) && (p.src[i+1] == delim || delim == '"' && p.src[i+1] == '\\'):
+			i++
+			b.WriteByte(p.src[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("invalid query: unterminated %c at offset %d", delim, start)
+}
@@ -0,0 +1,97 @@
+package grepper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGrepLines_Query(t *testing.T) {
+	input := `ERROR db connection lost
+ERROR cache miss: timeout
+WARN db slow
+error db retry
+ERROR cached value AND more
+INFO all fine
+`
+	tests := []struct {
+		name     string
+		query    string
+		patterns []string
+		cfg      Config
+		want     string
+	}{
+		{"and_or_not", "ERROR AND (db OR cache) AND NOT timeout", nil, Config{},
+			"ERROR db connection lost\nERROR cached value AND more\n"},
+		{"precedence", "WARN OR ERROR AND NOT cache", nil, Config{},
+			"ERROR db connection lost\nWARN db slow\n"},
+		{"not_not", "NOT NOT WARN", nil, Config{}, "WARN db slow\n"},
+		{"ignore_case", "error AND db", nil, Config{IgnoreCase: true},
+			"ERROR db connection lost\nerror db retry\n"},
+		{"word", "ERROR AND cache", nil, Config{WordRegexp: true}, "ERROR cache miss: timeout\n"},
+		{"quoted", `"AND more" OR "miss: "`, nil, Config{},
+			"ERROR cache miss: timeout\nERROR cached value AND more\n"},
+		{"regexp", `/^[A-Z]{4,5} db/ AND NOT /s(low|ucks)/`, nil, Config{}, "ERROR db connection lost\n"},
+		{"regexp_basic", `/\(db\|cache\) \(slow\|miss\)/`, nil, Config{Syntax: SyntaxBasic},
+			"ERROR cache miss: timeout\nWARN db slow\n"},
+		{"with_pattern", "db OR cache", []string{"^ERROR"}, Config{},
+			"ERROR db connection lost\nERROR cache miss: timeout\nERROR cached value AND more\n"},
+		{"invert_count", "ERROR OR WARN", nil, Config{Invert: true, CountOnly: true}, "2\n"},
+		{"context", "fine", nil, Config{Before: 1, WithLineNo: true},
+			"5-ERROR cached value AND more\n6:INFO all fine\n"},
+		{"only_matching", "(ERROR OR WARN) AND NOT timeout AND /d[a-z]/", nil, Config{OnlyMatching: true},
+			"ERROR\ndb\nWARN\ndb\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Query = tt.query
+			g, err := New(tt.patterns, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if _, err := g.Grep(strings.NewReader(input), StdinName, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestGrepLines_QueryHighlight checks that the matches of every leaf that
+// isn't negated are highlighted.
+func TestGrepLines_QueryHighlight(t *testing.T) {
+	colors := DefaultColors
+	var out strings.Builder
+	_, err := GrepLines(strings.NewReader("ERROR db and cache\n"), &out, "", Config{Query: "ERROR AND (cache OR redis) AND NOT (db AND timeout)", Colors: &colors})
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := func(s string) string { return "\x1b[01;31m\x1b[K" + s + "\x1b[m\x1b[K" }
+	if want := match("ERROR") + " db and " + match("cache") + "\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"  ", "invalid query: empty"},
+		{"a AND", "invalid query: unexpected end"},
+		{"(a OR b", "invalid query: missing ) for ( at offset 0"},
+		{"a b", `invalid query: expected AND or OR before "b" at offset 2`},
+		{"a OR OR b", "invalid query: unexpected OR at offset 5"},
+		{"a)", "invalid query: unexpected ) at offset 1"},
+		{`"abc`, `invalid query: unterminated " at offset 0`},
+		{`a AND /x(/`, "invalid query: x(: error parsing regexp: missing closing ): `x(`"},
+	}
+	for _, tt := range tests {
+		_, err := New(nil, Config{Query: tt.query})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("New with query %q: got error %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package grepper
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+
+	"grep/search"
+	"lineio"
+)
+
+// template is a parsed --replace replacement: literal text and references
+// to the groups of the match, in the syntax of regexp.Regexp.Expand.
+type template struct {
+	parts  []templatePart
+	groups bool // some part refers to a group other than $0
+}
+
+// templatePart is literal text, or a reference to a group by number or name.
+type templatePart struct {
+	text string
+	ref  bool
+}
+
+// newTemplate parses a replacement: $1 or ${1} is the text of the first
+// group, $name or ${name} the text of the named group, $0 the whole match
+// and $$ a dollar sign. As in regexp, $name takes the longest sequence of
+// letters, digits and underscores, so $1x is ${1x}; write ${1}x. A dollar
+// sign that starts no reference is literal. It returns nil for nil.
+func newTemplate(replacement *string) *template {
+	if replacement == nil {
+		return nil
+	}
+	t := &template{}
+	s := *replacement
+	for s != "" {
+		i := strings.IndexByte(s, '$')
+		if i < 0 {
+			t.literal(s)
+			break
+		}
+		t.literal(s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "$$") {
+			t.literal("$")
+			s = s[2:]
+			continue
+		}
+		name, rest, ok := refName(s)
+		if !ok {
+			t.literal("$")
+			s = s[1:]
+			continue
+		}
+		t.parts = append(t.parts, templatePart{text: name, ref: true})
+		t.groups = t.groups || name != "0"
+		s = rest
+	}
+	return t
+}
+
+// literal appends literal text to t.
+func (t *template) literal(s string) {
+	if s == "" {
+		return
+	}
+	if n := len(t.parts); n > 0 && !t.parts[n-1].ref {
+		t.parts[n-1].text += s
+		return
+	}
+	t.parts = append(t.parts, templatePart{text: s})
+}
+
+// refName parses the reference at the start of s, which starts with '$',
+// and returns the group name (or number) and the rest of s.
+func refName(s string) (name, rest string, ok bool) {
+	s = s[1:]
+	braced := strings.HasPrefix(s, "{")
+	if braced {
+		s = s[1:]
+	}
+	n := 0
+	for n < len(s) && (s[n] == '_' || 'a' <= s[n] && s[n] <= 'z' || 'A' <= s[n] && s[n] <= 'Z' || '0' <= s[n] && s[n] <= '9') {
+		n++
+	}
+	if n == 0 {
+		return "", "", false
+	}
+	name, s = s[:n], s[n:]
+	if braced {
+		if !strings.HasPrefix(s, "}") {
+			return "", "", false
+		}
+		s = s[1:]
+	}
+	return name, s, true
+}
+
+// replace returns line with the matches of m at spans replaced by the
+// expansion of t, and the spans of the replacements in the result.
+func (t *template) replace(m search.Matcher, line string, spans []search.Span) (string, []search.Span, error) {
+	locs := make([][]int, len(spans))
+	owners := make([]submatcher, len(spans))
+	if t.groups {
+		// find the groups of every match, from the matcher that found it
+		for _, sm := range submatchers(m) {
+			all, err := sm.findAllSubmatch(line)
+			if err != nil {
+				return "", nil, err
+			}
+			for i, sp := range spans {
+				for _, loc := range all {
+					if locs[i] == nil && loc[0] == sp.Start && loc[1] == sp.End {
+						locs[i], owners[i] = loc, sm
+						break
+					}
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	replaced := make([]search.Span, len(spans))
+	pos := 0
+	for i, sp := range spans {
+		b.WriteString(line[pos:sp.Start])
+		loc := locs[i]
+		if loc == nil {
+			loc = []int{sp.Start, sp.End}
+		}
+		start := b.Len()
+		t.expand(&b, line, loc, owners[i])
+		replaced[i] = search.Span{Start: start, End: b.Len()}
+		pos = sp.End
+	}
+	b.WriteString(line[pos:])
+	return b.String(), replaced, nil
+}
+
+// expand writes t for the match of line at loc, whose groups are those of
+// sm's patterns (nil if the match has no groups). References to groups that
+// don't exist or didn't participate in the match are empty.
+func (t *template) expand(b *strings.Builder, line string, loc []int, sm submatcher) {
+	for _, p := range t.parts {
+		if !p.ref {
+			b.WriteString(p.text)
+			continue
+		}
+		group, err := strconv.Atoi(p.text)
+		if err != nil {
+			group = -1
+			if sm != nil {
+				group = sm.subexpIndex(p.text)
+			}
+		}
+		if group >= 0 && 2*group+1 < len(loc) && loc[2*group] >= 0 {
+			b.WriteString(line[loc[2*group]:loc[2*group+1]])
+		}
+	}
+}
+
+// submatcher is implemented by the matchers of patterns that may have
+// capturing groups.
+type submatcher interface {
+	// findAllSubmatch returns the matches in line with the spans of their
+	// groups, like regexp.Regexp.FindAllStringSubmatchIndex.
+	findAllSubmatch(line string) ([][]int, error)
+	// subexpIndex returns the index of the named group, or -1.
+	subexpIndex(name string) int
+}
+
+// submatchers returns the matchers of m that may have found its matches
+// and know their groups.
+func submatchers(m search.Matcher) []submatcher {
+	var sms []submatcher
+	for _, leaf := range leaves(m) {
+		if sm, ok := leaf.(submatcher); ok {
+			sms = append(sms, sm)
+		}
+	}
+	return sms
+}
+
+func (m *regexMatcher) findAllSubmatch(line string) ([][]int, error) {
+	return m.re.FindAllStringSubmatchIndex(line, -1), nil
+}
+
+func (m *regexMatcher) subexpIndex(name string) int {
+	return m.re.SubexpIndex(name)
+}
+
+func (m *backtrackMatcher) findAllSubmatch(line string) ([][]int, error) {
+	return m.re.FindAllStringSubmatchIndex(line)
+}
+
+func (m *backtrackMatcher) subexpIndex(name string) int {
+	return m.re.SubexpIndex(name)
+}
+
+// Rewrite copies r to w with the matches of the selected lines replaced by
+// the expansion of Config.Replace, to edit files in place. Lines are
+// selected as by Search, except that -v selects nothing: non-matching lines
+// have nothing to replace. Other lines are copied as is, and the input isn't
+// transcoded. An input that looks binary, unless it's searched as text, isn't
+// rewritten: Rewrite stops and reports no selected lines.
+func (g *Grepper) Rewrite(ctx context.Context, r io.Reader, w io.Writer) (Stats, error) {
+	t := newTemplate(g.opts.Replace)
+	if t == nil || g.opts.Invert {
+		return Stats{}, nil
+	}
+	binaryOK := g.opts.Binary == BinaryText || g.opts.NullData
+	in := lineio.NewReaderSize(r, g.opts.delim(), readBufferSize)
+	out := bufio.NewWriter(w)
+	var stats Stats
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		rec, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return stats, err
+		}
+		stats.BytesSearched += int64(len(rec.Text) + len(rec.EOL))
+		if !binaryOK && strings.IndexByte(rec.Text, 0) >= 0 {
+			return Stats{BytesSearched: stats.BytesSearched}, nil
+		}
+
+		text, err := g.rewrite(t, rec.Text, &stats)
+		if err != nil {
+			return stats, err
+		}
+		if _, err := out.WriteString(text); err != nil {
+			return stats, err
+		}
+		if _, err := out.WriteString(rec.EOL); err != nil {
+			return stats, err
+		}
+	}
+	return stats, out.Flush()
+}
+
+// rewrite returns line with its matches replaced if it's selected, and
+// counts it in stats.
+func (g *Grepper) rewrite(t *template, line string, stats *Stats) (string, error) {
+	if g.opts.MaxCount > 0 && stats.MatchedLines >= g.opts.MaxCount {
+		return line, nil
+	}
+	if ok, err := g.matcher.Match(line); !ok || err != nil {
+		return line, err
+	}
+	spans, err := g.matcher.FindAll(line)
+	if err != nil {
+		return "", err
+	}
+	stats.MatchedLines++
+	stats.Matches += len(spans)
+	line, _, err = t.replace(g.matcher, line, spans)
+	return line, err
+}
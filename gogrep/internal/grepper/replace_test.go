@@ -0,0 +1,153 @@
+package grepper
+
+import (
+	"context"
+	"errors"
+	"grep/internal/backtrack"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewTemplate(t *testing.T) {
+	tests := []struct {
+		replacement string
+		want        []templatePart
+		groups      bool
+	}{
+		{"XXXX", []templatePart{{text: "XXXX"}}, false},
+		{"$1-XXXX", []templatePart{{text: "1", ref: true}, {text: "-XXXX"}}, true},
+		{"${1}x", []templatePart{{text: "1", ref: true}, {text: "x"}}, true},
+		{"$1x", []templatePart{{text: "1x", ref: true}}, true},
+		{"<$0>", []templatePart{{text: "<"}, {text: "0", ref: true}, {text: ">"}}, false},
+		{"$$5 ${name}", []templatePart{{text: "$5 "}, {text: "name", ref: true}}, true},
+		{"$ ${ $-", []templatePart{{text: "$ ${ $-"}}, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		got := newTemplate(&tt.replacement)
+		if !reflect.DeepEqual(got.parts, tt.want) || got.groups != tt.groups {
+			t.Errorf("newTemplate(%q) = %+v (groups %v), want %+v (groups %v)", tt.replacement, got.parts, got.groups, tt.want, tt.groups)
+		}
+	}
+	if newTemplate(nil) != nil {
+		t.Error("newTemplate(nil) isn't nil")
+	}
+}
+
+func TestGrepLines_Replace(t *testing.T) {
+	input := "card=4111111111111111 ok\nno card\ncard=5500000000000004 card=4012888888881881\n"
+	tests := []struct {
+		name        string
+		patterns    []string
+		replacement string
+		cfg         Config
+		want        string
+	}{
+		{"groups", []string{`card=(\d{4})\d+`}, "card=$1-XXXX", Config{},
+			"card=4111-XXXX ok\ncard=5500-XXXX card=4012-XXXX\n"},
+		{"only_matching", []string{`card=(\d{4})\d+`}, "$1", Config{OnlyMatching: true, WithLineNo: true},
+			"1:4111\n3:5500\n3:4012\n"},
+		{"named", []string{`card=(?P<bin>\d{6})\d*(?P<last>\d{4})`}, "${bin}...${last}", Config{},
+			"411111...1111 ok\n550000...0004 401288...1881\n"},
+		{"perl", []string{`(?<=card=)(\d{2})\d+(?=\d{4})`}, "$1**", Config{Syntax: SyntaxPerl},
+			"card=41**1111 ok\ncard=55**0004 card=40**1881\n"},
+		{"perl_named", []string{`card=(?<bin>\d{4})\d+`}, "[$bin]", Config{Syntax: SyntaxPerl},
+			"[4111] ok\n[5500] [4012]\n"},
+		{"basic", []string{`card=\([0-9]\{4\}\)[0-9]*`}, "$1", Config{Syntax: SyntaxBasic},
+			"4111 ok\n5500 4012\n"},
+		{"fixed", []string{"card"}, "[$0]", Config{Fixed: true, IgnoreCase: true},
+			"[card]=4111111111111111 ok\nno [card]\n[card]=5500000000000004 [card]=4012888888881881\n"},
+		{"several_patterns", []string{`ok$`, `no (c)ard`}, "<$1>", Config{},
+			"card=4111111111111111 <>\n<c>\n"},
+		{"word", []string{`(c)ard`}, "$1", Config{WordRegexp: true},
+			"c=4111111111111111 ok\nno c\nc=5500000000000004 c=4012888888881881\n"},
+		{"empty_replacement", []string{`=\d+`}, "", Config{},
+			"card ok\ncard card\n"},
+		{"context_not_replaced", []string{`no`}, "yes", Config{Before: 1, WithLineNo: true},
+			"1-card=4111111111111111 ok\n2:yes card\n"},
+		{"invert", []string{`card=`}, "x", Config{Invert: true},
+			"no card\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Replace = &tt.replacement
+			g, err := New(tt.patterns, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			if _, err := g.Grep(strings.NewReader(input), StdinName, &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepLines_ReplaceColors(t *testing.T) {
+	colors := DefaultColors
+	repl := "<$1>"
+	var out strings.Builder
+	if _, err := GrepLines(strings.NewReader("a1 b2\n"), &out, `[a-z](\d)`, Config{Replace: &repl, Colors: &colors}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\x1b[01;31m\x1b[K<1>\x1b[m\x1b[K \x1b[01;31m\x1b[K<2>\x1b[m\x1b[K\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestGrepper_Rewrite(t *testing.T) {
+	input := "id=1 token=abc\r\nplain\nid=2 token=def\nid=3 token=ghi"
+	repl := "token=<$1>"
+	tests := []struct {
+		name  string
+		input string
+		cfg   Config
+		want  string
+		lines int
+	}{
+		{"all", input, Config{}, "id=1 token=<a>\r\nplain\nid=2 token=<d>\nid=3 token=<g>", 3},
+		{"max_count", input, Config{MaxCount: 1}, "id=1 token=<a>\r\nplain\nid=2 token=def\nid=3 token=ghi", 1},
+		{"invert", input, Config{Invert: true}, "", 0},
+		{"binary", "token=x\n\x00\n", Config{}, "", 0},
+		{"binary_as_text", "token=x\n\x00\n", Config{Binary: BinaryText}, "token=<x>\n\x00\n", 1},
+		{"null_data", "token=x\x00token=y\n\x00", Config{NullData: true}, "token=<x>\x00token=<y>\n\x00", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Replace = &repl
+			g, err := New([]string{`token=(\w)\w*`}, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out strings.Builder
+			stats, err := g.Rewrite(context.Background(), strings.NewReader(tt.input), &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.MatchedLines != tt.lines || tt.lines > 0 && out.String() != tt.want {
+				t.Errorf("got %d lines, %q, want %d, %q", stats.MatchedLines, out.String(), tt.lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepper_RewriteBacktrackBudget(t *testing.T) {
+	repl := "x"
+	g, err := New([]string{`(a|a)+b`}, Config{Syntax: SyntaxPerl, Replace: &repl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	stats, err := g.Rewrite(context.Background(), strings.NewReader("ab\n"+strings.Repeat("a", 40)+"cb\n"), &out)
+	if !errors.Is(err, backtrack.ErrStepLimit) {
+		t.Errorf("got error %v, want %v", err, backtrack.ErrStepLimit)
+	}
+	if stats.MatchedLines != 1 {
+		t.Errorf("got %d lines rewritten before the error, want 1", stats.MatchedLines)
+	}
+}
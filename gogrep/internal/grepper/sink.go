@@ -0,0 +1,224 @@
+package grepper
+
+import (
+	"bufio"
+
+	"grep/internal/fields"
+	"grep/search"
+)
+
+// line is an input line with its 1-based number and the byte offset of
+// its start in the input.
+type line struct {
+	no     int
+	offset int64
+	text   string // without the line terminator
+	eol    string // line terminator as read: "\n", "\r\n", "\x00" with NullData, or "" at EOF
+
+	distances []int // edit distances of the matches with --fuzzy and --json
+}
+
+// newLine returns the line of the output for a line of the search.
+func newLine(l search.Line) line {
+	return line{no: l.Number, offset: l.Offset, text: l.Text, eol: l.EOL}
+}
+
+// sink receives the results of searching one input and writes them with an
+// output: the sink decides what is printed, the output how. It's the
+// search.Sink of the text and JSON formats.
+type sink struct {
+	matcher search.Matcher
+	opts    Config
+	out     *bufio.Writer
+	printer output
+	replace *template        // nil without --replace
+	fuzzy   []search.Matcher // matchers of the --fuzzy matches, for --json
+
+	lastPrinted int // number of the last printed line, 0 if none
+	matches     int // matches in selected lines, counted when spans are found
+}
+
+func newSink(matcher search.Matcher, name string, out *bufio.Writer, opts Config) *sink {
+	return &sink{
+		matcher: matcher,
+		opts:    opts,
+		out:     out,
+		printer: newOutput(out, name, opts),
+		replace: newTemplate(opts.Replace),
+		fuzzy:   fuzzyLeaves(matcher, opts),
+	}
+}
+
+func (s *sink) Begin(string) error {
+	return nil
+}
+
+func (s *sink) Matched(l search.Line) error {
+	return s.printSelected(newLine(l))
+}
+
+func (s *sink) Context(l search.Line) error {
+	return s.printContext(newLine(l))
+}
+
+func (s *sink) Binary(l search.Line) error {
+	return s.printer.binaryMatches(newLine(l))
+}
+
+// End writes the summary (count, file name) if one is requested.
+func (s *sink) End(stats search.Stats) error {
+	if err := s.summary(stats.MatchedLines); err != nil {
+		return err
+	}
+	return s.printer.end(s.stats(stats))
+}
+
+// Flush writes the buffered output, so that it isn't delayed while the
+// search waits for input.
+func (s *sink) Flush() error {
+	return s.out.Flush()
+}
+
+// stats completes the statistics of the search with the matches counted
+// by s.
+func (s *sink) stats(stats search.Stats) Stats {
+	return Stats{MatchedLines: stats.MatchedLines, Matches: s.matches, BytesSearched: stats.BytesSearched}
+}
+
+// summary writes the per-input result of -l, -L and -c.
+func (s *sink) summary(count int) error {
+	switch {
+	case s.opts.Quiet:
+		return nil
+	case s.opts.FilesWithMatches:
+		if count > 0 {
+			return s.printer.fileName()
+		}
+	case s.opts.FilesWithoutMatch:
+		if count == 0 {
+			return s.printer.fileName()
+		}
+	case s.opts.CountOnly:
+		return s.printer.count(count)
+	}
+	return nil
+}
+
+// separate writes the group separator if l doesn't follow the previously
+// printed line. Within an input, the sink separates the groups; between
+// inputs, it's up to the caller (see Grepper.GroupSeparator).
+func (s *sink) separate(l line) error {
+	defer func() { s.lastPrinted = l.no }()
+	if s.lastPrinted == 0 || l.no == s.lastPrinted+1 {
+		return nil
+	}
+	if sep, ok := s.opts.groupSeparator(); ok {
+		return s.printer.separator(sep)
+	}
+	return nil
+}
+
+// printSelected writes a selected line, or only its matches with -o.
+func (s *sink) printSelected(l line) error {
+	if err := s.separate(l); err != nil {
+		return err
+	}
+	l = s.project(l)
+	if s.opts.Invert {
+		if s.opts.OnlyMatching {
+			return nil // selected lines don't match
+		}
+		return s.printer.line(l, ':', nil)
+	}
+
+	var spans []search.Span
+	if s.opts.Colors != nil || s.opts.Column || s.opts.OnlyMatching || s.opts.JSON || s.replace != nil {
+		var err error
+		if spans, err = s.matcher.FindAll(l.text); err != nil {
+			return err
+		}
+		s.matches += len(spans)
+		if l.distances, err = s.distances(l.text, spans); err != nil {
+			return err
+		}
+	}
+	if s.replace != nil {
+		var err error
+		if l.text, spans, err = s.replace.replace(s.matcher, l.text, spans); err != nil {
+			return err
+		}
+	}
+	if s.opts.OnlyMatching {
+		return s.printer.onlyMatching(l, spans)
+	}
+	return s.printer.line(l, ':', spans)
+}
+
+// printContext writes a context line; with -v context lines are the
+// matching ones, so their matches are highlighted. With -o context lines
+// aren't printed, but still join groups, as in grep.
+func (s *sink) printContext(l line) error {
+	if err := s.separate(l); err != nil || s.opts.OnlyMatching {
+		return err
+	}
+	l = s.project(l)
+	var spans []search.Span
+	if s.opts.Invert && (s.opts.Colors != nil || s.opts.JSON) {
+		var err error
+		if spans, err = s.matcher.FindAll(l.text); err != nil {
+			return err
+		}
+		if l.distances, err = s.distances(l.text, spans); err != nil {
+			return err
+		}
+	}
+	return s.printer.line(l, '-', spans)
+}
+
+// fuzzyLeaves returns the matchers of m that may have found its matches,
+// when their edit distances are to be reported, or nil.
+func fuzzyLeaves(m search.Matcher, opts Config) []search.Matcher {
+	if opts.Fuzzy == 0 || !opts.JSON {
+		return nil
+	}
+	return leaves(m)
+}
+
+// distances returns the edit distances of the matches of line at spans
+// (--fuzzy), or nil if they aren't reported: the lowest distance to the
+// patterns within reach. Matches of exact patterns, like the regexps of a
+// query, are at distance 0.
+func (s *sink) distances(line string, spans []search.Span) ([]int, error) {
+	if s.fuzzy == nil {
+		return nil, nil
+	}
+	dists := make([]int, len(spans))
+	for i, sp := range spans {
+		text := line[sp.Start:sp.End]
+		dist := s.opts.Fuzzy
+		for _, m := range s.fuzzy {
+			if fm, ok := m.(*fuzzyMatcher); ok {
+				dist = min(dist, fm.m.Distance(text))
+				continue
+			}
+			matched, err := m.Match(text)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				dist = 0
+			}
+		}
+		dists[i] = dist
+	}
+	return dists, nil
+}
+
+// project keeps only the fields of a structured line that are to be
+// printed (--jq-project); the line is then matched again for highlighting.
+func (s *sink) project(l line) line {
+	if len(s.opts.Project) > 0 {
+		l.text = fields.Project(l.text, s.opts.Project)
+	}
+	return l
+}
@@ -0,0 +1,15 @@
+package grepper
+
+// Stats describe the search of one or more inputs.
+type Stats struct {
+	MatchedLines  int   // selected lines
+	Matches       int   // matches in selected lines; counted only when the output needs their positions (--json, -o, --column, --color, --replace)
+	BytesSearched int64 // bytes read from the inputs
+}
+
+// Add accumulates other into s.
+func (s *Stats) Add(other Stats) {
+	s.MatchedLines += other.MatchedLines
+	s.Matches += other.Matches
+	s.BytesSearched += other.BytesSearched
+}
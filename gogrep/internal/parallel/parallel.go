@@ -0,0 +1,94 @@
+// Package parallel runs independent tasks on a pool of workers while
+// delivering their results in submission order.
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// SubmitFunc queues a task. It blocks while too many results are pending
+// and returns false once the run is cancelled.
+type SubmitFunc[T any] func(task T) bool
+
+type job[T, R any] struct {
+	task   T
+	result chan R
+}
+
+// Ordered calls work for every task submitted by feed on n workers and
+// passes the results to emit one at a time, in the order the tasks were
+// submitted. At most 2*n results are buffered, so a slow emit throttles feed.
+//
+// The context passed to feed and work is cancelled when ctx is done, feed
+// fails or emit returns an error; Ordered then waits for the goroutines to
+// finish and returns the first error.
+func Ordered[T, R any](
+	ctx context.Context,
+	n int,
+	feed func(ctx context.Context, submit SubmitFunc[T]) error,
+	work func(ctx context.Context, task T) R,
+	emit func(R) error,
+) error {
+	n = max(n, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan job[T, R])
+	queue := make(chan chan R, 2*n)
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.result <- work(ctx, j.task)
+			}
+		}()
+	}
+
+	feedErr := make(chan error, 1)
+	go func() {
+		defer close(queue)
+		defer close(jobs)
+		feedErr <- feed(ctx, func(task T) bool {
+			j := job[T, R]{task: task, result: make(chan R, 1)}
+			select {
+			case queue <- j.result:
+			case <-ctx.Done():
+				return false
+			}
+			select {
+			case jobs <- j:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	var err error
+	for result := range queue {
+		if err != nil {
+			continue // drain so that feed can finish
+		}
+		select {
+		case r := <-result:
+			if err = emit(r); err != nil {
+				cancel()
+			}
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	wg.Wait()
+
+	if ferr := <-feedErr; err == nil {
+		err = ferr
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
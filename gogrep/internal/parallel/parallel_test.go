@@ -0,0 +1,84 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func feedInts(count int) func(context.Context, SubmitFunc[int]) error {
+	return func(ctx context.Context, submit SubmitFunc[int]) error {
+		for i := range count {
+			if !submit(i) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+}
+
+func TestOrdered_KeepsSubmissionOrder(t *testing.T) {
+	var got []int
+	err := Ordered(context.Background(), 8, feedInts(100),
+		func(_ context.Context, i int) int {
+			time.Sleep(time.Duration(rand.IntN(500)) * time.Microsecond)
+			return i * i
+		},
+		func(r int) error {
+			got = append(got, r)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := make([]int, 100)
+	for i := range want {
+		want[i] = i * i
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestOrdered_EmitErrorCancels(t *testing.T) {
+	stop := errors.New("stop")
+	var started atomic.Int32
+
+	err := Ordered(context.Background(), 4, feedInts(1_000_000),
+		func(ctx context.Context, i int) int {
+			started.Add(1)
+			return i
+		},
+		func(r int) error {
+			if r == 10 {
+				return stop
+			}
+			return nil
+		})
+	if !errors.Is(err, stop) {
+		t.Fatalf("got %v, want %v", err, stop)
+	}
+	if n := started.Load(); n > 100 {
+		t.Errorf("%d tasks started after the run was stopped at task 10", n)
+	}
+}
+
+func TestOrdered_ParentCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := Ordered(ctx, 2, feedInts(1_000_000),
+		func(ctx context.Context, i int) int { return i },
+		func(r int) error {
+			if r == 5 {
+				cancel()
+			}
+			return nil
+		})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,254 @@
+package walker
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Ignore files, read in every directory of a recursive walk. The rules of
+// .ignore take precedence over the ones of .gitignore, which only apply
+// inside a git repository, and .git/info/exclude applies to the whole
+// repository with the lowest precedence, as in git.
+const (
+	gitDir        = ".git"
+	gitIgnoreFile = ".gitignore"
+	ignoreFile    = ".ignore"
+)
+
+var gitExcludeFile = filepath.Join(gitDir, "info", "exclude")
+
+// ignoreRule is a pattern line of an ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp // matches the slash-separated path relative to the ignore file's directory
+	negate  bool           // "!pattern": re-include what earlier rules excluded
+	dirOnly bool           // "pattern/": only match directories
+}
+
+// ignoreSet holds the rules of the ignore files of a directory; its parent
+// holds the ones of the directories above it.
+type ignoreSet struct {
+	parent *ignoreSet
+	dir    string // the directory, slash-separated and relative to the top of the walk; "" is the top
+	rules  []ignoreRule
+	repo   bool // the directory is in a git repository
+}
+
+// ignored reports whether the path (relative to the top of the walk, like
+// dir) is excluded: the last rule that matches it decides, and rules of
+// deeper directories take precedence. A nil set ignores nothing.
+func (s *ignoreSet) ignored(path string, isDir bool) bool {
+	for ; s != nil; s = s.parent {
+		rel := path
+		if s.dir != "" {
+			rel = strings.TrimPrefix(path, s.dir+"/")
+		}
+		for i := len(s.rules) - 1; i >= 0; i-- {
+			r := s.rules[i]
+			if r.dirOnly && !isDir || !r.re.MatchString(rel) {
+				continue
+			}
+			return !r.negate
+		}
+	}
+	return false
+}
+
+// inRepo reports whether s is the set of a directory in a git repository.
+func (s *ignoreSet) inRepo() bool {
+	return s != nil && s.repo
+}
+
+// enterDir returns the rules in effect in the directory fsPath (named rel
+// relative to the top of the walk), given the ones of its parent.
+func enterDir(parent *ignoreSet, fsPath, rel string) (*ignoreSet, error) {
+	set := &ignoreSet{parent: parent, dir: rel, repo: parent.inRepo()}
+	files := []string{ignoreFile}
+	if _, err := os.Lstat(filepath.Join(fsPath, gitDir)); err == nil {
+		set.repo = true
+		files = []string{gitExcludeFile, gitIgnoreFile, ignoreFile}
+	} else if set.repo {
+		files = []string{gitIgnoreFile, ignoreFile}
+	}
+
+	for _, name := range files {
+		rules, err := readIgnoreFile(filepath.Join(fsPath, name))
+		if err != nil {
+			return parent, err
+		}
+		set.rules = append(set.rules, rules...)
+	}
+	if len(set.rules) == 0 && set.repo == parent.inRepo() {
+		return parent, nil
+	}
+	return set, nil
+}
+
+// readIgnoreFile returns the rules of the ignore file path; a missing file
+// has none.
+func readIgnoreFile(path string) ([]ignoreRule, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if r, ok := parseIgnoreRule(sc.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules, sc.Err()
+}
+
+// parseIgnoreRule compiles a line of an ignore file, in the format of
+// gitignore(5). It returns false for blank lines, comments and invalid
+// patterns.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = trimTrailingSpaces(strings.TrimSuffix(line, "\r"))
+	if line == "" || line[0] == '#' {
+		return ignoreRule{}, false
+	}
+
+	var r ignoreRule
+	if line[0] == '!' {
+		r.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// a slash at the start or in the middle anchors the pattern to the
+	// directory of the ignore file; otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	expr := globExpr(strings.TrimPrefix(line, "/"))
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// trimTrailingSpaces removes the trailing spaces of line that aren't escaped
+// with a backslash.
+func trimTrailingSpaces(line string) string {
+	end := len(line)
+	for end > 0 && line[end-1] == ' ' {
+		if end > 1 && line[end-2] == '\\' {
+			break
+		}
+		end--
+	}
+	return line[:end]
+}
+
+// globExpr translates a gitignore glob to a regular expression: "*" and "?"
+// don't match slashes, "**" as a whole path segment matches any number of
+// directories, and a backslash escapes the next character.
+func globExpr(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			segment := strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/')
+			switch {
+			case segment && i+2 == len(glob):
+				b.WriteString(".*")
+				i++
+			case segment && glob[i+2] == '/':
+				b.WriteString("(?:.*/)?")
+				i += 2
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, n := classExpr(glob[i:])
+			if n == 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(class)
+			i += n - 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
+
+// classExpr translates the bracket expression at the start of glob and
+// returns it with its length in glob, or 0 if the bracket isn't closed.
+// Like the other wildcards, bracket expressions don't match slashes.
+func classExpr(glob string) (string, int) {
+	i := 1
+	negate := i < len(glob) && (glob[i] == '!' || glob[i] == '^')
+	if negate {
+		i++
+	}
+	var b strings.Builder
+	b.WriteString("[")
+	if negate {
+		b.WriteString("^/")
+	}
+	for first := true; i < len(glob); i, first = i+1, false {
+		c := glob[i]
+		switch {
+		case c == ']' && !first:
+			b.WriteString("]")
+			return b.String(), i + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case c == '[' || c == ']' || c == '\\':
+			b.WriteString(`\` + string(c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0
+}
+
+// isHidden reports whether a file name is hidden, i.e. starts with a dot.
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// gitRoot returns the root of the git repository containing the absolute
+// directory path, i.e. the nearest directory at or above it with a .git entry.
+func gitRoot(path string) (string, bool) {
+	for {
+		if _, err := os.Lstat(filepath.Join(path, gitDir)); err == nil {
+			return path, true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", false
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,67 @@
+package walker
+
+import "testing"
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool // the rule matches
+	}{
+		{"*.log", "app.log", false, true},
+		{"*.log", "a/b/app.log", false, true},
+		{"*.log", "app.log.1", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build/", "src/build", true, true},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"doc/frotz", "doc/frotz", false, true},
+		{"doc/frotz", "a/doc/frotz", false, false},
+		{"doc/*.txt", "doc/a.txt", false, true},
+		{"doc/*.txt", "doc/sub/a.txt", false, false},
+		{"**/vendor", "vendor", true, true},
+		{"**/vendor", "a/b/vendor", true, true},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**", "a/x/y", false, true},
+		{"a/**", "a", true, false},
+		{"a**b", "axyb", false, true},
+		{"a**b", "ax/yb", false, false},
+		{"file?.go", "file1.go", false, true},
+		{"file?.go", "file/.go", false, false},
+		{"[a-c].txt", "b.txt", false, true},
+		{"[!a-c].txt", "d.txt", false, true},
+		{"[!a-c].txt", "a.txt", false, false},
+		{"[]a].txt", "].txt", false, true},
+		{"[].txt", "[].txt", false, true},
+		{`\#notes`, "#notes", false, true},
+		{`\!important`, "!important", false, true},
+		{`\*.md`, "*.md", false, true},
+		{`\*.md`, "a.md", false, false},
+		{"trailing   ", "trailing", false, true},
+		{`space\ `, "space ", false, true},
+		{"[unclosed", "[unclosed", false, true},
+	}
+	for _, tt := range tests {
+		r, ok := parseIgnoreRule(tt.pattern)
+		if !ok {
+			t.Errorf("parseIgnoreRule(%q) failed", tt.pattern)
+			continue
+		}
+		got := !(r.dirOnly && !tt.isDir) && r.re.MatchString(tt.path)
+		if got != tt.want {
+			t.Errorf("%q matches %q (dir %v) = %v, want %v", tt.pattern, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "   ", "# comment", "!", "/"} {
+		if _, ok := parseIgnoreRule(line); ok {
+			t.Errorf("parseIgnoreRule(%q) is a rule", line)
+		}
+	}
+	if r, _ := parseIgnoreRule("!keep.log"); !r.negate || !r.re.MatchString("keep.log") {
+		t.Errorf("!keep.log isn't a negated rule for keep.log")
+	}
+}
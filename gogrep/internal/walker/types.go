@@ -0,0 +1,57 @@
+package walker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// fileTypes maps the names of --type to the globs of their files' base names.
+var fileTypes = map[string][]string{
+	"c":        {"*.c", "*.h"},
+	"cpp":      {"*.cpp", "*.cc", "*.cxx", "*.hpp", "*.hh", "*.hxx", "*.h"},
+	"css":      {"*.css", "*.scss"},
+	"docker":   {"Dockerfile", "Dockerfile.*", "*.dockerfile"},
+	"go":       {"*.go"},
+	"html":     {"*.html", "*.htm"},
+	"java":     {"*.java"},
+	"js":       {"*.js", "*.mjs", "*.cjs", "*.jsx"},
+	"json":     {"*.json"},
+	"log":      {"*.log"},
+	"make":     {"Makefile", "makefile", "GNUmakefile", "*.mk"},
+	"markdown": {"*.md", "*.markdown"},
+	"md":       {"*.md", "*.markdown"},
+	"proto":    {"*.proto"},
+	"py":       {"*.py", "*.pyi"},
+	"rust":     {"*.rs"},
+	"sh":       {"*.sh", "*.bash", "*.zsh"},
+	"sql":      {"*.sql"},
+	"toml":     {"*.toml"},
+	"ts":       {"*.ts", "*.tsx", "*.mts", "*.cts"},
+	"txt":      {"*.txt"},
+	"xml":      {"*.xml"},
+	"yaml":     {"*.yaml", "*.yml"},
+}
+
+// typeGlobs returns the globs of the named file types.
+func typeGlobs(types []string) ([]string, error) {
+	var globs []string
+	for _, t := range types {
+		g, ok := fileTypes[t]
+		if !ok {
+			return nil, fmt.Errorf("unknown file type %q (known types: %s)", t, strings.Join(TypeNames(), ", "))
+		}
+		globs = append(globs, g...)
+	}
+	return globs, nil
+}
+
+// TypeNames returns the names of the known file types, sorted.
+func TypeNames() []string {
+	names := make([]string, 0, len(fileTypes))
+	for name := range fileTypes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
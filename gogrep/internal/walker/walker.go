@@ -0,0 +1,290 @@
+// Package walker lists the files gogrep searches: command-line operands and,
+// for recursive search, the files found below directory operands.
+package walker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Stdin is the operand that stands for standard input.
+const Stdin = "-"
+
+var (
+	// ErrIsDirectory is reported for directory operands of a non-recursive search.
+	ErrIsDirectory error = syscall.EISDIR
+	// ErrDirectoryLoop is reported for symbolic links leading back to a directory being walked.
+	ErrDirectoryLoop = errors.New("recursive directory loop")
+)
+
+// Options control which files are visited.
+type Options struct {
+	Recursive      bool     // -r: search directories recursively, skipping symbolic links met on the way
+	FollowSymlinks bool     // -R: like Recursive, but follow all symbolic links
+	Include        []string // --include=GLOB: search only files whose base name matches one of the globs
+	Exclude        []string // --exclude=GLOB: skip files whose base name matches one of the globs
+	ExcludeDir     []string // --exclude-dir=GLOB: skip subdirectories whose base name matches one of the globs
+	Types          []string // --type=TYPE: search only files of one of the types (see TypeNames)
+	Hidden         bool     // --hidden: also walk hidden files and directories, whose name starts with a dot
+	NoIgnore       bool     // --no-ignore: don't skip .git and the files excluded by .gitignore and .ignore files
+}
+
+// IsRecursive reports whether directories are descended into.
+func (o Options) IsRecursive() bool {
+	return o.Recursive || o.FollowSymlinks
+}
+
+// Validate checks the globs for syntax errors and the file types.
+func (o Options) Validate() error {
+	if _, err := typeGlobs(o.Types); err != nil {
+		return err
+	}
+	for _, globs := range [][]string{o.Include, o.Exclude, o.ExcludeDir} {
+		for _, g := range globs {
+			if _, err := filepath.Match(g, ""); err != nil {
+				return errors.New("invalid glob " + g + ": " + err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// VisitFunc is called for every file to search. If a path can't be visited,
+// err describes the problem and path should be skipped. A non-nil return
+// value stops the walk.
+type VisitFunc func(path string, err error) error
+
+// Walk calls fn for the files denoted by paths, in argument order; directory
+// contents are visited in lexical order. Without paths, the working directory
+// is walked for recursive search and standard input is searched otherwise.
+//
+// Unless opts.Hidden is set, hidden files and directories met during the walk
+// are skipped; unless opts.NoIgnore is set, so are .git directories and the
+// files excluded by the .gitignore and .ignore files of the walked
+// directories and, inside a git repository, of the directories above them up
+// to the repository's root. Operands are searched even if they are hidden or
+// ignored.
+//
+// Walk returns the error returned by fn, if any, or the error of invalid opts.
+func Walk(paths []string, opts Options, fn VisitFunc) error {
+	types, err := typeGlobs(opts.Types)
+	if err != nil {
+		return err
+	}
+	w := &walker{opts: opts, fn: fn, types: types}
+	if len(paths) == 0 {
+		if !opts.IsRecursive() {
+			return fn(Stdin, nil)
+		}
+		return w.top("")
+	}
+
+	for _, path := range paths {
+		if err := w.operand(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type walker struct {
+	opts  Options
+	fn    VisitFunc
+	types []string // globs of opts.Types
+}
+
+// operand visits a command-line path. Symbolic links given on the command
+// line are always followed.
+func (w *walker) operand(path string) error {
+	if path == Stdin {
+		return w.fn(path, nil)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return w.fn(path, err)
+	}
+	if info.IsDir() {
+		if !w.opts.IsRecursive() {
+			return w.fn(path, &fs.PathError{Op: "read", Path: path, Err: ErrIsDirectory})
+		}
+		return w.top(path)
+	}
+	if !w.selected(path) {
+		return nil
+	}
+	return w.fn(path, nil)
+}
+
+// top walks the directory operand path with the ignore rules of the
+// directories above it, up to the root of its git repository.
+func (w *walker) top(path string) error {
+	if w.opts.NoIgnore {
+		return w.dir(path, "", nil, nil)
+	}
+	abs, err := filepath.Abs(fsPath(path))
+	if err != nil {
+		return w.fn(path, err)
+	}
+	root, ok := gitRoot(abs)
+	if !ok || root == abs {
+		return w.dir(path, "", nil, nil)
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return w.fn(path, err)
+	}
+	rel = filepath.ToSlash(rel)
+	var ignores *ignoreSet
+	dir, relDir := root, ""
+	for _, name := range strings.Split(rel, "/") {
+		if ignores, err = enterDir(ignores, dir, relDir); err != nil {
+			if err := w.ignoreFileError(err); err != nil {
+				return err
+			}
+		}
+		dir, relDir = filepath.Join(dir, name), joinRel(relDir, name)
+	}
+	return w.dir(path, rel, nil, ignores)
+}
+
+// dir visits the contents of the directory path; "" is the working directory,
+// whose files are named without a "./" prefix. rel is the path relative to the
+// top of the walk that the ignore rules are matched against, ignores the
+// rules of the directories above it. ancestors are the directories being
+// walked above it, used to detect symbolic link loops.
+func (w *walker) dir(path, rel string, ancestors []os.FileInfo, ignores *ignoreSet) error {
+	fsPath := fsPath(path)
+	info, err := os.Stat(fsPath)
+	if err != nil {
+		return w.fn(path, err)
+	}
+	for _, a := range ancestors {
+		if os.SameFile(a, info) {
+			return w.fn(path, &fs.PathError{Op: "walk", Path: path, Err: ErrDirectoryLoop})
+		}
+	}
+	ancestors = append(ancestors, info)
+
+	entries, err := os.ReadDir(fsPath)
+	if err != nil {
+		return w.fn(path, err)
+	}
+	if !w.opts.NoIgnore {
+		if ignores, err = enterDir(ignores, fsPath, rel); err != nil {
+			if err := w.ignoreFileError(err); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if !w.opts.Hidden && isHidden(e.Name()) || !w.opts.NoIgnore && e.Name() == gitDir {
+			continue
+		}
+		child, childRel := join(path, e.Name()), joinRel(rel, e.Name())
+		typ := e.Type()
+		if typ&fs.ModeSymlink != 0 {
+			if !w.opts.FollowSymlinks {
+				continue
+			}
+			target, err := os.Stat(child)
+			if err != nil {
+				if err := w.fn(child, err); err != nil {
+					return err
+				}
+				continue
+			}
+			typ = target.Mode().Type()
+		}
+
+		if ignores.ignored(childRel, typ.IsDir()) {
+			continue
+		}
+
+		switch {
+		case typ.IsDir():
+			if matchAny(w.opts.ExcludeDir, e.Name()) {
+				continue
+			}
+			err = w.dir(child, childRel, ancestors, ignores)
+		case typ.IsRegular():
+			if !w.selected(child) {
+				continue
+			}
+			err = w.fn(child, nil)
+		default:
+			// devices, sockets and FIFOs met during recursion are skipped
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ignoreFileError reports the error of reading an ignore file; the walk
+// goes on without its rules.
+func (w *walker) ignoreFileError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return w.fn(pathErr.Path, err)
+	}
+	return w.fn("", err)
+}
+
+// selected reports whether the file passes --include, --exclude and --type.
+func (w *walker) selected(path string) bool {
+	name := filepath.Base(path)
+	if len(w.opts.Include) > 0 && !matchAny(w.opts.Include, name) {
+		return false
+	}
+	if len(w.types) > 0 && !matchAny(w.types, name) {
+		return false
+	}
+	return !matchAny(w.opts.Exclude, name)
+}
+
+func matchAny(globs []string, name string) bool {
+	for _, g := range globs {
+		if ok, _ := filepath.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// join appends name to dir keeping dir as given, like grep does: searching
+// "./src" reports "./src/main.go".
+func join(dir, name string) string {
+	switch {
+	case dir == "":
+		return name
+	case os.IsPathSeparator(dir[len(dir)-1]):
+		return dir + name
+	default:
+		return dir + string(filepath.Separator) + name
+	}
+}
+
+// joinRel appends name to the slash-separated relative path dir.
+func joinRel(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
+
+// fsPath returns the path to pass to the file system for the directory path,
+// where "" is the working directory.
+func fsPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
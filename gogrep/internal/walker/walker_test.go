@@ -0,0 +1,201 @@
+package walker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// makeTree creates files (with parent directories) under a temporary directory
+// and returns its path.
+func makeTree(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// collect walks paths and returns the visited files relative to root and the errors.
+func collect(t *testing.T, root string, paths []string, opts Options) ([]string, []error) {
+	t.Helper()
+	var files []string
+	var errs []error
+	err := Walk(paths, opts, func(path string, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			t.Fatal(relErr)
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files, errs
+}
+
+func TestWalk(t *testing.T) {
+	root := makeTree(t,
+		"a.go", "b.txt", "sub/c.go", "sub/d.log", "vendor/e.go", "z/sub/f.go",
+	)
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"recursive", Options{Recursive: true},
+			[]string{"a.go", "b.txt", "sub/c.go", "sub/d.log", "vendor/e.go", "z/sub/f.go"}},
+		{"include", Options{Recursive: true, Include: []string{"*.go"}},
+			[]string{"a.go", "sub/c.go", "vendor/e.go", "z/sub/f.go"}},
+		{"exclude", Options{Recursive: true, Exclude: []string{"*.go", "*.log"}},
+			[]string{"b.txt"}},
+		{"exclude_dir", Options{Recursive: true, ExcludeDir: []string{"vendor", "su?"}},
+			[]string{"a.go", "b.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := collect(t, root, []string{root}, tt.opts)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk_Operands(t *testing.T) {
+	root := makeTree(t, "a.txt", "dir/b.txt")
+
+	got, errs := collect(t, root, []string{
+		filepath.Join(root, "dir"),
+		filepath.Join(root, "a.txt"),
+		filepath.Join(root, "missing"),
+	}, Options{})
+
+	if !reflect.DeepEqual(got, []string{"a.txt"}) {
+		t.Errorf("got %v, want [a.txt]", got)
+	}
+	if len(errs) != 2 || !errors.Is(errs[0], ErrIsDirectory) || !errors.Is(errs[1], os.ErrNotExist) {
+		t.Errorf("got errors %v, want is a directory and not exist", errs)
+	}
+}
+
+func TestWalk_Symlinks(t *testing.T) {
+	root := makeTree(t, "dir/a.txt")
+	if err := os.Symlink(filepath.Join(root, "dir"), filepath.Join(root, "dir", "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "dir", "a.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, errs := collect(t, root, []string{root}, Options{Recursive: true})
+	if want := []string{"dir/a.txt"}; !reflect.DeepEqual(got, want) || len(errs) > 0 {
+		t.Errorf("-r: got %v %v, want %v", got, errs, want)
+	}
+
+	got, errs = collect(t, root, []string{root}, Options{FollowSymlinks: true})
+	if want := []string{"dir/a.txt", "link.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("-R: got %v, want %v", got, want)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrDirectoryLoop) {
+		t.Errorf("-R: got errors %v, want a directory loop", errs)
+	}
+}
+
+// writeFile writes content to the file name under root.
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalk_Ignore(t *testing.T) {
+	root := makeTree(t,
+		".git/HEAD", ".env", ".github/ci.yml", "a.go", "app.log", "keep.log",
+		"build/out.go", "vendor/v.go", "src/b.go", "src/gen.go", "src/tmp/c.go", "src/sub/gen.go",
+		"docs/x.md", "docs/y.md",
+	)
+	writeFile(t, root, ".gitignore", "*.log\n!keep.log\n/build/\nvendor/\ntmp/\n")
+	writeFile(t, root, "src/.gitignore", "gen.go\n!/sub/gen.go\n")
+	writeFile(t, root, "docs/.gitignore", "*.md\n")
+	writeFile(t, root, "docs/.ignore", "!y.md\n")
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"default", Options{Recursive: true},
+			[]string{"a.go", "docs/y.md", "keep.log", "src/b.go", "src/sub/gen.go"}},
+		{"hidden", Options{Recursive: true, Hidden: true},
+			[]string{".env", ".github/ci.yml", ".gitignore", "a.go", "docs/.gitignore", "docs/.ignore", "docs/y.md",
+				"keep.log", "src/.gitignore", "src/b.go", "src/sub/gen.go"}},
+		{"no_ignore", Options{Recursive: true, NoIgnore: true},
+			[]string{"a.go", "app.log", "build/out.go", "docs/x.md", "docs/y.md", "keep.log",
+				"src/b.go", "src/gen.go", "src/sub/gen.go", "src/tmp/c.go", "vendor/v.go"}},
+		{"type", Options{Recursive: true, Types: []string{"go"}},
+			[]string{"a.go", "src/b.go", "src/sub/gen.go"}},
+		{"types", Options{Recursive: true, NoIgnore: true, Types: []string{"md", "log"}},
+			[]string{"app.log", "docs/x.md", "docs/y.md", "keep.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := collect(t, root, []string{root}, tt.opts)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors %v", errs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+
+	// the rules of the directories above an operand apply up to the root of
+	// the repository; operands themselves are always searched
+	got, _ := collect(t, root, []string{filepath.Join(root, "src"), filepath.Join(root, "app.log")}, Options{Recursive: true})
+	if want := []string{"src/b.go", "src/sub/gen.go", "app.log"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("operands: got %v, want %v", got, want)
+	}
+}
+
+func TestWalk_GitignoreOutsideRepository(t *testing.T) {
+	root := makeTree(t, "a.go", "b.go")
+	writeFile(t, root, ".gitignore", "a.go\n")
+	writeFile(t, root, ".ignore", "b.go\n")
+
+	got, _ := collect(t, root, []string{root}, Options{Recursive: true})
+	if want := []string{"a.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	if err := (Options{Types: []string{"go", "md"}}).Validate(); err != nil {
+		t.Errorf("known types: %v", err)
+	}
+	if err := (Options{Types: []string{"golang"}}).Validate(); err == nil {
+		t.Error("unknown type: no error")
+	}
+	if err := (Options{Include: []string{"[a-"}}).Validate(); err == nil {
+		t.Error("invalid glob: no error")
+	}
+}
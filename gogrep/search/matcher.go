@@ -0,0 +1,40 @@
+package search
+
+import "regexp"
+
+// Span is the byte range [Start, End) of a match within a line.
+type Span struct {
+	Start, End int
+}
+
+// Matcher finds the pattern in lines. Matchers that can fail on a line,
+// like a backtracking engine running out of its budget, return an error,
+// which stops the search.
+type Matcher interface {
+	// Match reports whether line contains a match.
+	Match(line string) (bool, error)
+	// FindAll returns the spans of all successive non-overlapping matches
+	// in line, leftmost first.
+	FindAll(line string) ([]Span, error)
+}
+
+// Regexp returns a Matcher for the matches of re.
+func Regexp(re *regexp.Regexp) Matcher {
+	return regexpMatcher{re}
+}
+
+type regexpMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexpMatcher) Match(line string) (bool, error) {
+	return m.re.MatchString(line), nil
+}
+
+func (m regexpMatcher) FindAll(line string) ([]Span, error) {
+	var spans []Span
+	for _, loc := range m.re.FindAllStringIndex(line, -1) {
+		spans = append(spans, Span{loc[0], loc[1]})
+	}
+	return spans, nil
+}
@@ -0,0 +1,333 @@
+// Package search is the engine of gogrep for use in other programs: a
+// Searcher reads an input line by line, selects the lines that match a
+// Matcher, with their context, and reports them to a Sink, which decides
+// what to do with them. gogrep's own output formats are sinks.
+//
+//	s := search.New(search.Regexp(regexp.MustCompile(`ERROR|WARN`)), search.Options{After: 2})
+//	stats, err := s.Search(ctx, r, "app.log", sink)
+//
+// Inputs are processed as streams: memory use is bounded by the longest
+// line and the size of the before-context, and lines are reported as soon
+// as they are known, so endless inputs like `tail -f` can be searched.
+package search
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"lineio"
+)
+
+// bufferSize is the size of the input buffer; the prefilter skips lines a
+// buffer at a time.
+const bufferSize = 64 << 10
+
+// BinaryMode is how inputs with NUL bytes are searched, unless NUL
+// terminates lines (Options.NullData).
+type BinaryMode int
+
+const (
+	// BinaryQuit reports the first selected line of a binary input with
+	// Sink.Binary and stops, as grep does.
+	BinaryQuit BinaryMode = iota
+	// BinaryText searches binary inputs like any other.
+	BinaryText
+	// BinaryWithoutMatch stops at the first NUL byte: no further lines of
+	// a binary input are selected.
+	BinaryWithoutMatch
+)
+
+// Prefilter finds where the lines that may match are in a buffer holding
+// many lines, so that the lines before can be skipped without being
+// matched one by one.
+type Prefilter interface {
+	// Index returns an offset in b such that no line before the one
+	// holding it matches, or -1 if no line of b matches.
+	Index(b []byte) int
+}
+
+// Options control the selection of lines.
+type Options struct {
+	Invert    bool // select the lines that don't match
+	MaxCount  int  // stop after that many selected lines and their trailing context; 0 means no limit
+	Before    int  // lines of context before selected lines
+	After     int  // lines of context after selected lines
+	CountOnly bool // only count the selected lines, up to MaxCount: the sink gets no lines
+	NullData  bool // lines are terminated by NUL bytes instead of newlines
+	Binary    BinaryMode
+	Prefilter Prefilter // optional
+}
+
+// Stats describe the search of an input.
+type Stats struct {
+	MatchedLines  int   // selected lines
+	BytesSearched int64 // bytes read from the input
+}
+
+// Searcher searches inputs for the lines that match a Matcher. It is safe
+// to search several inputs with the same Searcher, concurrently if its
+// Matcher is safe for concurrent use.
+type Searcher struct {
+	matcher Matcher
+	opts    Options
+	delim   byte // line terminator
+}
+
+// New returns a Searcher for the lines matching m.
+func New(m Matcher, opts Options) *Searcher {
+	delim := lineio.Newline
+	if opts.NullData {
+		delim = lineio.NUL
+	}
+	opts.MaxCount = max(opts.MaxCount, 0)
+	opts.Before = max(opts.Before, 0)
+	opts.After = max(opts.After, 0)
+	return &Searcher{matcher: m, opts: opts, delim: delim}
+}
+
+// Search reads r to its end, or until the result is known, and reports the
+// input, named name, to sink. It stops with the first error of the Matcher
+// or the sink, or with ctx.Err() once ctx is done; cancellation is noticed
+// between lines.
+func (s *Searcher) Search(ctx context.Context, r io.Reader, name string, sink Sink) (Stats, error) {
+	in := &input{
+		Searcher: s,
+		ctx:      ctx,
+		sink:     sink,
+		before:   newRing(s.opts.Before),
+	}
+	if err := sink.Begin(name); err != nil {
+		return Stats{}, err
+	}
+	lr := lineio.NewReaderSize(r, s.delim, bufferSize)
+	if err := in.detectBinary(lr); err != nil {
+		return Stats{}, err
+	}
+	if err := in.scan(lr); err != nil {
+		return in.stats(), err
+	}
+	return in.stats(), sink.End(in.stats())
+}
+
+// input is the state of the search of one input.
+type input struct {
+	*Searcher
+	ctx  context.Context
+	sink Sink
+
+	before    *ring
+	afterLeft int // lines of trailing context still to report
+	count     int
+	bytes     int64
+
+	capped bool // MaxCount selected lines were found, only trailing context is left
+	binary bool // input contains NUL bytes
+	done   bool // the rest of the input doesn't change the result
+}
+
+func (in *input) stats() Stats {
+	return Stats{MatchedLines: in.count, BytesSearched: in.bytes}
+}
+
+// detectBinary looks for NUL bytes in the first buffered chunk of r.
+// It doesn't wait for the buffer to fill up, so streams aren't delayed.
+func (in *input) detectBinary(r *lineio.Reader) error {
+	if !in.detectsBinary() {
+		return nil
+	}
+	if err := r.Fill(); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	in.binary = bytes.IndexByte(r.Buffered(), 0) >= 0
+	in.done = in.binary && in.opts.Binary == BinaryWithoutMatch
+	return nil
+}
+
+// scan processes the lines of r until EOF or until the result is known.
+// The sink is flushed whenever r has no more buffered input, i.e. before
+// a read that may block waiting for the producer.
+func (in *input) scan(r *lineio.Reader) error {
+	cancel := in.ctx.Done()
+	flusher, _ := in.sink.(Flusher)
+	for no := 1; !in.done; no++ {
+		select {
+		case <-cancel:
+			return in.ctx.Err()
+		default:
+		}
+
+		if in.canSkip() {
+			if no = in.skip(r, no); in.done {
+				return nil
+			}
+		}
+		if flusher != nil && len(r.Buffered()) == 0 {
+			if err := flusher.Flush(); err != nil {
+				return err
+			}
+		}
+
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		l := Line{Number: no, Offset: in.bytes, Text: rec.Text, EOL: rec.EOL}
+		in.bytes += int64(len(rec.Text) + len(rec.EOL))
+		if err := in.process(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectsBinary reports whether inputs with NUL bytes are binary: unless
+// they are searched as text or NUL terminates lines.
+func (in *input) detectsBinary() bool {
+	return in.opts.Binary != BinaryText && !in.opts.NullData
+}
+
+// canSkip reports whether lines without the prefilter's literals can be
+// skipped: they can't be selected, nor be trailing context.
+func (in *input) canSkip() bool {
+	return in.opts.Prefilter != nil && !in.opts.Invert && !in.capped && in.afterLeft == 0
+}
+
+// skip discards the complete lines buffered in r before the first one that
+// may match, and returns the number of the next line. The last skipped
+// lines are kept for the before-context.
+func (in *input) skip(r *lineio.Reader, no int) int {
+	buf := r.Buffered()
+	end := len(buf)
+	if i := in.opts.Prefilter.Index(buf); i >= 0 {
+		end = i
+	}
+	end = bytes.LastIndexByte(buf[:end], in.delim) + 1
+	if end == 0 {
+		return no
+	}
+
+	skipped := buf[:end]
+	if !in.binary && in.detectsBinary() && bytes.IndexByte(skipped, 0) >= 0 {
+		in.binary = true
+		in.done = in.opts.Binary == BinaryWithoutMatch
+	}
+	lines := bytes.Count(skipped, []byte{in.delim})
+	in.keepBefore(skipped, no+lines-1)
+
+	in.bytes += int64(end)
+	r.Discard(end)
+	return no + lines
+}
+
+// keepBefore pushes the last lines of the skipped text, whose last line
+// has number last, to the before-context.
+func (in *input) keepBefore(skipped []byte, last int) {
+	n := min(len(in.before.lines), last)
+	starts := make([]int, 0, n)
+	for end := len(skipped); len(starts) < n && end > 0; {
+		start := bytes.LastIndexByte(skipped[:end-1], in.delim) + 1
+		starts = append(starts, start)
+		end = start
+	}
+	for i := len(starts) - 1; i >= 0; i-- {
+		start, end := starts[i], len(skipped)
+		if i > 0 {
+			end = starts[i-1]
+		}
+		rec := lineio.Parse(string(skipped[start:end]), in.delim)
+		in.before.push(Line{Number: last - i, Offset: in.bytes + int64(start), Text: rec.Text, EOL: rec.EOL})
+	}
+}
+
+// process handles one input line: a selected line flushes the
+// before-context and restarts the after-context countdown.
+func (in *input) process(l Line) error {
+	if !in.binary && in.detectsBinary() && strings.IndexByte(l.Text, 0) >= 0 {
+		in.binary = true
+		if in.opts.Binary == BinaryWithoutMatch {
+			in.done = true
+			return nil
+		}
+	}
+
+	if in.capped {
+		// like grep, report the trailing context even if it matches
+		in.afterLeft--
+		in.done = in.afterLeft == 0
+		return in.sink.Context(l)
+	}
+
+	matched, err := in.matcher.Match(l.Text)
+	if err != nil {
+		return err
+	}
+	if matched != in.opts.Invert {
+		in.count++
+		in.capped = in.opts.MaxCount > 0 && in.count >= in.opts.MaxCount
+		switch {
+		case in.opts.CountOnly:
+			in.done = in.capped
+			return nil
+		case in.binary:
+			in.done = true
+			return in.sink.Binary(l)
+		}
+		if err := in.before.drain(in.sink.Context); err != nil {
+			return err
+		}
+		in.afterLeft = in.opts.After
+		in.done = in.capped && in.afterLeft == 0
+		return in.sink.Matched(l)
+	}
+
+	if in.afterLeft > 0 {
+		in.afterLeft--
+		return in.sink.Context(l)
+	}
+	in.before.push(l)
+	return nil
+}
+
+// ring keeps the last cap(lines) lines for the before-context.
+type ring struct {
+	lines []Line
+	start int
+	n     int
+}
+
+func newRing(size int) *ring {
+	return &ring{lines: make([]Line, size)}
+}
+
+// push appends l, evicting the oldest line when the ring is full.
+func (r *ring) push(l Line) {
+	if len(r.lines) == 0 {
+		return
+	}
+	if r.n < len(r.lines) {
+		r.lines[(r.start+r.n)%len(r.lines)] = l
+		r.n++
+		return
+	}
+	r.lines[r.start] = l
+	r.start = (r.start + 1) % len(r.lines)
+}
+
+// drain calls fn for the buffered lines from oldest to newest and empties the ring.
+func (r *ring) drain(fn func(Line) error) error {
+	for i := 0; i < r.n; i++ {
+		if err := fn(r.lines[(r.start+i)%len(r.lines)]); err != nil {
+			return err
+		}
+	}
+	r.start, r.n = 0, 0
+	return nil
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// recorder is a Sink that records its calls.
+type recorder struct {
+	events  []string
+	flushes int
+	err     error // returned by Matched
+}
+
+func (r *recorder) Begin(name string) error {
+	r.events = append(r.events, "begin "+name)
+	return nil
+}
+
+func (r *recorder) Matched(l Line) error {
+	r.events = append(r.events, fmt.Sprintf("match %d@%d %q", l.Number, l.Offset, l.Text+l.EOL))
+	return r.err
+}
+
+func (r *recorder) Context(l Line) error {
+	r.events = append(r.events, fmt.Sprintf("context %d@%d %q", l.Number, l.Offset, l.Text+l.EOL))
+	return nil
+}
+
+func (r *recorder) Binary(l Line) error {
+	r.events = append(r.events, fmt.Sprintf("binary %d", l.Number))
+	return nil
+}
+
+func (r *recorder) End(stats Stats) error {
+	r.events = append(r.events, fmt.Sprintf("end %d/%d", stats.MatchedLines, stats.BytesSearched))
+	return nil
+}
+
+func (r *recorder) Flush() error {
+	r.flushes++
+	return nil
+}
+
+// literalPrefilter is a Prefilter for a literal.
+type literalPrefilter string
+
+func (p literalPrefilter) Index(b []byte) int {
+	return bytes.Index(b, []byte(p))
+}
+
+func TestSearcher_Search(t *testing.T) {
+	input := "a\nERROR 1\nb\nc\nd\nERROR 2\ne"
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  []string
+	}{
+		{"matches", input, Options{}, []string{
+			`begin app.log`, `match 2@2 "ERROR 1\n"`, `match 6@16 "ERROR 2\n"`, `end 2/25`,
+		}},
+		{"context", input, Options{Before: 1, After: 1}, []string{
+			`begin app.log`, `context 1@0 "a\n"`, `match 2@2 "ERROR 1\n"`, `context 3@10 "b\n"`,
+			`context 5@14 "d\n"`, `match 6@16 "ERROR 2\n"`, `context 7@24 "e"`, `end 2/25`,
+		}},
+		{"invert", input, Options{Invert: true, MaxCount: 2, After: 1}, []string{
+			`begin app.log`, `match 1@0 "a\n"`, `context 2@2 "ERROR 1\n"`, `match 3@10 "b\n"`, `context 4@12 "c\n"`, `end 2/14`,
+		}},
+		{"max_count_trailing_context", input, Options{MaxCount: 1, After: 2}, []string{
+			`begin app.log`, `match 2@2 "ERROR 1\n"`, `context 3@10 "b\n"`, `context 4@12 "c\n"`, `end 1/14`,
+		}},
+		{"count_only", input, Options{CountOnly: true, Before: 1}, []string{`begin app.log`, `end 2/25`}},
+		{"count_only_max_count", input, Options{CountOnly: true, MaxCount: 1}, []string{`begin app.log`, `end 1/10`}},
+		{"null_data", "ERROR\nx\x00y\x00ERROR\x00", Options{NullData: true, Before: 1}, []string{
+			`begin app.log`, `match 1@0 "ERROR\nx\x00"`, `context 2@8 "y\x00"`, `match 3@10 "ERROR\x00"`, `end 2/16`,
+		}},
+		{"binary", "a\x00\nERROR\nERROR\n", Options{}, []string{`begin app.log`, `binary 2`, `end 1/9`}},
+		{"binary_text", "a\x00\nERROR\n", Options{Binary: BinaryText}, []string{`begin app.log`, `match 2@3 "ERROR\n"`, `end 1/9`}},
+		{"binary_without_match", "a\x00\nERROR\n", Options{Binary: BinaryWithoutMatch}, []string{`begin app.log`, `end 0/0`}},
+		{"prefilter", input, Options{Before: 2, Prefilter: literalPrefilter("ERROR 2")}, []string{
+			`begin app.log`, `context 4@12 "c\n"`, `context 5@14 "d\n"`, `match 6@16 "ERROR 2\n"`, `end 1/25`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Regexp(regexp.MustCompile(`ERROR`)), tt.opts)
+			var r recorder
+			stats, err := s.Search(context.Background(), strings.NewReader(tt.input), "app.log", &r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(r.events, tt.want) {
+				t.Errorf("got calls\n%s\nwant\n%s", strings.Join(r.events, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if want := tt.want[len(tt.want)-1]; fmt.Sprintf("end %d/%d", stats.MatchedLines, stats.BytesSearched) != want {
+				t.Errorf("got stats %+v, want %s", stats, want)
+			}
+			if stats.BytesSearched == int64(len(tt.input)) && r.flushes == 0 {
+				t.Error("the sink wasn't flushed before waiting for more input")
+			}
+		})
+	}
+}
+
+// failingMatcher fails on lines containing "boom".
+type failingMatcher struct{}
+
+var errBoom = errors.New("boom")
+
+func (failingMatcher) Match(line string) (bool, error) {
+	if strings.Contains(line, "boom") {
+		return false, errBoom
+	}
+	return strings.Contains(line, "ERROR"), nil
+}
+
+func (failingMatcher) FindAll(string) ([]Span, error) {
+	return nil, nil
+}
+
+func TestSearcher_SearchErrors(t *testing.T) {
+	input := "ERROR\nboom\nERROR\n"
+	sinkErr := errors.New("sink failed")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		m       Matcher
+		sinkErr error
+		want    error
+		calls   int // calls of the sink before the error
+	}{
+		{"match_error", context.Background(), failingMatcher{}, nil, errBoom, 2},
+		{"sink_error", context.Background(), failingMatcher{}, sinkErr, sinkErr, 2},
+		{"canceled", canceled, Regexp(regexp.MustCompile(`ERROR`)), nil, context.Canceled, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := recorder{err: tt.sinkErr}
+			_, err := New(tt.m, Options{}).Search(tt.ctx, strings.NewReader(input), "app.log", &r)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+			if len(r.events) != tt.calls {
+				t.Errorf("got calls %q, want %d calls and no end", r.events, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	m := Regexp(regexp.MustCompile(`o+`))
+	if got, err := m.FindAll("foo boo"); err != nil || !reflect.DeepEqual(got, []Span{{1, 3}, {5, 7}}) {
+		t.Errorf("FindAll() = %v, %v, want [{1 3} {5 7}]", got, err)
+	}
+	if ok, err := m.Match("bar"); ok || err != nil {
+		t.Errorf("Match(\"bar\") = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,39 @@
+package search
+
+// Line is a line of an input.
+type Line struct {
+	Number int    // 1-based
+	Offset int64  // byte offset of the start of the line in the input
+	Text   string // without the line terminator
+	EOL    string // line terminator as read: "\n", "\r\n", "\x00" with NullData, or "" at the end of the input
+}
+
+// Sink receives the results of searching an input, in input order. The
+// search stops with the first error a callback returns.
+//
+// Lines are reported as soon as they are known; a Sink that buffers its
+// output can implement Flusher to deliver them while the search waits for
+// more input.
+type Sink interface {
+	// Begin is called before the input named name is searched.
+	Begin(name string) error
+	// Matched is called for each selected line: the matching lines, or the
+	// non-matching ones with Options.Invert.
+	Matched(l Line) error
+	// Context is called for each line of context around selected lines.
+	// Lines whose numbers don't follow the previous line's start a new
+	// group of context.
+	Context(l Line) error
+	// Binary is called instead of Matched for the first selected line of
+	// a binary input (see BinaryQuit); the search stops after it.
+	Binary(l Line) error
+	// End is called after the input is searched, unless the search fails.
+	End(stats Stats) error
+}
+
+// Flusher is implemented by sinks that buffer their output. Flush is called
+// before reading input that may not be available yet, as when following a
+// stream.
+type Flusher interface {
+	Flush() error
+}
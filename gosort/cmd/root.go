@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"gosort/internal/sorter"
+	"io"
+	"lineio"
 	"log"
 	"os"
 	"strings"
 )
 
-var cfg sorter.Config
+var (
+	cfg      sorter.Config
+	nullData bool // -z
+)
 
 func init() {
 	RootCmd.PersistentFlags().BoolP("help", "", false, "disable default help")
@@ -23,6 +28,7 @@ func init() {
 	RootCmd.Flags().BoolVarP(&cfg.IgnoreTrailing, "ignore-trailing-blanks", "b", false, "ignore trailing blanks")
 	RootCmd.Flags().BoolVarP(&cfg.CheckIfSorted, "check-if-sorted", "c", false, "checkIfSorted if input is sorted")
 	RootCmd.Flags().BoolVarP(&cfg.HumanNum, "human-readable-numeric", "h", false, "human readable numeric sort")
+	RootCmd.Flags().BoolVarP(&nullData, "null-data", "z", false, "lines are terminated by NUL bytes instead of newlines")
 }
 
 func Execute() {
@@ -63,7 +69,7 @@ Args:
 			return err
 		}
 
-		var scanner *bufio.Scanner
+		var in io.Reader = os.Stdin
 		if len(args) > 0 {
 			file := args[0]
 			f, err := os.Open(file)
@@ -76,32 +82,30 @@ Args:
 					log.Fatalf("failed to close file: %v", err)
 				}
 			}(f)
-			scanner = bufio.NewScanner(f)
-		} else {
-			scanner = bufio.NewScanner(os.Stdin)
+			in = f
 		}
 
-		var lines []string
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
+		delim := lineio.Newline
+		if nullData {
+			delim = lineio.NUL
 		}
-		if err := scanner.Err(); err != nil {
+		records, err := lineio.ReadAll(in, delim)
+		if err != nil {
 			return err
 		}
 
-		sortedLines, err := sorter.SortLines(lines, cfg)
+		sortedRecords, err := sorter.SortRecords(records, cfg)
 		if err != nil {
 			return err
 		}
 
-		out := cmd.OutOrStdout()
-		for _, line := range sortedLines {
-			_, err := fmt.Fprintln(out, line)
-			if err != nil {
+		out := bufio.NewWriter(cmd.OutOrStdout())
+		for _, r := range sortedRecords {
+			if _, err := out.WriteString(r.Terminated(delim)); err != nil {
 				return err
 			}
 		}
 
-		return nil
+		return out.Flush()
 	},
 }
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"bytes"
+	"gosort/internal/sorter"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -44,3 +47,38 @@ func TestSortFromFile(t *testing.T) {
 		t.Errorf("output = %q, want %q", got, want)
 	}
 }
+
+func TestSortLineTerminators(t *testing.T) {
+	long := strings.Repeat("z", 100000)
+	tests := []struct {
+		name string
+		args []string
+		in   string
+		want string
+	}{
+		{"long_line", nil, long + "\nb\n", "b\n" + long + "\n"},
+		{"crlf", nil, "b\r\nc\na\r\n", "a\r\nb\r\nc\n"},
+		{"no_final_newline", nil, "b\na", "a\nb\n"},
+		{"null_data", []string{"-z"}, "b\nx\x00a\x00c", "a\x00b\nx\x00c\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "input")
+			if err := os.WriteFile(path, []byte(tt.in), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// flags keep their values between executions
+			cfg, nullData = sorter.Config{}, false
+
+			var out bytes.Buffer
+			RootCmd.SetOut(&out)
+			RootCmd.SetArgs(append(tt.args, path))
+			if err := RootCmd.Execute(); err != nil {
+				t.Fatalf("command failed: %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %.40q, want %.40q", got, tt.want)
+			}
+		})
+	}
+}
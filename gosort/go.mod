@@ -2,8 +2,14 @@ module gosort
 
 go 1.24.5
 
+require (
+	github.com/spf13/cobra v1.9.1
+	lineio v0.0.0
+)
+
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/cobra v1.9.1 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 )
+
+replace lineio => ../lineio
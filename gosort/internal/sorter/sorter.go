@@ -3,6 +3,7 @@ package sorter
 import (
 	"errors"
 	"gosort/internal/parsers"
+	"lineio"
 	"sort"
 	"strings"
 )
@@ -23,6 +24,7 @@ import (
 // ]
 // Sorting will directly compare keyNum = 100 vs 50, no reparsing needed.
 type sortableLine struct {
+	index    int     // index    — the position of the line in the input.
 	original string  // original — the full line as it appeared in the input.
 	keyStr   string  // keyStr   — the string representation of the key to sort by (if sorting lexicographically).
 	keyNum   float64 // keyNum   — the numeric representation of the key (used for numeric or human-readable numeric sort).
@@ -49,6 +51,43 @@ func uniquePrepared(prepared []sortableLine) []sortableLine {
 
 // SortLines sorts lines according to the provided Config.
 func SortLines(lines []string, cfg Config) ([]string, error) {
+	prepared, err := sortPrepared(lines, cfg)
+	if prepared == nil {
+		return nil, err
+	}
+
+	// Extract sorted original lines
+	result := make([]string, len(prepared))
+	for i, sl := range prepared {
+		result[i] = sl.original
+	}
+
+	return result, nil
+}
+
+// SortRecords sorts records by their text like SortLines; each record
+// keeps its terminator.
+func SortRecords(records []lineio.Record, cfg Config) ([]lineio.Record, error) {
+	lines := make([]string, len(records))
+	for i, r := range records {
+		lines[i] = r.Text
+	}
+	prepared, err := sortPrepared(lines, cfg)
+	if prepared == nil {
+		return nil, err
+	}
+
+	result := make([]lineio.Record, len(prepared))
+	for i, sl := range prepared {
+		result[i] = lineio.Record{Text: sl.original, EOL: records[sl.index].EOL}
+	}
+
+	return result, nil
+}
+
+// sortPrepared prepares and sorts lines; it returns nil if there is nothing
+// to output, with -c or on error.
+func sortPrepared(lines []string, cfg Config) ([]sortableLine, error) {
 	// Precompute sort keys for all lines
 	prepared := make([]sortableLine, len(lines))
 	for i, line := range lines {
@@ -58,6 +97,7 @@ func SortLines(lines []string, cfg Config) ([]string, error) {
 
 		key := extractField(line, cfg)
 		sl := sortableLine{
+			index:    i,
 			original: line,
 			keyStr:   key,
 		}
@@ -130,13 +170,7 @@ func SortLines(lines []string, cfg Config) ([]string, error) {
 		prepared = uniquePrepared(prepared)
 	}
 
-	// Extract sorted original lines
-	result := make([]string, len(prepared))
-	for i, sl := range prepared {
-		result[i] = sl.original
-	}
-
-	return result, nil
+	return prepared, nil
 }
 
 // extractField extracts the key field from a line according to -k flag.
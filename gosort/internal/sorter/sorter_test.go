@@ -1,6 +1,7 @@
 package sorter
 
 import (
+	"lineio"
 	"reflect"
 	"testing"
 )
@@ -162,3 +163,15 @@ func TestSortLines_Combinations(t *testing.T) {
 		})
 	}
 }
+
+func TestSortRecords(t *testing.T) {
+	records := []lineio.Record{{Text: "c", EOL: "\r\n"}, {Text: "a  ", EOL: "\n"}, {Text: "b", EOL: ""}}
+	got, err := SortRecords(records, Config{IgnoreTrailing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []lineio.Record{{Text: "a", EOL: "\n"}, {Text: "b", EOL: ""}, {Text: "c", EOL: "\r\n"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
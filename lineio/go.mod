@@ -0,0 +1,3 @@
+module lineio
+
+go 1.24.2
@@ -0,0 +1,115 @@
+// Package lineio reads the records of line-oriented text tools: lines of
+// any length, or NUL-terminated records for -z/--null-data, with their
+// terminators as read, so that tools can write them back unchanged.
+package lineio
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Record delimiters.
+const (
+	Newline byte = '\n' // lines; "\r\n" is recognized too
+	NUL     byte = 0    // -z/--null-data
+)
+
+// Record is a line (or NUL-terminated record) of the input.
+type Record struct {
+	Text string // without the terminator
+	EOL  string // the terminator as read: "\n", "\r\n", "\x00", or "" for a last record without one
+}
+
+// Terminated returns the record with its terminator, or with delim if it
+// has none, as tools terminate every record they write.
+func (r Record) Terminated(delim byte) string {
+	if r.EOL == "" {
+		return r.Text + string(delim)
+	}
+	return r.Text + r.EOL
+}
+
+// Reader reads records terminated by a delimiter. Unlike bufio.Scanner, it
+// has no limit on their length: a record only has to fit in memory.
+type Reader struct {
+	r     *bufio.Reader
+	delim byte
+}
+
+// NewReader returns a Reader of the records of r terminated by delim
+// (Newline or NUL).
+func NewReader(r io.Reader, delim byte) *Reader {
+	return NewReaderSize(r, delim, 4096)
+}
+
+// NewReaderSize is like NewReader with a buffer of at least size bytes.
+// Records can be longer than the buffer.
+func NewReaderSize(r io.Reader, delim byte, size int) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, size), delim: delim}
+}
+
+// Delim returns the record delimiter.
+func (r *Reader) Delim() byte {
+	return r.delim
+}
+
+// Read returns the next record. At the end of the input, it returns an
+// empty record and io.EOF; a last record without a terminator is returned
+// with a nil error, and an empty EOL.
+func (r *Reader) Read() (Record, error) {
+	text, err := r.r.ReadString(r.delim)
+	if len(text) == 0 || err != nil && err != io.EOF {
+		return Record{}, err
+	}
+	return Parse(text, r.delim), nil
+}
+
+// Parse splits the terminator off s, a record terminated by delim, or the
+// last record of an input.
+func Parse(s string, delim byte) Record {
+	switch {
+	case !strings.HasSuffix(s, string(delim)):
+		return Record{Text: s}
+	case delim == Newline && strings.HasSuffix(s, "\r\n"):
+		return Record{Text: s[:len(s)-2], EOL: "\r\n"}
+	default:
+		return Record{Text: s[:len(s)-1], EOL: s[len(s)-1:]}
+	}
+}
+
+// Buffered returns the input that is buffered but not read yet, without
+// reading more. It's valid until the next call to a read method; a search
+// can look at it to Discard the records it doesn't need.
+func (r *Reader) Buffered() []byte {
+	b, _ := r.r.Peek(r.r.Buffered())
+	return b
+}
+
+// Fill reads more input if none is buffered, waiting for it. It returns
+// io.EOF at the end of the input.
+func (r *Reader) Fill() error {
+	_, err := r.r.Peek(1)
+	return err
+}
+
+// Discard skips the next n bytes of the input, which must be buffered.
+func (r *Reader) Discard(n int) {
+	r.r.Discard(n)
+}
+
+// ReadAll reads all the records of r terminated by delim.
+func ReadAll(r io.Reader, delim byte) ([]Record, error) {
+	lr := NewReader(r, delim)
+	var records []Record
+	for {
+		rec, err := lr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
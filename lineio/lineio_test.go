@@ -0,0 +1,88 @@
+package lineio
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadAll(t *testing.T) {
+	long := strings.Repeat("x", 1<<20)
+	tests := []struct {
+		name  string
+		input string
+		delim byte
+		want  []Record
+	}{
+		{"empty", "", Newline, nil},
+		{"lf", "a\nb\n", Newline, []Record{{"a", "\n"}, {"b", "\n"}}},
+		{"crlf", "a\r\nb\nc\r", Newline, []Record{{"a", "\r\n"}, {"b", "\n"}, {"c\r", ""}}},
+		{"no_final_newline", "a\nb", Newline, []Record{{"a", "\n"}, {"b", ""}}},
+		{"empty_lines", "\n\n", Newline, []Record{{"", "\n"}, {"", "\n"}}},
+		{"long_line", long + "\nend", Newline, []Record{{long, "\n"}, {"end", ""}}},
+		{"nul", "a\nb\x00c\r\n\x00d", NUL, []Record{{"a\nb", "\x00"}, {"c\r\n", "\x00"}, {"d", ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// one byte at a time, so that records span many reads
+			got, err := ReadAll(iotest.OneByteReader(strings.NewReader(tt.input)), tt.delim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadAll_Error(t *testing.T) {
+	errBroken := errors.New("broken")
+	r := io.MultiReader(strings.NewReader("a\nb"), iotest.ErrReader(errBroken))
+	got, err := ReadAll(r, Newline)
+	if !errors.Is(err, errBroken) || !reflect.DeepEqual(got, []Record{{"a", "\n"}}) {
+		t.Errorf("got %q, %v, want the first line and %v", got, err, errBroken)
+	}
+}
+
+func TestRecord_Terminated(t *testing.T) {
+	for _, tt := range []struct {
+		rec   Record
+		delim byte
+		want  string
+	}{
+		{Record{"a", "\r\n"}, Newline, "a\r\n"},
+		{Record{"a", ""}, Newline, "a\n"},
+		{Record{"a", ""}, NUL, "a\x00"},
+	} {
+		if got := tt.rec.Terminated(tt.delim); got != tt.want {
+			t.Errorf("%q.Terminated(%q) = %q, want %q", tt.rec, tt.delim, got, tt.want)
+		}
+	}
+}
+
+func TestReader_Buffered(t *testing.T) {
+	r := NewReader(strings.NewReader("skip\nme\nkeep\n"), Newline)
+	if got := r.Buffered(); len(got) != 0 {
+		t.Fatalf("Buffered() = %q before reading", got)
+	}
+	if err := r.Fill(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(r.Buffered()); got != "skip\nme\nkeep\n" {
+		t.Fatalf("Buffered() = %q", got)
+	}
+	r.Discard(len("skip\nme\n"))
+	if rec, err := r.Read(); err != nil || rec != (Record{"keep", "\n"}) {
+		t.Errorf("Read() = %q, %v", rec, err)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read() at the end: %v", err)
+	}
+	if err := r.Fill(); err != io.EOF {
+		t.Errorf("Fill() at the end: %v", err)
+	}
+}